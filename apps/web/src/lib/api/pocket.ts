@@ -18,6 +18,29 @@ export const createPocket = async ({ accountId, name }: { accountId: string; nam
 	return response.json();
 };
 
+export const reorderPockets = async ({
+	accountId,
+	pocketIds
+}: {
+	accountId: string;
+	pocketIds: string[];
+}) => {
+	const response = await fetch(`${import.meta.env.VITE_BASE_URL}/pocket/reorder`, {
+		method: 'PUT',
+		headers: {
+			'Content-Type': 'application/json',
+			Authorization: 'Bearer ' + get(authStore).accessToken
+		},
+		body: JSON.stringify({ accountId, pocketIds })
+	});
+
+	if (!response.ok) {
+		throw new Error('Pocket reorder failed');
+	}
+
+	return response.json();
+};
+
 export const transfer = async ({
 	fromId,
 	toId,
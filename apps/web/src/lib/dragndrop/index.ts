@@ -47,3 +47,17 @@ export function drop(
 	move(fromId, toId);
 	setOpenBalance(true);
 }
+
+// moveBefore returns ids with fromId moved to the place of toId, or null when
+// either is not in ids.
+export function moveBefore(ids: string[], fromId: string, toId: string): string[] | null {
+	const from = ids.indexOf(fromId);
+	const to = ids.indexOf(toId);
+	if (from === -1 || to === -1 || from === to) return null;
+
+	const result = [...ids];
+	result.splice(from, 1);
+	result.splice(to, 0, fromId);
+
+	return result;
+}
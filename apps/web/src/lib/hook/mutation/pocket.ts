@@ -1,4 +1,4 @@
-import { createPocket, transfer, widthdraw } from '@/api/pocket';
+import { createPocket, reorderPockets, transfer, widthdraw } from '@/api/pocket';
 import { createMutation, useQueryClient } from '@tanstack/svelte-query';
 import { toast } from 'svelte-sonner';
 
@@ -16,6 +16,19 @@ export const useCreatePocketMutation = ({ accountId }: { accountId: string }) =>
 	});
 };
 
+export const useReorderPocketsMutation = ({ accountId }: { accountId: string }) => {
+	const client = useQueryClient();
+
+	return createMutation({
+		mutationFn: reorderPockets,
+		onSuccess() {
+			client.invalidateQueries({
+				queryKey: ['account', accountId]
+			});
+		}
+	});
+};
+
 export const useTransferMutation = ({ accountId }: { accountId: string }) => {
 	const client = useQueryClient();
 
@@ -12,6 +12,10 @@ export type Account = {
 	name: string;
 	bank: string;
 	balance: string;
+	sortOrder: number;
+	color: string;
+	icon: string;
+	archived: boolean;
 	createdAt: number;
 	updatedAt: number;
 	pockets: Pocket[];
@@ -23,6 +27,10 @@ export type Pocket = {
 	name: string;
 	type: string;
 	balance: string;
+	sortOrder: number;
+	color: string;
+	icon: string;
+	archived: boolean;
 	createdAt: number;
 	updatedAt: number;
 };
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/config"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/transaction"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// ledger-backfill writes the double-entry postings of transactions recorded
+// before the ledger existed, all in one database transaction. It is safe to run
+// more than once.
+func main() {
+	conf := config.Load()
+	ctx := context.Background()
+
+	if err := logger.Init(conf.Logger); err != nil {
+		logger.PanicContext(ctx, "failed to initialize logger", slog.Any("error", err))
+	}
+
+	db, err := gorm.Open(postgres.Open(conf.Postgres.String()), &gorm.Config{})
+	if err != nil {
+		logger.PanicContext(ctx, "failed to connect to database", slog.Any("error", err))
+	}
+
+	txManager := txmanager.NewTxManager(db)
+	transactionRepo := transaction.NewRepository(db)
+
+	var count int
+	err = txManager.WithTx(ctx, func(ctx context.Context) error {
+		count, err = transactionRepo.BackfillPostings(ctx)
+		return err
+	})
+	if err != nil {
+		logger.PanicContext(ctx, "failed to backfill postings", slog.Any("error", err))
+	}
+
+	logger.InfoContext(ctx, "backfilled postings", slog.Int("transactions", count))
+}
@@ -8,18 +8,36 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/account"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/allocation"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/auth"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/budget"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/config"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/deposit"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/fund"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/holding"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/idempotency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interest"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/liability"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/admin"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	idempotencymw "github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/networth"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/pocket"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/price"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/reconcile"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/report"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/transaction"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/user"
 	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
 	"github.com/boomchanotai/assets-tracker/server/pkg/redis"
 	"github.com/boomchanotai/assets-tracker/server/pkg/requestlogger"
+	"github.com/boomchanotai/assets-tracker/server/pkg/scheduler"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
@@ -52,12 +70,37 @@ func main() {
 		}
 	}()
 
+	txManager := txmanager.NewTxManager(db)
+
 	userRepo := user.NewRepository(db, redisConn, &conf.JWT)
 	accountRepo := account.NewRepository(db)
 	pocketRepo := pocket.NewRepository(db)
 	transactionRepo := transaction.NewRepository(db)
+	holdingRepo := holding.NewRepository(db)
+	fundRepo := fund.NewRepository(db)
+	priceRepo := price.NewRepository(db)
+	exchangeRateRepo := currency.NewRepository(db)
+	depositRepo := deposit.NewRepository(db)
+	interestRepo := interest.NewRepository(db)
+	liabilityRepo := liability.NewRepository(db)
+	snapshotRepo := networth.NewRepository(db)
+	reportRepo := report.NewRepository(db)
+	allocationRuleRepo := allocation.NewRepository(db)
+	budgetRepo := budget.NewRepository(db)
+
+	priceSource, err := price.NewSource(&conf.Price)
+	if err != nil {
+		logger.PanicContext(ctx, "failed to create price source", slog.Any("error", err))
+	}
+	priceProvider := price.NewCachedProvider(priceRepo, priceSource)
+	idempotencyRepo := idempotency.NewRepository(redisConn)
 
 	authMiddleware := authentication.NewAuthMiddleware(userRepo, &conf.JWT)
+	idempotencyMiddleware := idempotencymw.NewIdempotencyMiddleware(idempotencyRepo, authMiddleware, &conf.Idempotency)
+	adminMiddleware := admin.NewAdminMiddleware(userRepo, authMiddleware, &conf.Admin)
+
+	currencyUsecase := currency.NewUsecase(exchangeRateRepo, userRepo)
+	currencyController := currency.NewController(currencyUsecase, authMiddleware)
 
 	userUsecase := user.NewUsecase(userRepo)
 	userController := user.NewController(userUsecase)
@@ -65,13 +108,46 @@ func main() {
 	authUsecase := auth.NewUsecase(userRepo, &conf.JWT)
 	authController := auth.NewController(authUsecase, authMiddleware)
 
-	pocketUsecase := pocket.NewUsecase(pocketRepo, accountRepo, transactionRepo)
-	pocketController := pocket.NewController(pocketUsecase, authMiddleware)
+	pocketUsecase := pocket.NewUsecase(txManager, pocketRepo, accountRepo, transactionRepo, &conf.Transfer)
+	pocketController := pocket.NewController(pocketUsecase, authMiddleware, idempotencyMiddleware)
+
+	allocationUsecase := allocation.NewUsecase(allocationRuleRepo, accountRepo, pocketRepo, transactionRepo)
+	allocationController := allocation.NewController(allocationUsecase, authMiddleware)
+
+	accountUsecase := account.NewUsecase(txManager, accountRepo, pocketRepo, transactionRepo, currencyUsecase, allocationUsecase)
+	accountController := account.NewController(accountUsecase, pocketUsecase, authMiddleware, idempotencyMiddleware)
+
+	reconcileUsecase := reconcile.NewUsecase(txManager, accountRepo, pocketRepo, transactionRepo)
+	reconcileController := reconcile.NewController(reconcileUsecase, authMiddleware)
+
+	holdingUsecase := holding.NewUsecase(txManager, holdingRepo, accountRepo, pocketRepo, transactionRepo, priceProvider)
+	holdingController := holding.NewController(holdingUsecase, currencyUsecase, authMiddleware, idempotencyMiddleware)
+
+	fundUsecase := fund.NewUsecase(txManager, fundRepo, accountRepo, pocketRepo, transactionRepo, priceProvider)
+	fundController := fund.NewController(fundUsecase, currencyUsecase, authMiddleware, idempotencyMiddleware)
+
+	depositUsecase := deposit.NewUsecase(txManager, depositRepo, accountRepo, pocketRepo, transactionRepo)
+	depositController := deposit.NewController(depositUsecase, authMiddleware)
+
+	interestUsecase := interest.NewUsecase(txManager, interestRepo, accountRepo, pocketRepo, transactionRepo)
+	interestController := interest.NewController(interestUsecase, authMiddleware)
+
+	liabilityUsecase := liability.NewUsecase(txManager, liabilityRepo, accountRepo, pocketRepo, transactionRepo)
+	liabilityController := liability.NewController(liabilityUsecase, authMiddleware, idempotencyMiddleware)
+
+	networthUsecase := networth.NewUsecase(snapshotRepo, accountRepo, pocketRepo, transactionRepo, currencyUsecase)
+	networthController := networth.NewController(networthUsecase, authMiddleware)
+
+	reportUsecase := report.NewUsecase(reportRepo, accountRepo, pocketRepo, currencyUsecase)
+	reportController := report.NewController(reportUsecase, authMiddleware)
 
-	accountUsecase := account.NewUsecase(accountRepo, pocketRepo, transactionRepo)
-	accountController := account.NewController(accountUsecase, pocketUsecase, authMiddleware)
+	budgetUsecase := budget.NewUsecase(budgetRepo, accountRepo, pocketRepo, transactionRepo)
+	budgetController := budget.NewController(budgetUsecase, authMiddleware)
 
-	transactionUsecase := transaction.NewUsecase(transactionRepo, accountRepo)
+	priceUsecase := price.NewUsecase(priceRepo, priceSource, priceProvider)
+	priceController := price.NewController(priceUsecase)
+
+	transactionUsecase := transaction.NewUsecase(txManager, transactionRepo, accountRepo, pocketRepo)
 	transactionController := transaction.NewController(transactionUsecase, authMiddleware)
 
 	app := fiber.New(fiber.Config{
@@ -100,6 +176,13 @@ func main() {
 	accountGroup := app.Group("/v1/account")
 	accountGroup.Use(authMiddleware.Auth)
 	accountController.Mount(accountGroup)
+	reconcileController.Mount(accountGroup)
+	holdingController.Mount(accountGroup)
+	fundController.Mount(accountGroup)
+	depositController.Mount(accountGroup)
+	interestController.Mount(accountGroup)
+	liabilityController.Mount(accountGroup)
+	allocationController.Mount(accountGroup)
 
 	pocketGroup := app.Group("/v1/pocket")
 	pocketGroup.Use(authMiddleware.Auth)
@@ -109,6 +192,46 @@ func main() {
 	transactionGroup.Use(authMiddleware.Auth)
 	transactionController.Mount(transactionGroup)
 
+	currencyGroup := app.Group("/v1/currency")
+	currencyGroup.Use(authMiddleware.Auth)
+	currencyController.Mount(currencyGroup)
+
+	priceGroup := app.Group("/v1/price")
+	priceGroup.Use(authMiddleware.Auth)
+	priceController.Mount(priceGroup)
+
+	networthGroup := app.Group("/v1/networth")
+	networthGroup.Use(authMiddleware.Auth)
+	networthController.Mount(networthGroup)
+
+	reportGroup := app.Group("/v1/reports")
+	reportGroup.Use(authMiddleware.Auth)
+	reportController.Mount(reportGroup)
+
+	budgetGroup := app.Group("/v1/budget")
+	budgetGroup.Use(authMiddleware.Auth)
+	budgetController.Mount(budgetGroup)
+
+	adminGroup := app.Group("/v1/admin")
+	adminGroup.Use(authMiddleware.Auth, adminMiddleware.Admin)
+	priceController.MountAdmin(adminGroup)
+	currencyController.MountAdmin(adminGroup)
+
+	go scheduler.Every(ctx, "reconcile", time.Duration(conf.Reconcile.Interval)*time.Second, func(ctx context.Context) error {
+		return reconcileUsecase.ReconcileAll(ctx, conf.Reconcile.Repair)
+	})
+
+	go scheduler.Every(ctx, "price-refresh", time.Duration(conf.Price.RefreshInterval)*time.Second, func(ctx context.Context) error {
+		_, err := priceUsecase.Refresh(ctx)
+		return err
+	})
+
+	go scheduler.Every(ctx, "fixed-deposit", time.Duration(conf.FixedDeposit.Interval)*time.Second, depositUsecase.AccrueAll)
+
+	go scheduler.Every(ctx, "savings-interest", time.Duration(conf.Interest.Interval)*time.Second, interestUsecase.PostAll)
+
+	go scheduler.Every(ctx, "networth-snapshot", time.Duration(conf.NetWorth.Interval)*time.Second, networthUsecase.SnapshotAll)
+
 	go func() {
 		if err := app.Listen(fmt.Sprintf(":%d", conf.Port)); err != nil {
 			logger.PanicContext(ctx, "failed to start server", slog.Any("error", err))
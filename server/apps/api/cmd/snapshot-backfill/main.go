@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/account"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/config"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/networth"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/pocket"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/transaction"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/user"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/boomchanotai/assets-tracker/server/pkg/redis"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// snapshot-backfill rebuilds the daily net worth snapshots before today from
+// the ledger. It is safe to run more than once.
+func main() {
+	conf := config.Load()
+	ctx := context.Background()
+
+	if err := logger.Init(conf.Logger); err != nil {
+		logger.PanicContext(ctx, "failed to initialize logger", slog.Any("error", err))
+	}
+
+	db, err := gorm.Open(postgres.Open(conf.Postgres.String()), &gorm.Config{})
+	if err != nil {
+		logger.PanicContext(ctx, "failed to connect to database", slog.Any("error", err))
+	}
+
+	redisConn, err := redis.New(ctx, conf.Redis)
+	if err != nil {
+		logger.PanicContext(ctx, "failed to connect to redis", slog.Any("error", err))
+	}
+	defer redisConn.Close()
+
+	userRepo := user.NewRepository(db, redisConn, &conf.JWT)
+	accountRepo := account.NewRepository(db)
+	pocketRepo := pocket.NewRepository(db)
+	transactionRepo := transaction.NewRepository(db)
+	snapshotRepo := networth.NewRepository(db)
+	currencyUsecase := currency.NewUsecase(currency.NewRepository(db), userRepo)
+
+	networthUsecase := networth.NewUsecase(snapshotRepo, accountRepo, pocketRepo, transactionRepo, currencyUsecase)
+
+	count, err := networthUsecase.Backfill(ctx)
+	if err != nil {
+		logger.PanicContext(ctx, "failed to backfill snapshots", slog.Any("error", err))
+	}
+
+	logger.InfoContext(ctx, "backfilled snapshots", slog.Int("snapshots", count))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/config"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/transaction"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// txtype-backfill corrects the type of transactions recorded before transfers and
+// withdrawals were typed properly, inferring it from the pockets they reference.
+func main() {
+	conf := config.Load()
+	ctx := context.Background()
+
+	if err := logger.Init(conf.Logger); err != nil {
+		logger.PanicContext(ctx, "failed to initialize logger", slog.Any("error", err))
+	}
+
+	db, err := gorm.Open(postgres.Open(conf.Postgres.String()), &gorm.Config{})
+	if err != nil {
+		logger.PanicContext(ctx, "failed to connect to database", slog.Any("error", err))
+	}
+
+	transactionRepo := transaction.NewRepository(db)
+
+	count, err := transactionRepo.BackfillTypes(ctx)
+	if err != nil {
+		logger.PanicContext(ctx, "failed to backfill transaction types", slog.Any("error", err))
+	}
+
+	logger.InfoContext(ctx, "backfilled transaction types", slog.Int64("transactions", count))
+}
@@ -1,9 +1,12 @@
 package account
 
 import (
+	"regexp"
+
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/pocket"
 	"github.com/cockroachdb/errors"
 	"github.com/gofiber/fiber/v2"
@@ -12,44 +15,61 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// colorPattern matches a hex color such as #1E90FF.
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
 type controller struct {
-	usecase        *usecase
-	pocketUsecase  *pocket.Usecase
-	authMiddleware authentication.AuthMiddleware
+	usecase               *usecase
+	pocketUsecase         *pocket.Usecase
+	authMiddleware        authentication.AuthMiddleware
+	idempotencyMiddleware idempotency.IdempotencyMiddleware
 }
 
 func NewController(
 	accountUsecase *usecase,
 	pocketUsecase *pocket.Usecase,
 	authMiddleware authentication.AuthMiddleware,
+	idempotencyMiddleware idempotency.IdempotencyMiddleware,
 ) *controller {
 	return &controller{
-		usecase:        accountUsecase,
-		pocketUsecase:  pocketUsecase,
-		authMiddleware: authMiddleware,
+		usecase:               accountUsecase,
+		pocketUsecase:         pocketUsecase,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
 	}
 }
 
 func (h *controller) Mount(r fiber.Router) {
 	r.Get("/", h.GetAccounts)
+	r.Get("/summary", h.GetSummary)
 	r.Get("/:id", h.GetAccount)
 	r.Post("/", h.CreateAccount)
+	r.Put("/reorder", h.ReorderAccounts)
 	r.Put("/:id", h.UpdateAccount)
 	r.Delete("/:id", h.DeleteAccount)
 
-	r.Post("/:id/deposit", h.Deposit)
+	r.Post("/:id/deposit", h.idempotencyMiddleware.Handle, h.Deposit)
+	r.Post("/:id/withdraw", h.idempotencyMiddleware.Handle, h.Withdraw)
 }
 
 type accountResponse struct {
-	ID        uuid.UUID               `json:"id"`
-	UserID    uuid.UUID               `json:"userId"`
-	Type      entity.AccountType      `json:"type"`
-	Name      string                  `json:"name"`
-	Bank      string                  `json:"bank"`
-	Balance   decimal.Decimal         `json:"balance"`
-	CreatedAt int64                   `json:"createdAt"`
-	UpdatedAt int64                   `json:"updatedAt"`
-	Pockets   []pocket.PocketResponse `json:"pockets"`
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	Type      entity.AccountType `json:"type"`
+	Name      string             `json:"name"`
+	Bank      string             `json:"bank"`
+	Currency  string             `json:"currency"`
+	Balance   decimal.Decimal    `json:"balance"`
+	SortOrder int                `json:"sortOrder"`
+	Color     string             `json:"color"`
+	Icon      string             `json:"icon"`
+	Archived  bool               `json:"archived"`
+	// BaseBalance is null when there is no exchange rate to BaseCurrency
+	BaseCurrency string                  `json:"baseCurrency,omitempty"`
+	BaseBalance  *decimal.Decimal        `json:"baseBalance,omitempty"`
+	CreatedAt    int64                   `json:"createdAt"`
+	UpdatedAt    int64                   `json:"updatedAt"`
+	Pockets      []pocket.PocketResponse `json:"pockets"`
 }
 
 func (h *controller) GetAccounts(ctx *fiber.Ctx) error {
@@ -60,22 +80,36 @@ func (h *controller) GetAccounts(ctx *fiber.Ctx) error {
 		})
 	}
 
-	accounts, err := h.usecase.GetAccounts(ctx.UserContext(), userID)
+	summary, err := h.usecase.GetSummary(ctx.UserContext(), userID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get accounts")
 	}
 
-	accountsResponse := make([]accountResponse, 0, len(accounts))
-	for _, account := range accounts {
+	// Archived accounts are only listed on request
+	includeArchived := ctx.QueryBool("archived")
+
+	accountsResponse := make([]accountResponse, 0, len(summary.Accounts))
+	for _, account := range summary.Accounts {
+		if account.Archived && !includeArchived {
+			continue
+		}
+
 		accountsResponse = append(accountsResponse, accountResponse{
-			ID:        account.ID,
-			UserID:    account.UserID,
-			Type:      account.Type,
-			Name:      account.Name,
-			Bank:      account.Bank,
-			Balance:   account.Balance,
-			CreatedAt: account.CreatedAt.Unix(),
-			UpdatedAt: account.UpdatedAt.Unix(),
+			ID:           account.ID,
+			UserID:       account.UserID,
+			Type:         account.Type,
+			Name:         account.Name,
+			Bank:         account.Bank,
+			Currency:     account.Currency,
+			Balance:      account.Balance,
+			SortOrder:    account.SortOrder,
+			Color:        account.Color,
+			Icon:         account.Icon,
+			Archived:     account.Archived,
+			BaseCurrency: summary.BaseCurrency,
+			BaseBalance:  account.BaseBalance,
+			CreatedAt:    account.CreatedAt.Unix(),
+			UpdatedAt:    account.UpdatedAt.Unix(),
 		})
 	}
 
@@ -122,48 +156,51 @@ func (h *controller) GetAccount(ctx *fiber.Ctx) error {
 		})
 	}
 
-	account, err := h.usecase.GetAccount(ctx.UserContext(), userID, req.Id)
+	account, baseCurrency, err := h.usecase.GetAccountValue(ctx.UserContext(), userID, req.Id)
 	if err != nil {
 		return errors.Wrap(err, "failed to get account")
 	}
 
-	pockets, err := h.pocketUsecase.GetPocketsByAccountID(ctx.UserContext(), userID, req.Id)
+	// Archived pockets are only listed on request
+	pockets, err := h.pocketUsecase.GetPocketsByAccountID(ctx.UserContext(), userID, req.Id, ctx.QueryBool("archived"))
 	if err != nil {
 		return errors.Wrap(err, "failed to get pockets")
 	}
 
 	pocketsResponse := make([]pocket.PocketResponse, 0, len(pockets))
 	for _, p := range pockets {
-		pocketsResponse = append(pocketsResponse, pocket.PocketResponse{
-			ID:        p.ID,
-			AccountID: p.AccountID,
-			Name:      p.Name,
-			Type:      p.Type,
-			Balance:   p.Balance,
-			CreatedAt: p.CreatedAt.Unix(),
-			UpdatedAt: p.UpdatedAt.Unix(),
-		})
+		pocketsResponse = append(pocketsResponse, pocket.NewPocketResponse(&p))
 	}
 
 	return ctx.JSON(dto.HttpResponse{
 		Result: accountResponse{
-			ID:        account.ID,
-			UserID:    account.UserID,
-			Type:      account.Type,
-			Name:      account.Name,
-			Bank:      account.Bank,
-			Balance:   account.Balance,
-			CreatedAt: account.CreatedAt.Unix(),
-			UpdatedAt: account.UpdatedAt.Unix(),
-			Pockets:   pocketsResponse,
+			ID:           account.ID,
+			UserID:       account.UserID,
+			Type:         account.Type,
+			Name:         account.Name,
+			Bank:         account.Bank,
+			Currency:     account.Currency,
+			Balance:      account.Balance,
+			SortOrder:    account.SortOrder,
+			Color:        account.Color,
+			Icon:         account.Icon,
+			Archived:     account.Archived,
+			BaseCurrency: baseCurrency,
+			BaseBalance:  account.BaseBalance,
+			CreatedAt:    account.CreatedAt.Unix(),
+			UpdatedAt:    account.UpdatedAt.Unix(),
+			Pockets:      pocketsResponse,
 		},
 	})
 }
 
 type createAccountRequest struct {
-	Type string `json:"type"`
-	Name string `json:"name"`
-	Bank string `json:"bank"`
+	Type     string  `json:"type"`
+	Name     string  `json:"name"`
+	Bank     string  `json:"bank"`
+	Currency string  `json:"currency"` // Defaults to THB
+	Color    *string `json:"color"`
+	Icon     *string `json:"icon"`
 }
 
 func (a *createAccountRequest) Parse(ctx *fiber.Ctx) error {
@@ -183,6 +220,8 @@ func (a *createAccountRequest) Validate() error {
 	v.Must(a.Type != "", "type is required")
 	v.Must(a.Name != "", "name is required")
 	v.Must(a.Bank != "", "bank is required")
+	v.Must(a.Color == nil || *a.Color == "" || colorPattern.MatchString(*a.Color), "color must be a hex color like #1E90FF")
+	v.Must(a.Icon == nil || len(*a.Icon) <= 64, "icon must be at most 64 characters")
 
 	return errors.WithStack(v.Error())
 }
@@ -203,11 +242,24 @@ func (h *controller) CreateAccount(ctx *fiber.Ctx) error {
 	}
 
 	account, err := h.usecase.CreateAccount(ctx.UserContext(), entity.AccountInput{
-		UserID: userID,
-		Type:   entity.AccountType(req.Type),
-		Name:   req.Name,
-		Bank:   req.Bank,
+		UserID:   userID,
+		Type:     entity.AccountType(req.Type),
+		Name:     req.Name,
+		Bank:     req.Bank,
+		Currency: req.Currency,
+		Color:    req.Color,
+		Icon:     req.Icon,
 	})
+	if errors.Is(err, entity.ErrInvalidCurrency) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid currency",
+		})
+	}
+	if errors.Is(err, entity.ErrInvalidAccountType) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account type",
+		})
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to create account")
 	}
@@ -219,7 +271,12 @@ func (h *controller) CreateAccount(ctx *fiber.Ctx) error {
 			Type:      account.Type,
 			Name:      account.Name,
 			Bank:      account.Bank,
+			Currency:  account.Currency,
 			Balance:   account.Balance,
+			SortOrder: account.SortOrder,
+			Color:     account.Color,
+			Icon:      account.Icon,
+			Archived:  account.Archived,
 			CreatedAt: account.CreatedAt.Unix(),
 			UpdatedAt: account.UpdatedAt.Unix(),
 		},
@@ -227,10 +284,13 @@ func (h *controller) CreateAccount(ctx *fiber.Ctx) error {
 }
 
 type updateAccountRequest struct {
-	Id   uuid.UUID `params:"id"`
-	Type string    `json:"type"`
-	Name string    `json:"name"`
-	Bank string    `json:"bank"`
+	Id       uuid.UUID `params:"id"`
+	Type     string    `json:"type"`
+	Name     string    `json:"name"`
+	Bank     string    `json:"bank"`
+	Color    *string   `json:"color"`
+	Icon     *string   `json:"icon"`
+	Archived *bool     `json:"archived"`
 }
 
 func (a *updateAccountRequest) Parse(ctx *fiber.Ctx) error {
@@ -252,6 +312,8 @@ func (a *updateAccountRequest) Parse(ctx *fiber.Ctx) error {
 func (a *updateAccountRequest) Validate() error {
 	v := validator.New()
 	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Color == nil || *a.Color == "" || colorPattern.MatchString(*a.Color), "color must be a hex color like #1E90FF")
+	v.Must(a.Icon == nil || len(*a.Icon) <= 64, "icon must be at most 64 characters")
 
 	return errors.WithStack(v.Error())
 }
@@ -272,11 +334,23 @@ func (h *controller) UpdateAccount(ctx *fiber.Ctx) error {
 	}
 
 	account, err := h.usecase.UpdateAccount(ctx.UserContext(), userID, req.Id, entity.AccountInput{
-		Type: entity.AccountType(req.Type),
-		Name: req.Name,
-		Bank: req.Bank,
+		Type:     entity.AccountType(req.Type),
+		Name:     req.Name,
+		Bank:     req.Bank,
+		Color:    req.Color,
+		Icon:     req.Icon,
+		Archived: req.Archived,
 	})
-
+	if errors.Is(err, entity.ErrInvalidAccountType) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account type",
+		})
+	}
+	if errors.Is(err, entity.ErrAccountClassChange) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Can't change an account between asset and liability types",
+		})
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to update account")
 	}
@@ -288,13 +362,73 @@ func (h *controller) UpdateAccount(ctx *fiber.Ctx) error {
 			Type:      account.Type,
 			Name:      account.Name,
 			Bank:      account.Bank,
+			Currency:  account.Currency,
 			Balance:   account.Balance,
+			SortOrder: account.SortOrder,
+			Color:     account.Color,
+			Icon:      account.Icon,
+			Archived:  account.Archived,
 			CreatedAt: account.CreatedAt.Unix(),
 			UpdatedAt: account.UpdatedAt.Unix(),
 		},
 	})
 }
 
+type reorderAccountsRequest struct {
+	AccountIDs []uuid.UUID `json:"accountIds"` // In display order
+}
+
+func (a *reorderAccountsRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *reorderAccountsRequest) Validate() error {
+	v := validator.New()
+	v.Must(len(a.AccountIDs) > 0, "accountIds is required")
+
+	return errors.WithStack(v.Error())
+}
+
+// ReorderAccounts saves the order of the user's accounts, as arranged by drag
+// and drop.
+func (h *controller) ReorderAccounts(ctx *fiber.Ctx) error {
+	var req reorderAccountsRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	err = h.usecase.ReorderAccounts(ctx.UserContext(), userID, req.AccountIDs)
+	if errors.Is(err, entity.ErrInvalidAccountOrder) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Accounts must belong to the user and be listed once",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to reorder accounts")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "Accounts reordered",
+	})
+}
+
 func (h *controller) DeleteAccount(ctx *fiber.Ctx) error {
 	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
 	if err != nil {
@@ -324,6 +458,7 @@ func (h *controller) DeleteAccount(ctx *fiber.Ctx) error {
 type depositRequest struct {
 	Id     uuid.UUID       `params:"id"`
 	Amount decimal.Decimal `json:"amount"`
+	Note   string          `json:"note"`
 }
 
 func (a *depositRequest) Parse(ctx *fiber.Ctx) error {
@@ -365,7 +500,7 @@ func (h *controller) Deposit(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.usecase.Deposit(ctx.UserContext(), userID, req.Id, req.Amount); err != nil {
+	if err := h.usecase.Deposit(ctx.UserContext(), userID, req.Id, req.Amount, req.Note); err != nil {
 		return errors.Wrap(err, "failed to deposit")
 	}
 
@@ -373,3 +508,137 @@ func (h *controller) Deposit(ctx *fiber.Ctx) error {
 		Result: "success",
 	})
 }
+
+type withdrawRequest struct {
+	Id     uuid.UUID       `params:"id"`
+	Amount decimal.Decimal `json:"amount"`
+	Note   string          `json:"note"`
+	Payee  string          `json:"payee"`
+}
+
+func (a *withdrawRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *withdrawRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Amount.IsPositive(), "amount must be positive")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) Withdraw(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req withdrawRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	err = h.usecase.Withdraw(ctx.UserContext(), userID, req.Id, req.Amount, req.Note, req.Payee)
+	if errors.Is(err, entity.ErrInsufficientBalance) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to withdraw")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "success",
+	})
+}
+
+type currencyTotalResponse struct {
+	Currency   string           `json:"currency"`
+	Amount     decimal.Decimal  `json:"amount"`
+	BaseAmount *decimal.Decimal `json:"baseAmount"`
+}
+
+type summaryResponse struct {
+	BaseCurrency    string                  `json:"baseCurrency"`
+	BaseTotal       decimal.Decimal         `json:"baseTotal"`
+	BaseAssets      decimal.Decimal         `json:"baseAssets"`
+	BaseLiabilities decimal.Decimal         `json:"baseLiabilities"`
+	Unconverted     []string                `json:"unconverted"`
+	Currencies      []currencyTotalResponse `json:"currencies"`
+	Accounts        []accountResponse       `json:"accounts"`
+}
+
+func (h *controller) GetSummary(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	summary, err := h.usecase.GetSummary(ctx.UserContext(), userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get summary")
+	}
+
+	response := summaryResponse{
+		BaseCurrency:    summary.BaseCurrency,
+		BaseTotal:       summary.BaseTotal,
+		BaseAssets:      summary.BaseAssets,
+		BaseLiabilities: summary.BaseLiabilities,
+		Unconverted:     make([]string, 0, len(summary.Unconverted)),
+		Currencies:      make([]currencyTotalResponse, 0, len(summary.Currencies)),
+		Accounts:        make([]accountResponse, 0, len(summary.Accounts)),
+	}
+	response.Unconverted = append(response.Unconverted, summary.Unconverted...)
+
+	for _, c := range summary.Currencies {
+		response.Currencies = append(response.Currencies, currencyTotalResponse{
+			Currency:   c.Currency,
+			Amount:     c.Amount,
+			BaseAmount: c.BaseAmount,
+		})
+	}
+
+	for _, account := range summary.Accounts {
+		response.Accounts = append(response.Accounts, accountResponse{
+			ID:           account.ID,
+			UserID:       account.UserID,
+			Type:         account.Type,
+			Name:         account.Name,
+			Bank:         account.Bank,
+			Currency:     account.Currency,
+			Balance:      account.Balance,
+			SortOrder:    account.SortOrder,
+			Color:        account.Color,
+			Icon:         account.Icon,
+			Archived:     account.Archived,
+			BaseCurrency: summary.BaseCurrency,
+			BaseBalance:  account.BaseBalance,
+			CreatedAt:    account.CreatedAt.Unix(),
+			UpdatedAt:    account.UpdatedAt.Unix(),
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
@@ -2,15 +2,18 @@ package account
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
 	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -29,24 +32,51 @@ func NewRepository(db *gorm.DB) interfaces.AccountRepository {
 	}
 }
 
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func accountFromModel(a *model.Account) *entity.Account {
+	return &entity.Account{
+		ID:        a.ID,
+		UserID:    a.UserID,
+		Type:      a.Type,
+		Name:      a.Name,
+		Bank:      a.Bank,
+		Currency:  a.Currency,
+		Balance:   a.Balance,
+		SortOrder: a.SortOrder,
+		Color:     a.Color,
+		Icon:      a.Icon,
+		Archived:  a.Archived,
+		CreatedAt: a.CreatedAt,
+		UpdatedAt: a.UpdatedAt,
+	}
+}
+
+func (r *repository) GetAccounts(ctx context.Context) ([]entity.Account, error) {
+	var accounts []*model.Account
+	if err := r.getDB(ctx).Order("created_at asc").Find(&accounts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get accounts")
+	}
+
+	var result []entity.Account
+	for _, a := range accounts {
+		result = append(result, *accountFromModel(a))
+	}
+
+	return result, nil
+}
+
 func (r *repository) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]entity.Account, error) {
 	var accounts []*model.Account
-	if err := r.db.Where("user_id = ?", userID).Order("created_at asc").Find(&accounts).Error; err != nil {
+	if err := r.getDB(ctx).Where("user_id = ?", userID).Order("sort_order asc, created_at asc").Find(&accounts).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get user accounts")
 	}
 
 	var result []entity.Account
 	for _, a := range accounts {
-		result = append(result, entity.Account{
-			ID:        a.ID,
-			UserID:    a.UserID,
-			Type:      a.Type,
-			Name:      a.Name,
-			Bank:      a.Bank,
-			Balance:   a.Balance,
-			CreatedAt: a.CreatedAt,
-			UpdatedAt: a.UpdatedAt,
-		})
+		result = append(result, *accountFromModel(a))
 	}
 
 	return result, nil
@@ -54,55 +84,66 @@ func (r *repository) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]e
 
 func (r *repository) GetUserAccount(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Account, error) {
 	var a model.Account
-	if err := r.db.Where("user_id = ? AND id = ?", userID, id).First(&a).Error; err != nil {
+	if err := r.getDB(ctx).Where("user_id = ? AND id = ?", userID, id).First(&a).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get user account")
 	}
 
-	return &entity.Account{
-		ID:        a.ID,
-		UserID:    a.UserID,
-		Type:      a.Type,
-		Name:      a.Name,
-		Bank:      a.Bank,
-		Balance:   a.Balance,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
-	}, nil
+	return accountFromModel(&a), nil
+}
+
+func (r *repository) GetAccount(ctx context.Context, id uuid.UUID) (*entity.Account, error) {
+	var a model.Account
+	if err := r.getDB(ctx).First(&a, id).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	return accountFromModel(&a), nil
 }
 
 func (r *repository) CreateAccount(ctx context.Context, input entity.AccountInput) (*entity.Account, error) {
+	// New accounts go last
+	var sortOrder int
+	if err := r.getDB(ctx).Model(&model.Account{}).Select("COALESCE(MAX(sort_order) + 1, 0)").Where("user_id = ?", input.UserID).Scan(&sortOrder).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get sort order")
+	}
+
 	a := model.Account{
-		ID:      uuid.New(),
-		UserID:  input.UserID,
-		Type:    input.Type,
-		Name:    input.Name,
-		Bank:    input.Bank,
-		Balance: decimal.NewFromInt(0),
+		ID:        uuid.New(),
+		UserID:    input.UserID,
+		Type:      input.Type,
+		Name:      input.Name,
+		Bank:      input.Bank,
+		Currency:  input.Currency,
+		Balance:   decimal.NewFromInt(0),
+		SortOrder: sortOrder,
+	}
+
+	if input.Color != nil {
+		a.Color = *input.Color
+	}
+
+	if input.Icon != nil {
+		a.Icon = *input.Icon
 	}
 
-	if err := r.db.Create(&a).Error; err != nil {
+	if err := r.getDB(ctx).Create(&a).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to create account")
 	}
 
-	return &entity.Account{
-		ID:        a.ID,
-		UserID:    a.UserID,
-		Type:      a.Type,
-		Name:      a.Name,
-		Bank:      a.Bank,
-		Balance:   a.Balance,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
-	}, nil
+	return accountFromModel(&a), nil
 }
 
 func (r *repository) UpdateAccount(ctx context.Context, id uuid.UUID, input entity.AccountInput) (*entity.Account, error) {
 	var a model.Account
-	if err := r.db.First(&a, id).Error; err != nil {
+	if err := r.getDB(ctx).First(&a, id).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get account")
 	}
 
 	if input.Type != "" {
+		if input.Type.IsLiability() != a.Type.IsLiability() {
+			return nil, errors.Wrap(entity.ErrAccountClassChange, "failed to update account")
+		}
+
 		a.Type = input.Type
 	}
 
@@ -114,77 +155,230 @@ func (r *repository) UpdateAccount(ctx context.Context, id uuid.UUID, input enti
 		a.Bank = input.Bank
 	}
 
+	if input.Color != nil {
+		a.Color = *input.Color
+	}
+
+	if input.Icon != nil {
+		a.Icon = *input.Icon
+	}
+
+	if input.Archived != nil {
+		a.Archived = *input.Archived
+	}
+
 	a.UpdatedAt = time.Now()
 
-	if err := r.db.Save(&a).Error; err != nil {
+	if err := r.getDB(ctx).Save(&a).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to update account")
 	}
 
-	return &entity.Account{
-		ID:        a.ID,
-		UserID:    a.UserID,
-		Type:      a.Type,
-		Name:      a.Name,
-		Bank:      a.Bank,
-		Balance:   a.Balance,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
-	}, nil
+	return accountFromModel(&a), nil
+}
+
+// ReorderAccounts numbers the given accounts of the user from 0 in order, then
+// the rest after them in their current order.
+func (r *repository) ReorderAccounts(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error {
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var rest []uuid.UUID
+		if err := tx.Model(&model.Account{}).Where("user_id = ? AND id NOT IN ?", userID, ids).Order("sort_order asc, created_at asc").Pluck("id", &rest).Error; err != nil {
+			return errors.Wrap(err, "failed to get accounts")
+		}
+
+		for i, id := range slices.Concat(ids, rest) {
+			if err := tx.Model(&model.Account{}).Where("user_id = ? AND id = ?", userID, id).Update("sort_order", i).Error; err != nil {
+				return errors.Wrap(err, "failed to update sort order")
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *repository) DeleteAccount(ctx context.Context, id uuid.UUID) error {
-	if err := r.db.Where("id = ?", id).Delete(&model.Account{}).Error; err != nil {
+	if err := r.getDB(ctx).Where("id = ?", id).Delete(&model.Account{}).Error; err != nil {
 		return errors.Wrap(err, "failed to delete account")
 	}
 
 	return nil
 }
 
+// lockAccounts selects the accounts with SELECT ... FOR UPDATE ordered by id, so
+// concurrent balance mutations always acquire row locks in the same order.
+func lockAccounts(tx *gorm.DB, ids ...uuid.UUID) (map[uuid.UUID]*model.Account, error) {
+	var accounts []*model.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&accounts).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to lock accounts")
+	}
+
+	result := make(map[uuid.UUID]*model.Account, len(accounts))
+	for _, a := range accounts {
+		result[a.ID] = a
+	}
+
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			return nil, errors.Wrap(gorm.ErrRecordNotFound, "failed to get account")
+		}
+	}
+
+	return result, nil
+}
+
 func (r *repository) Deposit(ctx context.Context, id uuid.UUID, amount decimal.Decimal) error {
-	var a model.Account
-	if err := r.db.First(&a, id).Error; err != nil {
-		return errors.Wrap(err, "failed to get account")
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to deposit")
 	}
 
-	// TODO: Lock db transaction
-	a.Balance = a.Balance.Add(amount)
-	a.UpdatedAt = time.Now()
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+		a := accounts[id]
+
+		a.Balance = a.Balance.Add(amount)
+		a.UpdatedAt = time.Now()
+
+		if err := tx.Save(a).Error; err != nil {
+			return errors.Wrap(err, "failed to update account")
+		}
 
-	if err := r.db.Save(&a).Error; err != nil {
-		return errors.Wrap(err, "failed to update account")
+		return nil
+	})
+}
+
+func (r *repository) Withdraw(ctx context.Context, id uuid.UUID, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to withdraw")
 	}
 
-	return nil
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+		a := accounts[id]
+
+		if !a.Type.IsLiability() && a.Balance.LessThan(amount) {
+			return errors.Wrap(entity.ErrInsufficientBalance, "failed to withdraw")
+		}
+
+		a.Balance = a.Balance.Sub(amount)
+		a.UpdatedAt = time.Now()
+
+		if err := tx.Save(a).Error; err != nil {
+			return errors.Wrap(err, "failed to update account")
+		}
+
+		return nil
+	})
+}
+
+func (r *repository) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to transfer")
+	}
+
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, fromID, toID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+		from, to := accounts[fromID], accounts[toID]
+
+		if !from.Type.IsLiability() && from.Balance.LessThan(amount) {
+			return errors.Wrap(entity.ErrInsufficientBalance, "failed to transfer")
+		}
+
+		from.Balance = from.Balance.Sub(amount)
+		from.UpdatedAt = time.Now()
+
+		to.Balance = to.Balance.Add(amount)
+		to.UpdatedAt = time.Now()
+
+		if err := tx.Save(from).Error; err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		if err := tx.Save(to).Error; err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		return nil
+	})
 }
 
 func (r *repository) UpdateBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (account *entity.Account, differenceBalance decimal.Decimal, err error) {
-	var a model.Account
-	if err := r.db.First(&a, id).Error; err != nil {
-		return nil, decimal.Decimal{}, errors.Wrap(err, "failed to get account")
+	var a *model.Account
+	err = r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+		a = accounts[id]
+
+		if amount.LessThan(a.Balance) {
+			return errors.Wrap(ErrNewBalanceLessThanCurrentBalance, "new balance less than current balance")
+		}
+
+		differenceBalance = amount.Sub(a.Balance)
+
+		a.Balance = amount
+		a.UpdatedAt = time.Now()
+
+		if err := tx.Save(a).Error; err != nil {
+			return errors.Wrap(err, "failed to update account")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, decimal.Decimal{}, errors.WithStack(err)
 	}
 
-	// TODO: Lock db transaction
-	if amount.LessThan(a.Balance) {
-		return nil, decimal.Decimal{}, errors.Wrap(ErrNewBalanceLessThanCurrentBalance, "new balance less than current balance")
+	return accountFromModel(a), differenceBalance, nil
+}
+
+func (r *repository) LockAccount(ctx context.Context, id uuid.UUID) (*entity.Account, error) {
+	accounts, err := lockAccounts(r.getDB(ctx), id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock account")
 	}
+	a := accounts[id]
 
-	differenceBalance = amount.Sub(a.Balance)
+	return accountFromModel(a), nil
+}
 
-	a.Balance = amount
-	a.UpdatedAt = time.Now()
+func (r *repository) LockAccounts(ctx context.Context, ids ...uuid.UUID) (map[uuid.UUID]*entity.Account, error) {
+	accounts, err := lockAccounts(r.getDB(ctx), ids...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock accounts")
+	}
 
-	if err := r.db.Save(&a).Error; err != nil {
-		return nil, decimal.Decimal{}, errors.Wrap(err, "failed to update account")
+	result := make(map[uuid.UUID]*entity.Account, len(accounts))
+	for id, a := range accounts {
+		result[id] = accountFromModel(a)
 	}
 
-	return &entity.Account{
-		ID:        a.ID,
-		UserID:    a.UserID,
-		Type:      a.Type,
-		Name:      a.Name,
-		Bank:      a.Bank,
-		Balance:   a.Balance,
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
-	}, differenceBalance, nil
+	return result, nil
+}
+
+func (r *repository) SetBalance(ctx context.Context, id uuid.UUID, balance decimal.Decimal) error {
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		accounts, err := lockAccounts(tx, id)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+		a := accounts[id]
+
+		a.Balance = balance
+		a.UpdatedAt = time.Now()
+
+		if err := tx.Save(a).Error; err != nil {
+			return errors.Wrap(err, "failed to update account")
+		}
+
+		return nil
+	})
 }
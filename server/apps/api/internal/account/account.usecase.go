@@ -2,6 +2,8 @@ package account
 
 import (
 	"context"
+	"sort"
+	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
@@ -11,16 +13,22 @@ import (
 )
 
 type usecase struct {
-	accountRepo     interfaces.AccountRepository
-	pocketRepo      interfaces.PocketRepository
-	transactionRepo interfaces.TransactionRepository
+	txManager         interfaces.TxManager
+	accountRepo       interfaces.AccountRepository
+	pocketRepo        interfaces.PocketRepository
+	transactionRepo   interfaces.TransactionRepository
+	currencyUsecase   interfaces.CurrencyUsecase
+	allocationUsecase interfaces.AllocationUsecase
 }
 
-func NewUsecase(accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+func NewUsecase(txManager interfaces.TxManager, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository, currencyUsecase interfaces.CurrencyUsecase, allocationUsecase interfaces.AllocationUsecase) *usecase {
 	return &usecase{
-		accountRepo:     accountRepo,
-		pocketRepo:      pocketRepo,
-		transactionRepo: transactionRepo,
+		txManager:         txManager,
+		accountRepo:       accountRepo,
+		pocketRepo:        pocketRepo,
+		transactionRepo:   transactionRepo,
+		currencyUsecase:   currencyUsecase,
+		allocationUsecase: allocationUsecase,
 	}
 }
 
@@ -42,26 +50,137 @@ func (u *usecase) GetAccount(ctx context.Context, userID uuid.UUID, id uuid.UUID
 	return account, nil
 }
 
+// GetAccountValue returns the account with its balance in the user's base currency.
+func (u *usecase) GetAccountValue(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.AccountValue, string, error) {
+	account, err := u.GetAccount(ctx, userID, id)
+	if err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	baseCurrency, err := u.currencyUsecase.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to get base currency")
+	}
+
+	value, err := u.currencyUsecase.ToBase(ctx, baseCurrency, account.Balance, account.Currency, time.Now())
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to convert balance")
+	}
+
+	return &entity.AccountValue{
+		Account:     *account,
+		BaseBalance: value.BaseAmount,
+	}, baseCurrency, nil
+}
+
+// GetSummary values every account of the user in their base currency and
+// totals the balances per currency.
+func (u *usecase) GetSummary(ctx context.Context, userID uuid.UUID) (*entity.AccountSummary, error) {
+	baseCurrency, err := u.currencyUsecase.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base currency")
+	}
+
+	accounts, err := u.GetAccounts(ctx, userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	summary := &entity.AccountSummary{
+		BaseCurrency: baseCurrency,
+		Accounts:     make([]entity.AccountValue, 0, len(accounts)),
+	}
+
+	now := time.Now()
+	totals := make(map[string]decimal.Decimal)
+	for _, account := range accounts {
+		value, err := u.currencyUsecase.ToBase(ctx, baseCurrency, account.Balance, account.Currency, now)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert balance")
+		}
+
+		if value.BaseAmount != nil {
+			if account.Type.IsLiability() {
+				summary.BaseLiabilities = summary.BaseLiabilities.Sub(*value.BaseAmount)
+			} else {
+				summary.BaseAssets = summary.BaseAssets.Add(*value.BaseAmount)
+			}
+		}
+
+		summary.Accounts = append(summary.Accounts, entity.AccountValue{
+			Account:     account,
+			BaseBalance: value.BaseAmount,
+		})
+		totals[account.Currency] = totals[account.Currency].Add(account.Balance)
+	}
+
+	currencies := make([]string, 0, len(totals))
+	for c := range totals {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+
+	for _, c := range currencies {
+		total, err := u.currencyUsecase.ToBase(ctx, baseCurrency, totals[c], c, now)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert total")
+		}
+
+		summary.Currencies = append(summary.Currencies, total)
+		if total.BaseAmount == nil {
+			summary.Unconverted = append(summary.Unconverted, c)
+			continue
+		}
+		summary.BaseTotal = summary.BaseTotal.Add(*total.BaseAmount)
+	}
+
+	return summary, nil
+}
+
 func (u *usecase) CreateAccount(ctx context.Context, input entity.AccountInput) (*entity.Account, error) {
-	account, err := u.accountRepo.CreateAccount(ctx, input)
+	if !input.Type.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidAccountType)
+	}
+
+	currency, err := entity.NormalizeCurrency(input.Currency)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create account")
+		return nil, errors.WithStack(err)
 	}
+	input.Currency = currency
+
+	var account *entity.Account
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		account, err = u.accountRepo.CreateAccount(ctx, input)
+		if err != nil {
+			return errors.Wrap(err, "failed to create account")
+		}
 
-	_, err = u.pocketRepo.CreatePocket(ctx, entity.PocketInput{
-		UserID:    input.UserID,
-		AccountID: account.ID,
-		Name:      "Cashbox",
-		Type:      entity.PocketTypeCashBox,
+		_, err = u.pocketRepo.CreatePocket(ctx, entity.PocketInput{
+			UserID:    input.UserID,
+			AccountID: account.ID,
+			Name:      "Cashbox",
+			Type:      entity.PocketTypeCashBox,
+			Currency:  account.Currency,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create cashbox pocket")
+		}
+
+		return nil
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create cashbox pocket")
+		return nil, errors.WithStack(err)
 	}
 
 	return account, nil
 }
 
 func (u *usecase) UpdateAccount(ctx context.Context, userID uuid.UUID, id uuid.UUID, input entity.AccountInput) (*entity.Account, error) {
+	if input.Type != "" && !input.Type.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidAccountType)
+	}
+
 	// Check ownership
 	if _, err := u.accountRepo.GetUserAccount(ctx, userID, id); err != nil {
 		return nil, errors.Wrap(err, "failed to get account")
@@ -75,6 +194,34 @@ func (u *usecase) UpdateAccount(ctx context.Context, userID uuid.UUID, id uuid.U
 	return account, nil
 }
 
+// ReorderAccounts saves the order of the user's accounts. The given accounts
+// come first, the ones left out keep their order after them.
+func (u *usecase) ReorderAccounts(ctx context.Context, userID uuid.UUID, accountIDs []uuid.UUID) error {
+	accounts, err := u.accountRepo.GetUserAccounts(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get accounts")
+	}
+
+	valid := make(map[uuid.UUID]bool, len(accounts))
+	for _, a := range accounts {
+		valid[a.ID] = true
+	}
+
+	for _, id := range accountIDs {
+		if !valid[id] {
+			return errors.WithStack(entity.ErrInvalidAccountOrder)
+		}
+		// Each account can be listed once
+		valid[id] = false
+	}
+
+	if err := u.accountRepo.ReorderAccounts(ctx, userID, accountIDs); err != nil {
+		return errors.Wrap(err, "failed to reorder accounts")
+	}
+
+	return nil
+}
+
 func (u *usecase) DeleteAccount(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
 	// Check ownership
 	if _, err := u.accountRepo.GetUserAccount(ctx, userID, id); err != nil {
@@ -89,63 +236,95 @@ func (u *usecase) DeleteAccount(ctx context.Context, userID uuid.UUID, id uuid.U
 	return nil
 }
 
-func (u *usecase) getCashboxPocket(ctx context.Context, accountID uuid.UUID) (*entity.Pocket, error) {
-	pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, accountID)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get pockets")
+// Deposit puts money into the account through its cashbox pocket, then splits
+// it into pockets by the account's allocation rules.
+func (u *usecase) Deposit(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal, note string) error {
+	// Check ownership
+	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
+		return errors.Wrap(err, "failed to get account")
 	}
 
-	if len(pockets) == 0 {
-		return nil, errors.New("pockets not found")
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cashbox pocket")
 	}
 
-	// Get cashbox pocket
-	var cashbox entity.Pocket
-	for _, pocket := range pockets {
-		if pocket.Type == entity.PocketTypeCashBox {
-			cashbox = pocket
-			break
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Create Transaction
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    cashbox.AccountID,
+			FromPocketID: nil,
+			ToPocketID:   &cashbox.ID,
+			Type:         entity.TxTypeDeposit,
+			Amount:       amount,
+			Note:         note,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create transaction")
 		}
-	}
 
-	return &cashbox, nil
-}
+		// Update account balance
+		if err := u.accountRepo.Deposit(ctx, accountID, amount); err != nil {
+			return errors.Wrap(err, "failed to deposit")
+		}
+
+		// Allocating the deposit needs every pocket of the account, so lock them
+		// in id order before the cashbox alone
+		if _, err := u.pocketRepo.LockPocketsByAccountID(ctx, accountID); err != nil {
+			return errors.Wrap(err, "failed to lock pockets")
+		}
+
+		// Update cashbox pocket balance
+		if err := u.pocketRepo.Deposit(ctx, cashbox.ID, amount); err != nil {
+			return errors.Wrap(err, "failed to deposit to cashbox pocket")
+		}
+
+		// Split the deposit into pockets by the account's allocation rules
+		if _, err := u.allocationUsecase.Allocate(ctx, cashbox, amount); err != nil {
+			return errors.Wrap(err, "failed to allocate deposit")
+		}
 
-func (u *usecase) Deposit(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal) error {
-	// TODO: Lock db transaction
+		return nil
+	})
+}
 
+// Withdraw takes money out of the account through its cashbox pocket.
+func (u *usecase) Withdraw(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal, note, payee string) error {
 	// Check ownership
 	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
 		return errors.Wrap(err, "failed to get account")
 	}
 
-	cashbox, err := u.getCashboxPocket(ctx, accountID)
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, accountID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get cashbox pocket")
 	}
 
-	// Create Transaction
-	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
-		AccountID:    cashbox.AccountID,
-		FromPocketID: nil,
-		ToPocketID:   &cashbox.ID,
-		Type:         entity.TxTypeDeposit,
-		Amount:       amount,
-	}); err != nil {
-		return errors.Wrap(err, "failed to create transaction")
-	}
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Create Transaction
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    cashbox.AccountID,
+			FromPocketID: &cashbox.ID,
+			ToPocketID:   nil,
+			Type:         entity.TxTypeWithdraw,
+			Amount:       amount,
+			Note:         note,
+			Payee:        payee,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
 
-	// Update account balance
-	if err := u.accountRepo.Deposit(ctx, accountID, amount); err != nil {
-		return errors.Wrap(err, "failed to deposit")
-	}
+		// Update account balance
+		if err := u.accountRepo.Withdraw(ctx, accountID, amount); err != nil {
+			return errors.Wrap(err, "failed to withdraw")
+		}
 
-	// Update cashbox pocket balance
-	if err = u.pocketRepo.Deposit(ctx, cashbox.ID, amount); err != nil {
-		return errors.Wrap(err, "failed to deposit to cashbox pocket")
-	}
+		// Update cashbox pocket balance
+		if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, amount); err != nil {
+			return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // func (u *usecase) UpdateBalance(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, amount decimal.Decimal) (*entity.Account, error) {
@@ -162,7 +341,7 @@ func (u *usecase) Deposit(ctx context.Context, userID uuid.UUID, accountID uuid.
 // 		return nil, errors.Wrap(err, "failed to update account")
 // 	}
 
-// 	cashbox, err := u.getCashboxPocket(ctx, accountID)
+// 	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, accountID)
 // 	if err != nil {
 // 		return nil, errors.Wrap(err, "failed to get cashbox pocket")
 // 	}
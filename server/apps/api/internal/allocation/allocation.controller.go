@@ -0,0 +1,263 @@
+package allocation
+
+import (
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase        *Usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(allocationUsecase *Usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        allocationUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/allocation-rules", h.GetRules)
+	r.Put("/:id/allocation-rules", h.SetRules)
+	r.Post("/:id/allocation-rules/preview", h.Preview)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidAllocationRules):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Rules need an asset account, a valid type, a positive value of at most 100 for PERCENT, positive caps and a distinct NORMAL pocket of the account",
+		})
+	}
+
+	return false, nil
+}
+
+type allocationRuleResponse struct {
+	ID         uuid.UUID                 `json:"id"`
+	PocketID   uuid.UUID                 `json:"pocketId"`
+	Type       entity.AllocationRuleType `json:"type"`
+	Value      decimal.Decimal           `json:"value"`
+	MaxAmount  *decimal.Decimal          `json:"maxAmount"`
+	MaxBalance *decimal.Decimal          `json:"maxBalance"`
+	Priority   int                       `json:"priority"`
+	CreatedAt  int64                     `json:"createdAt"`
+	UpdatedAt  int64                     `json:"updatedAt"`
+}
+
+func newAllocationRulesResponse(rules []entity.AllocationRule) []allocationRuleResponse {
+	response := make([]allocationRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, allocationRuleResponse{
+			ID:         rule.ID,
+			PocketID:   rule.PocketID,
+			Type:       rule.Type,
+			Value:      rule.Value,
+			MaxAmount:  rule.MaxAmount,
+			MaxBalance: rule.MaxBalance,
+			Priority:   rule.Priority,
+			CreatedAt:  rule.CreatedAt.Unix(),
+			UpdatedAt:  rule.UpdatedAt.Unix(),
+		})
+	}
+
+	return response
+}
+
+func (h *controller) GetRules(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	rules, err := h.usecase.GetRules(ctx.UserContext(), userID, accountID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get allocation rules")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newAllocationRulesResponse(rules),
+	})
+}
+
+type allocationRuleRequest struct {
+	PocketID   uuid.UUID                 `json:"pocketId"`
+	Type       entity.AllocationRuleType `json:"type"`
+	Value      decimal.Decimal           `json:"value"`
+	MaxAmount  *decimal.Decimal          `json:"maxAmount"`
+	MaxBalance *decimal.Decimal          `json:"maxBalance"`
+}
+
+type setRulesRequest struct {
+	Id    uuid.UUID               `params:"id"`
+	Rules []allocationRuleRequest `json:"rules"` // By priority, empty removes the rules
+}
+
+func (a *setRulesRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setRulesRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	for _, rule := range a.Rules {
+		v.Must(rule.PocketID != uuid.Nil, "pocketId is required")
+		v.Must(rule.Type.IsValid(), "type must be FIXED or PERCENT")
+	}
+
+	return errors.WithStack(v.Error())
+}
+
+// SetRules replaces the allocation rules of the account.
+func (h *controller) SetRules(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setRulesRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	input := entity.AllocationRulesInput{
+		UserID:    userID,
+		AccountID: req.Id,
+		Rules:     make([]entity.AllocationRule, 0, len(req.Rules)),
+	}
+	for _, rule := range req.Rules {
+		input.Rules = append(input.Rules, entity.AllocationRule{
+			PocketID:   rule.PocketID,
+			Type:       rule.Type,
+			Value:      rule.Value,
+			MaxAmount:  rule.MaxAmount,
+			MaxBalance: rule.MaxBalance,
+		})
+	}
+
+	rules, err := h.usecase.SetRules(ctx.UserContext(), input)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set allocation rules")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newAllocationRulesResponse(rules),
+	})
+}
+
+type previewRequest struct {
+	Id     uuid.UUID       `params:"id"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+func (a *previewRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *previewRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Amount.IsPositive(), "amount must be positive")
+
+	return errors.WithStack(v.Error())
+}
+
+type allocationTransferResponse struct {
+	RuleID   uuid.UUID       `json:"ruleId"`
+	PocketID uuid.UUID       `json:"pocketId"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+type allocationPlanResponse struct {
+	Amount    decimal.Decimal              `json:"amount"`
+	Transfers []allocationTransferResponse `json:"transfers"`
+	Remaining decimal.Decimal              `json:"remaining"`
+}
+
+// Preview returns how a deposit would be split without moving any money.
+func (h *controller) Preview(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req previewRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	plan, err := h.usecase.Preview(ctx.UserContext(), userID, req.Id, req.Amount)
+	if err != nil {
+		return errors.Wrap(err, "failed to preview allocation")
+	}
+
+	response := allocationPlanResponse{
+		Amount:    plan.Amount,
+		Transfers: make([]allocationTransferResponse, 0, len(plan.Transfers)),
+		Remaining: plan.Remaining,
+	}
+	for _, t := range plan.Transfers {
+		response.Transfers = append(response.Transfers, allocationTransferResponse{
+			RuleID:   t.RuleID,
+			PocketID: t.PocketID,
+			Amount:   t.Amount,
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
@@ -0,0 +1,113 @@
+package allocation
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.AllocationRuleRepository {
+	db.AutoMigrate(&model.AllocationRule{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func nullDecimal(d *decimal.Decimal) decimal.NullDecimal {
+	if d == nil {
+		return decimal.NullDecimal{}
+	}
+
+	return decimal.NewNullDecimal(*d)
+}
+
+func decimalPtr(d decimal.NullDecimal) *decimal.Decimal {
+	if !d.Valid {
+		return nil
+	}
+
+	return &d.Decimal
+}
+
+func (r *repository) GetAllocationRules(ctx context.Context, accountID uuid.UUID) ([]entity.AllocationRule, error) {
+	var rules []*model.AllocationRule
+	if err := r.getDB(ctx).Where("account_id = ?", accountID).Order("priority asc, created_at asc").Find(&rules).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation rules")
+	}
+
+	result := make([]entity.AllocationRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, entity.AllocationRule{
+			ID:         rule.ID,
+			AccountID:  rule.AccountID,
+			PocketID:   rule.PocketID,
+			Type:       rule.Type,
+			Value:      rule.Value,
+			MaxAmount:  decimalPtr(rule.MaxAmount),
+			MaxBalance: decimalPtr(rule.MaxBalance),
+			Priority:   rule.Priority,
+			CreatedAt:  rule.CreatedAt,
+			UpdatedAt:  rule.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) SetAllocationRules(ctx context.Context, accountID uuid.UUID, rules []entity.AllocationRule) ([]entity.AllocationRule, error) {
+	now := time.Now()
+
+	rows := make([]model.AllocationRule, 0, len(rules))
+	for _, rule := range rules {
+		rows = append(rows, model.AllocationRule{
+			ID:         uuid.New(),
+			AccountID:  accountID,
+			PocketID:   rule.PocketID,
+			Type:       rule.Type,
+			Value:      rule.Value,
+			MaxAmount:  nullDecimal(rule.MaxAmount),
+			MaxBalance: nullDecimal(rule.MaxBalance),
+			Priority:   rule.Priority,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", accountID).Delete(&model.AllocationRule{}).Error; err != nil {
+			return errors.Wrap(err, "failed to delete allocation rules")
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&rows).Error; err != nil {
+			return errors.Wrap(err, "failed to create allocation rules")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return r.GetAllocationRules(ctx, accountID)
+}
@@ -0,0 +1,159 @@
+package allocation
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// allocationNote is the note of the transfers made by the rules.
+const allocationNote = "Allocation rule"
+
+type Usecase struct {
+	ruleRepo        interfaces.AllocationRuleRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(ruleRepo interfaces.AllocationRuleRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *Usecase {
+	return &Usecase{
+		ruleRepo:        ruleRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+func (u *Usecase) GetRules(ctx context.Context, userID, accountID uuid.UUID) ([]entity.AllocationRule, error) {
+	// Check ownership
+	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	rules, err := u.ruleRepo.GetAllocationRules(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation rules")
+	}
+
+	return rules, nil
+}
+
+// SetRules replaces the rules of an asset account. The rules run in the order
+// given, and each NORMAL pocket of the account that is not archived can have
+// one rule.
+func (u *Usecase) SetRules(ctx context.Context, input entity.AllocationRulesInput) ([]entity.AllocationRule, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, input.UserID, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type.IsLiability() {
+		return nil, errors.Wrap(entity.ErrInvalidAllocationRules, "liability accounts cannot allocate deposits")
+	}
+
+	pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pockets")
+	}
+
+	allocatable := make(map[uuid.UUID]bool, len(pockets))
+	for _, p := range pockets {
+		allocatable[p.ID] = p.Type == entity.PocketTypeNormal && !p.Archived
+	}
+
+	hundred := decimal.NewFromInt(100)
+	seen := make(map[uuid.UUID]bool, len(input.Rules))
+	rules := make([]entity.AllocationRule, 0, len(input.Rules))
+	for i, rule := range input.Rules {
+		valid := rule.Type.IsValid() &&
+			rule.Value.IsPositive() &&
+			(rule.Type != entity.AllocationRuleTypePercent || rule.Value.LessThanOrEqual(hundred)) &&
+			(rule.MaxAmount == nil || rule.MaxAmount.IsPositive()) &&
+			(rule.MaxBalance == nil || rule.MaxBalance.IsPositive()) &&
+			allocatable[rule.PocketID] &&
+			!seen[rule.PocketID]
+		if !valid {
+			return nil, errors.Wrapf(entity.ErrInvalidAllocationRules, "rule %d", i+1)
+		}
+		seen[rule.PocketID] = true
+
+		rule.Priority = i
+		rules = append(rules, rule)
+	}
+
+	rules, err = u.ruleRepo.SetAllocationRules(ctx, input.AccountID, rules)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set allocation rules")
+	}
+
+	return rules, nil
+}
+
+// Preview shows how a deposit of amount would be split right now, without
+// moving any money.
+func (u *Usecase) Preview(ctx context.Context, userID, accountID uuid.UUID, amount decimal.Decimal) (*entity.AllocationPlan, error) {
+	// Check ownership
+	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	rules, err := u.ruleRepo.GetAllocationRules(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation rules")
+	}
+
+	pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pockets")
+	}
+
+	plan := entity.PlanAllocation(rules, amount, pockets)
+	return &plan, nil
+}
+
+// Allocate splits amount, just deposited to the cashbox, into the pockets of
+// its account. It must run in the transaction of the deposit, after the
+// account and all its pockets have been locked, so the deposit and its
+// transfers commit together without waiting on pockets while holding the cashbox.
+func (u *Usecase) Allocate(ctx context.Context, cashbox *entity.Pocket, amount decimal.Decimal) (*entity.AllocationPlan, error) {
+	rules, err := u.ruleRepo.GetAllocationRules(ctx, cashbox.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation rules")
+	}
+
+	if len(rules) == 0 {
+		return &entity.AllocationPlan{Amount: amount, Remaining: amount}, nil
+	}
+
+	// Caps are checked against balances no other deposit can change meanwhile
+	pockets, err := u.pocketRepo.LockPocketsByAccountID(ctx, cashbox.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock pockets")
+	}
+
+	plan := entity.PlanAllocation(rules, amount, pockets)
+	for _, transfer := range plan.Transfers {
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    cashbox.AccountID,
+			FromPocketID: &cashbox.ID,
+			ToPocketID:   &transfer.PocketID,
+			Type:         entity.TxTypeTransfer,
+			Amount:       transfer.Amount,
+			Note:         allocationNote,
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to create transaction")
+		}
+
+		if err := u.pocketRepo.Transfer(ctx, cashbox.ID, transfer.PocketID, transfer.Amount); err != nil {
+			return nil, errors.Wrap(err, "failed to transfer")
+		}
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,237 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const monthLayout = "2006-01"
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(budgetUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        budgetUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:month", h.GetMonth)
+	r.Put("/:month/:pocketId", h.SetBudget)
+	r.Delete("/:month/:pocketId", h.DeleteBudget)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidBudgetPocket):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Only NORMAL pockets can have a budget",
+		})
+	case errors.Is(err, entity.ErrBudgetNotFound):
+		return true, ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Budget not set for this month",
+		})
+	}
+
+	return false, nil
+}
+
+type budgetLineResponse struct {
+	PocketID    uuid.UUID       `json:"pocketId"`
+	AccountID   uuid.UUID       `json:"accountId"`
+	PocketName  string          `json:"pocketName"`
+	Currency    string          `json:"currency"`
+	Budgeted    decimal.Decimal `json:"budgeted"`
+	CarriedOver decimal.Decimal `json:"carriedOver"`
+	Spent       decimal.Decimal `json:"spent"`
+	Remaining   decimal.Decimal `json:"remaining"`
+	Rollover    bool            `json:"rollover"`
+}
+
+type monthlyBudgetResponse struct {
+	Month string               `json:"month"`
+	Lines []budgetLineResponse `json:"lines"`
+}
+
+func (h *controller) GetMonth(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	month, err := time.Parse(monthLayout, ctx.Params("month"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Month must be in YYYY-MM format",
+		})
+	}
+
+	budget, err := h.usecase.GetMonth(ctx.UserContext(), userID, month)
+	if err != nil {
+		return errors.Wrap(err, "failed to get budget")
+	}
+
+	response := monthlyBudgetResponse{
+		Month: budget.Month.Format(monthLayout),
+		Lines: make([]budgetLineResponse, 0, len(budget.Lines)),
+	}
+	for _, l := range budget.Lines {
+		response.Lines = append(response.Lines, budgetLineResponse{
+			PocketID:    l.PocketID,
+			AccountID:   l.AccountID,
+			PocketName:  l.PocketName,
+			Currency:    l.Currency,
+			Budgeted:    l.Budgeted,
+			CarriedOver: l.CarriedOver,
+			Spent:       l.Spent,
+			Remaining:   l.Remaining,
+			Rollover:    l.Rollover,
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type setBudgetRequest struct {
+	Month    string          `params:"month"` // 2006-01
+	PocketID uuid.UUID       `params:"pocketId"`
+	Amount   decimal.Decimal `json:"amount"`
+	Rollover bool            `json:"rollover"`
+
+	month time.Time
+}
+
+func (a *setBudgetRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	month, err := time.Parse(monthLayout, a.Month)
+	if err != nil {
+		return errors.Wrap(err, "invalid month")
+	}
+	a.month = month
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setBudgetRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.PocketID != uuid.Nil, "pocketId is required")
+	v.Must(!a.Amount.IsNegative(), "amount must not be negative")
+
+	return errors.WithStack(v.Error())
+}
+
+type budgetResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	PocketID  uuid.UUID       `json:"pocketId"`
+	Month     string          `json:"month"`
+	Amount    decimal.Decimal `json:"amount"`
+	Rollover  bool            `json:"rollover"`
+	CreatedAt int64           `json:"createdAt"`
+	UpdatedAt int64           `json:"updatedAt"`
+}
+
+// SetBudget sets the budget of the pocket from the month on.
+func (h *controller) SetBudget(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setBudgetRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	budget, err := h.usecase.SetBudget(ctx.UserContext(), entity.BudgetInput{
+		UserID:   userID,
+		PocketID: req.PocketID,
+		Month:    req.month,
+		Amount:   req.Amount,
+		Rollover: req.Rollover,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set budget")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: budgetResponse{
+			ID:        budget.ID,
+			PocketID:  budget.PocketID,
+			Month:     budget.Month.Format(monthLayout),
+			Amount:    budget.Amount,
+			Rollover:  budget.Rollover,
+			CreatedAt: budget.CreatedAt.Unix(),
+			UpdatedAt: budget.UpdatedAt.Unix(),
+		},
+	})
+}
+
+func (h *controller) DeleteBudget(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	month, err := time.Parse(monthLayout, ctx.Params("month"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Month must be in YYYY-MM format",
+		})
+	}
+
+	pocketID, err := uuid.Parse(ctx.Params("pocketId"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid pocket ID",
+		})
+	}
+
+	err = h.usecase.DeleteBudget(ctx.UserContext(), userID, pocketID, month)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to delete budget")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "Budget deleted",
+	})
+}
@@ -0,0 +1,106 @@
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.BudgetRepository {
+	db.AutoMigrate(&model.Budget{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetBudgets(ctx context.Context, pocketIDs []uuid.UUID, until time.Time) ([]entity.Budget, error) {
+	if len(pocketIDs) == 0 {
+		return nil, nil
+	}
+
+	var budgets []*model.Budget
+	if err := r.getDB(ctx).Where("pocket_id IN ? AND month <= ?", pocketIDs, until).Order("month asc").Find(&budgets).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get budgets")
+	}
+
+	result := make([]entity.Budget, 0, len(budgets))
+	for _, b := range budgets {
+		result = append(result, entity.Budget{
+			ID:        b.ID,
+			PocketID:  b.PocketID,
+			Month:     entity.MonthStart(b.Month),
+			Amount:    b.Amount,
+			Rollover:  b.Rollover,
+			CreatedAt: b.CreatedAt,
+			UpdatedAt: b.UpdatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) SaveBudget(ctx context.Context, budget entity.Budget) (*entity.Budget, error) {
+	now := time.Now()
+	b := model.Budget{
+		ID:        uuid.New(),
+		PocketID:  budget.PocketID,
+		Month:     budget.Month,
+		Amount:    budget.Amount,
+		Rollover:  budget.Rollover,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "pocket_id"}, {Name: "month"}},
+		DoUpdates: clause.AssignmentColumns([]string{"amount", "rollover", "updated_at"}),
+	}).Create(&b).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to save budget")
+	}
+
+	// On conflict the existing row keeps its id and created_at
+	var saved model.Budget
+	if err := r.getDB(ctx).Where("pocket_id = ? AND month = ?", budget.PocketID, budget.Month).First(&saved).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get budget")
+	}
+
+	return &entity.Budget{
+		ID:        saved.ID,
+		PocketID:  saved.PocketID,
+		Month:     entity.MonthStart(saved.Month),
+		Amount:    saved.Amount,
+		Rollover:  saved.Rollover,
+		CreatedAt: saved.CreatedAt,
+		UpdatedAt: saved.UpdatedAt,
+	}, nil
+}
+
+func (r *repository) DeleteBudget(ctx context.Context, pocketID uuid.UUID, month time.Time) error {
+	result := r.getDB(ctx).Where("pocket_id = ? AND month = ?", pocketID, month).Delete(&model.Budget{})
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "failed to delete budget")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.WithStack(entity.ErrBudgetNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type usecase struct {
+	budgetRepo      interfaces.BudgetRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(budgetRepo interfaces.BudgetRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+	return &usecase{
+		budgetRepo:      budgetRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// GetMonth returns the budget of every NORMAL pocket of the user that has one
+// in month. Rollovers are replayed from the first budgeted month of each
+// pocket, so editing an earlier month carries through to the later ones.
+func (u *usecase) GetMonth(ctx context.Context, userID uuid.UUID, month time.Time) (*entity.MonthlyBudget, error) {
+	month = entity.MonthStart(month)
+
+	accounts, err := u.accountRepo.GetUserAccounts(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get accounts")
+	}
+
+	var pockets []entity.Pocket
+	for _, account := range accounts {
+		accountPockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, account.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pockets")
+		}
+
+		for _, p := range accountPockets {
+			if p.Type == entity.PocketTypeNormal {
+				pockets = append(pockets, p)
+			}
+		}
+	}
+
+	pocketIDs := make([]uuid.UUID, 0, len(pockets))
+	for _, p := range pockets {
+		pocketIDs = append(pocketIDs, p.ID)
+	}
+
+	budgets, err := u.budgetRepo.GetBudgets(ctx, pocketIDs, month)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get budgets")
+	}
+
+	result := &entity.MonthlyBudget{
+		Month: month,
+		Lines: make([]entity.BudgetLine, 0),
+	}
+	if len(budgets) == 0 {
+		return result, nil
+	}
+
+	budgetsByPocket := make(map[uuid.UUID][]entity.Budget)
+	for _, b := range budgets {
+		budgetsByPocket[b.PocketID] = append(budgetsByPocket[b.PocketID], b)
+	}
+
+	spending, err := u.transactionRepo.GetPocketSpending(ctx, pocketIDs, budgets[0].Month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get spending")
+	}
+
+	spent := make(map[uuid.UUID]map[string]decimal.Decimal)
+	for _, s := range spending {
+		if spent[s.PocketID] == nil {
+			spent[s.PocketID] = make(map[string]decimal.Decimal)
+		}
+		spent[s.PocketID][s.Month.Format(monthLayout)] = s.Amount
+	}
+
+	for _, p := range pockets {
+		pocketBudgets, ok := budgetsByPocket[p.ID]
+		if !ok {
+			continue
+		}
+
+		line := replay(pocketBudgets, spent[p.ID], month)
+		line.PocketID = p.ID
+		line.AccountID = p.AccountID
+		line.PocketName = p.Name
+		line.Currency = p.Currency
+		result.Lines = append(result.Lines, line)
+	}
+
+	return result, nil
+}
+
+// replay walks the months from the first budget of a pocket to month, carrying
+// what is left of each month over to the next when its budget rolls over.
+func replay(budgets []entity.Budget, spent map[string]decimal.Decimal, month time.Time) entity.BudgetLine {
+	var (
+		line    entity.BudgetLine
+		current entity.Budget
+		carry   decimal.Decimal
+	)
+
+	next := 0
+	for m := budgets[0].Month; !m.After(month); m = m.AddDate(0, 1, 0) {
+		for next < len(budgets) && !budgets[next].Month.After(m) {
+			current = budgets[next]
+			next++
+		}
+
+		line = entity.BudgetLine{
+			Budgeted:    current.Amount,
+			CarriedOver: carry,
+			Spent:       spent[m.Format(monthLayout)],
+			Rollover:    current.Rollover,
+		}
+		line.Remaining = line.Budgeted.Add(line.CarriedOver).Sub(line.Spent)
+
+		carry = decimal.Zero
+		if current.Rollover {
+			carry = line.Remaining
+		}
+	}
+
+	return line
+}
+
+// SetBudget budgets a NORMAL pocket from input.Month on.
+func (u *usecase) SetBudget(ctx context.Context, input entity.BudgetInput) (*entity.Budget, error) {
+	// Check ownership
+	pocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, input.PocketID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket")
+	}
+
+	if pocket.Type != entity.PocketTypeNormal {
+		return nil, errors.WithStack(entity.ErrInvalidBudgetPocket)
+	}
+
+	budget, err := u.budgetRepo.SaveBudget(ctx, entity.Budget{
+		PocketID: pocket.ID,
+		Month:    entity.MonthStart(input.Month),
+		Amount:   input.Amount,
+		Rollover: input.Rollover,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save budget")
+	}
+
+	return budget, nil
+}
+
+// DeleteBudget removes the budget set for month, so the pocket falls back to
+// its budget of an earlier month, if any.
+func (u *usecase) DeleteBudget(ctx context.Context, userID, pocketID uuid.UUID, month time.Time) error {
+	// Check ownership
+	if _, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID); err != nil {
+		return errors.Wrap(err, "failed to get pocket")
+	}
+
+	if err := u.budgetRepo.DeleteBudget(ctx, pocketID, entity.MonthStart(month)); err != nil {
+		return errors.Wrap(err, "failed to delete budget")
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/shopspring/decimal"
+)
+
+func TestReplay(t *testing.T) {
+	month := func(m time.Month) time.Time {
+		return time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	budget := func(m time.Month, amount int64, rollover bool) entity.Budget {
+		return entity.Budget{Month: month(m), Amount: decimal.NewFromInt(amount), Rollover: rollover}
+	}
+	spending := func(amounts map[time.Month]int64) map[string]decimal.Decimal {
+		spent := make(map[string]decimal.Decimal, len(amounts))
+		for m, amount := range amounts {
+			spent[month(m).Format(monthLayout)] = decimal.NewFromInt(amount)
+		}
+		return spent
+	}
+
+	budgets := []entity.Budget{
+		budget(time.January, 1000, true),
+		budget(time.March, 1500, false),
+	}
+	spent := spending(map[time.Month]int64{
+		time.January:  700,
+		time.February: 1200,
+		time.March:    500,
+		time.April:    2000,
+	})
+
+	tests := []struct {
+		name     string
+		budgets  []entity.Budget
+		history  map[string]decimal.Decimal
+		month    time.Month
+		budgeted int64
+		carried  int64
+		spent    int64
+		rollover bool
+	}{
+		{name: "first month", budgets: budgets, history: spent, month: time.January, budgeted: 1000, spent: 700, rollover: true},
+		{name: "budget carries on with the leftover", budgets: budgets, history: spent, month: time.February, budgeted: 1000, carried: 300, spent: 1200, rollover: true},
+		{name: "new budget takes the last rollover", budgets: budgets, history: spent, month: time.March, budgeted: 1500, carried: 100, spent: 500},
+		{name: "no rollover starts afresh", budgets: budgets, history: spent, month: time.April, budgeted: 1500, spent: 2000},
+		{name: "month without spending", budgets: budgets, history: spent, month: time.May, budgeted: 1500},
+		{
+			name:     "overspending rolls over too",
+			budgets:  []entity.Budget{budget(time.January, 100, true)},
+			history:  spending(map[time.Month]int64{time.January: 150}),
+			month:    time.February,
+			budgeted: 100,
+			carried:  -50,
+			rollover: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := replay(tt.budgets, tt.history, month(tt.month))
+
+			remaining := tt.budgeted + tt.carried - tt.spent
+			for _, field := range []struct {
+				name string
+				got  decimal.Decimal
+				want int64
+			}{
+				{"Budgeted", line.Budgeted, tt.budgeted},
+				{"CarriedOver", line.CarriedOver, tt.carried},
+				{"Spent", line.Spent, tt.spent},
+				{"Remaining", line.Remaining, remaining},
+			} {
+				if !field.got.Equal(decimal.NewFromInt(field.want)) {
+					t.Errorf("%s = %s, want %d", field.name, field.got, field.want)
+				}
+			}
+
+			if line.Rollover != tt.rollover {
+				t.Errorf("Rollover = %t, want %t", line.Rollover, tt.rollover)
+			}
+		})
+	}
+}
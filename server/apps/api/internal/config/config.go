@@ -1,7 +1,15 @@
 package config
 
 import (
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/deposit"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interest"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/jwt"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/admin"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/networth"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/pocket"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/price"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/reconcile"
 	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
 	"github.com/boomchanotai/assets-tracker/server/pkg/postgres"
 	"github.com/boomchanotai/assets-tracker/server/pkg/redis"
@@ -13,12 +21,20 @@ const (
 )
 
 type AppConfig struct {
-	Name     string          `mapstructure:"name"`
-	Port     int             `mapstructure:"port"`
-	Logger   logger.Config   `mapstructure:"logger"`
-	Postgres postgres.Config `mapstructure:"postgres"`
-	Redis    redis.Config    `mapstructure:"redis"`
-	JWT      jwt.Config      `mapstructure:"jwt"`
+	Name         string             `mapstructure:"name"`
+	Port         int                `mapstructure:"port"`
+	Logger       logger.Config      `mapstructure:"logger"`
+	Postgres     postgres.Config    `mapstructure:"postgres"`
+	Redis        redis.Config       `mapstructure:"redis"`
+	JWT          jwt.Config         `mapstructure:"jwt"`
+	Reconcile    reconcile.Config   `mapstructure:"reconcile"`
+	Idempotency  idempotency.Config `mapstructure:"idempotency"`
+	Transfer     pocket.Config      `mapstructure:"transfer"`
+	Price        price.Config       `mapstructure:"price"`
+	Admin        admin.Config       `mapstructure:"admin"`
+	FixedDeposit deposit.Config     `mapstructure:"fixed_deposit"`
+	Interest     interest.Config    `mapstructure:"interest"`
+	NetWorth     networth.Config    `mapstructure:"networth"`
 }
 
 func Load() *AppConfig {
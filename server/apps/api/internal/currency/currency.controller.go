@@ -0,0 +1,222 @@
+package currency
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase        *Usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(currencyUsecase *Usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        currencyUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/rate", h.GetRate)
+	r.Get("/base", h.GetBaseCurrency)
+	r.Put("/base", h.SetBaseCurrency)
+}
+
+// MountAdmin registers the routes on the admin group.
+func (h *controller) MountAdmin(r fiber.Router) {
+	r.Post("/rates", h.UploadRates)
+}
+
+type rateResponse struct {
+	From string          `json:"from"`
+	To   string          `json:"to"`
+	Date string          `json:"date"`
+	Rate decimal.Decimal `json:"rate"`
+}
+
+func (h *controller) GetRate(ctx *fiber.Ctx) error {
+	from, err := entity.NormalizeCurrency(ctx.Query("from"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid from currency",
+		})
+	}
+
+	to, err := entity.NormalizeCurrency(ctx.Query("to"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid to currency",
+		})
+	}
+
+	date := time.Now()
+	if q := ctx.Query("date"); q != "" {
+		d, err := time.Parse(dateLayout, q)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+				Error: "Invalid date",
+			})
+		}
+		date = d
+	}
+
+	rate, err := h.usecase.GetRate(ctx.UserContext(), from, to, date)
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Exchange rate not found",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get exchange rate")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: rateResponse{
+			From: rate.From,
+			To:   rate.To,
+			Date: rate.Date.Format(dateLayout),
+			Rate: rate.Rate,
+		},
+	})
+}
+
+type baseCurrencyResponse struct {
+	Currency string `json:"currency"`
+}
+
+func (h *controller) GetBaseCurrency(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	currency, err := h.usecase.GetBaseCurrency(ctx.UserContext(), userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get base currency")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: baseCurrencyResponse{
+			Currency: currency,
+		},
+	})
+}
+
+type setBaseCurrencyRequest struct {
+	Currency string `json:"currency"`
+}
+
+func (a *setBaseCurrencyRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setBaseCurrencyRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Currency != "", "currency is required")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) SetBaseCurrency(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setBaseCurrencyRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	currency, err := h.usecase.SetBaseCurrency(ctx.UserContext(), userID, req.Currency)
+	if errors.Is(err, entity.ErrInvalidCurrency) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid currency",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set base currency")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: baseCurrencyResponse{
+			Currency: currency,
+		},
+	})
+}
+
+type rateRecord struct {
+	From string          `json:"from"`
+	To   string          `json:"to"`
+	Date string          `json:"date"`
+	Rate decimal.Decimal `json:"rate"`
+}
+
+type uploadRatesResponse struct {
+	Saved int `json:"saved"`
+}
+
+// UploadRates stores a JSON array of {"from", "to", "date", "rate"} records.
+func (h *controller) UploadRates(ctx *fiber.Ctx) error {
+	var records []rateRecord
+	if err := ctx.BodyParser(&records); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid request",
+		})
+	}
+
+	rates := make([]entity.ExchangeRate, 0, len(records))
+	for _, record := range records {
+		date, err := time.Parse(dateLayout, record.Date)
+		if err != nil || record.From == "" || record.To == "" || !record.Rate.IsPositive() {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+				Error: "Each rate needs from, to, a 2006-01-02 date and a positive rate",
+			})
+		}
+
+		rates = append(rates, entity.ExchangeRate{
+			From: record.From,
+			To:   record.To,
+			Date: date,
+			Rate: record.Rate,
+		})
+	}
+
+	err := h.usecase.SaveRates(ctx.UserContext(), rates)
+	if errors.Is(err, entity.ErrInvalidCurrency) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid currency",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to upload exchange rates")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: uploadRatesResponse{
+			Saved: len(rates),
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package currency
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateLayout = "2006-01-02"
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.ExchangeRateRepository {
+	db.AutoMigrate(&model.ExchangeRate{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetRate(ctx context.Context, from, to string, date time.Time) (*entity.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	err := r.getDB(ctx).Where("from_currency = ? AND to_currency = ? AND date <= ?", from, to, date.Format(dateLayout)).Order("date desc").First(&rate).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(entity.ErrExchangeRateNotFound, "no rate for %s/%s on %s", from, to, date.Format(dateLayout))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exchange rate")
+	}
+
+	return &entity.ExchangeRate{
+		From: rate.FromCurrency,
+		To:   rate.ToCurrency,
+		Date: rate.Date,
+		Rate: rate.Rate,
+	}, nil
+}
+
+func (r *repository) SaveRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	// A single upsert must not touch the same row twice, so the last rate for
+	// a pair and date wins
+	latest := make(map[string]int, len(rates))
+	for i, rate := range rates {
+		latest[rate.From+rate.To+rate.Date.Format(dateLayout)] = i
+	}
+
+	now := time.Now()
+	rows := make([]model.ExchangeRate, 0, len(latest))
+	for i, rate := range rates {
+		if latest[rate.From+rate.To+rate.Date.Format(dateLayout)] != i {
+			continue
+		}
+
+		rows = append(rows, model.ExchangeRate{
+			ID:           uuid.New(),
+			FromCurrency: rate.From,
+			ToCurrency:   rate.To,
+			Date:         rate.Date,
+			Rate:         rate.Rate,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		})
+	}
+
+	if err := r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(&rows).Error; err != nil {
+		return errors.Wrap(err, "failed to save exchange rates")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package currency
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ratePlaces is the precision of derived rates and basePlaces of converted amounts.
+const (
+	ratePlaces = 10
+	basePlaces = 2
+)
+
+type Usecase struct {
+	rateRepo interfaces.ExchangeRateRepository
+	userRepo interfaces.UserRepository
+}
+
+func NewUsecase(rateRepo interfaces.ExchangeRateRepository, userRepo interfaces.UserRepository) *Usecase {
+	return &Usecase{
+		rateRepo: rateRepo,
+		userRepo: userRepo,
+	}
+}
+
+// GetRate returns the rate from one currency to another on date. Without a
+// stored rate for the pair it falls back to the inverse pair, then to a cross
+// rate through entity.DefaultCurrency.
+func (u *Usecase) GetRate(ctx context.Context, from, to string, date time.Time) (*entity.ExchangeRate, error) {
+	if from == to {
+		return &entity.ExchangeRate{
+			From: from,
+			To:   to,
+			Date: date,
+			Rate: decimal.NewFromInt(1),
+		}, nil
+	}
+
+	rate, err := u.getDirectRate(ctx, from, to, date)
+	if err == nil || !errors.Is(err, entity.ErrExchangeRateNotFound) {
+		return rate, errors.WithStack(err)
+	}
+
+	if from == entity.DefaultCurrency || to == entity.DefaultCurrency {
+		return nil, errors.WithStack(err)
+	}
+
+	toDefault, err := u.getDirectRate(ctx, from, entity.DefaultCurrency, date)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fromDefault, err := u.getDirectRate(ctx, entity.DefaultCurrency, to, date)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// The cross rate is only as recent as its older leg
+	rateDate := toDefault.Date
+	if fromDefault.Date.Before(rateDate) {
+		rateDate = fromDefault.Date
+	}
+
+	return &entity.ExchangeRate{
+		From: from,
+		To:   to,
+		Date: rateDate,
+		Rate: toDefault.Rate.Mul(fromDefault.Rate).Round(ratePlaces),
+	}, nil
+}
+
+func (u *Usecase) getDirectRate(ctx context.Context, from, to string, date time.Time) (*entity.ExchangeRate, error) {
+	rate, err := u.rateRepo.GetRate(ctx, from, to, date)
+	if err == nil {
+		return rate, nil
+	}
+	if !errors.Is(err, entity.ErrExchangeRateNotFound) {
+		return nil, errors.Wrap(err, "failed to get exchange rate")
+	}
+
+	inverse, err := u.rateRepo.GetRate(ctx, to, from, date)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get exchange rate")
+	}
+
+	return &entity.ExchangeRate{
+		From: from,
+		To:   to,
+		Date: inverse.Date,
+		Rate: decimal.NewFromInt(1).DivRound(inverse.Rate, ratePlaces),
+	}, nil
+}
+
+// ToBase values amount in the base currency. The base amount is left nil when
+// there is no exchange rate for the currency.
+func (u *Usecase) ToBase(ctx context.Context, baseCurrency string, amount decimal.Decimal, currency string, date time.Time) (entity.Money, error) {
+	money := entity.Money{
+		Currency: currency,
+		Amount:   amount,
+	}
+
+	rate, err := u.GetRate(ctx, currency, baseCurrency, date)
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		return money, nil
+	}
+	if err != nil {
+		return money, errors.WithStack(err)
+	}
+
+	baseAmount := amount.Mul(rate.Rate).Round(basePlaces)
+	money.BaseAmount = &baseAmount
+
+	return money, nil
+}
+
+// BaseRate returns the user's base currency and today's rate to it from
+// currency. The rate is nil when there is none.
+func (u *Usecase) BaseRate(ctx context.Context, userID uuid.UUID, currency string) (string, *decimal.Decimal, error) {
+	baseCurrency, err := u.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	rate, err := u.GetRate(ctx, currency, baseCurrency, time.Now())
+	if errors.Is(err, entity.ErrExchangeRateNotFound) {
+		return baseCurrency, nil, nil
+	}
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	return baseCurrency, &rate.Rate, nil
+}
+
+// Convert multiplies amount by rate, keeping nil when the rate is unknown.
+func Convert(amount decimal.Decimal, rate *decimal.Decimal) *decimal.Decimal {
+	if rate == nil {
+		return nil
+	}
+
+	converted := amount.Mul(*rate).Round(basePlaces)
+	return &converted
+}
+
+func (u *Usecase) GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := u.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get user")
+	}
+
+	if user.BaseCurrency == "" {
+		return entity.DefaultCurrency, nil
+	}
+
+	return user.BaseCurrency, nil
+}
+
+func (u *Usecase) SetBaseCurrency(ctx context.Context, userID uuid.UUID, currency string) (string, error) {
+	currency, err := entity.NormalizeCurrency(currency)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	user, err := u.userRepo.UpdateUser(ctx, userID, entity.UserInput{
+		BaseCurrency: currency,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update user")
+	}
+
+	return user.BaseCurrency, nil
+}
+
+func (u *Usecase) SaveRates(ctx context.Context, rates []entity.ExchangeRate) error {
+	for i := range rates {
+		from, err := entity.NormalizeCurrency(rates[i].From)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		to, err := entity.NormalizeCurrency(rates[i].To)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		rates[i].From, rates[i].To = from, to
+	}
+
+	if err := u.rateRepo.SaveRates(ctx, rates); err != nil {
+		return errors.Wrap(err, "failed to save exchange rates")
+	}
+
+	return nil
+}
@@ -0,0 +1,318 @@
+package deposit
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// Bound the days accrued in one go, as accrual holds the account locks
+	maxTermMonths    = 120
+	maxBackdateYears = 5
+)
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(depositUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        depositUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/fixed-deposit", h.GetFixedDeposit)
+	r.Put("/:id/fixed-deposit", h.SetTerms)
+	r.Get("/:id/fixed-deposit/projection", h.GetProjection)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrNotFixedDepositAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a fixed deposit account",
+		})
+	case errors.Is(err, entity.ErrFixedDepositNotFound):
+		return true, ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Fixed deposit terms not set",
+		})
+	case errors.Is(err, entity.ErrInvalidCompounding):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid compounding",
+		})
+	case errors.Is(err, entity.ErrInvalidMaturityAction):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid maturity action",
+		})
+	case errors.Is(err, entity.ErrInvalidSavingsPocket):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Savings pocket must be in another account with the same currency",
+		})
+	case errors.Is(err, entity.ErrStartDateTooEarly):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "startDate must not be before the account's first transaction",
+		})
+	}
+
+	return false, nil
+}
+
+type fixedDepositResponse struct {
+	AccountID          uuid.UUID                 `json:"accountId"`
+	Principal          decimal.Decimal           `json:"principal"`
+	AnnualRate         decimal.Decimal           `json:"annualRate"`
+	TermMonths         int                       `json:"termMonths"`
+	StartDate          string                    `json:"startDate"`
+	MaturityDate       string                    `json:"maturityDate"`
+	Compounding        entity.Compounding        `json:"compounding"`
+	WithholdingTaxRate decimal.Decimal           `json:"withholdingTaxRate"`
+	MaturityAction     entity.MaturityAction     `json:"maturityAction"`
+	SavingsPocketID    *uuid.UUID                `json:"savingsPocketId"`
+	Status             entity.FixedDepositStatus `json:"status"`
+	AccruedUntil       string                    `json:"accruedUntil"`
+	Renewals           int                       `json:"renewals"`
+	MaturedAt          *int64                    `json:"maturedAt"`
+	CreatedAt          int64                     `json:"createdAt"`
+	UpdatedAt          int64                     `json:"updatedAt"`
+}
+
+func newFixedDepositResponse(fd *entity.FixedDeposit) fixedDepositResponse {
+	response := fixedDepositResponse{
+		AccountID:          fd.AccountID,
+		Principal:          fd.Principal,
+		AnnualRate:         fd.AnnualRate,
+		TermMonths:         fd.TermMonths,
+		StartDate:          fd.StartDate.Format(dateLayout),
+		MaturityDate:       fd.MaturityDate().Format(dateLayout),
+		Compounding:        fd.Compounding,
+		WithholdingTaxRate: fd.WithholdingTaxRate,
+		MaturityAction:     fd.MaturityAction,
+		SavingsPocketID:    fd.SavingsPocketID,
+		Status:             fd.Status,
+		AccruedUntil:       fd.AccruedUntil.Format(dateLayout),
+		Renewals:           fd.Renewals,
+		CreatedAt:          fd.CreatedAt.Unix(),
+		UpdatedAt:          fd.UpdatedAt.Unix(),
+	}
+	if fd.MaturedAt != nil {
+		maturedAt := fd.MaturedAt.Unix()
+		response.MaturedAt = &maturedAt
+	}
+
+	return response
+}
+
+func (h *controller) GetFixedDeposit(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	fd, err := h.usecase.GetFixedDeposit(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get fixed deposit")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newFixedDepositResponse(fd),
+	})
+}
+
+type setTermsRequest struct {
+	Id                 uuid.UUID             `params:"id"`
+	Principal          *decimal.Decimal      `json:"principal"` // Defaults to the account balance
+	AnnualRate         decimal.Decimal       `json:"annualRate"`
+	TermMonths         int                   `json:"termMonths"`
+	StartDate          string                `json:"startDate"` // 2006-01-02, defaults to today
+	Compounding        entity.Compounding    `json:"compounding"`
+	WithholdingTaxRate decimal.Decimal       `json:"withholdingTaxRate"`
+	MaturityAction     entity.MaturityAction `json:"maturityAction"`
+	SavingsPocketID    *uuid.UUID            `json:"savingsPocketId"`
+
+	startDate time.Time
+}
+
+func (a *setTermsRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if a.Compounding == "" {
+		a.Compounding = entity.CompoundingNone
+	}
+
+	if a.MaturityAction == "" {
+		a.MaturityAction = entity.MaturityActionNone
+	}
+
+	now := time.Now()
+	a.startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if a.StartDate != "" {
+		startDate, err := time.Parse(dateLayout, a.StartDate)
+		if err != nil {
+			return errors.Wrap(err, "invalid startDate")
+		}
+		a.startDate = startDate
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setTermsRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Principal == nil || a.Principal.IsPositive(), "principal must be positive")
+	v.Must(!a.AnnualRate.IsNegative(), "annualRate must not be negative")
+	v.Must(a.TermMonths > 0 && a.TermMonths <= maxTermMonths, "termMonths must be between 1 and 120")
+	v.Must(!a.startDate.Before(time.Now().AddDate(-maxBackdateYears, 0, 0)), "startDate must be within the last 5 years")
+	v.Must(!a.WithholdingTaxRate.IsNegative() && a.WithholdingTaxRate.LessThanOrEqual(decimal.NewFromInt(100)), "withholdingTaxRate must be between 0 and 100")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) SetTerms(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setTermsRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	fd, err := h.usecase.SetTerms(ctx.UserContext(), entity.FixedDepositInput{
+		UserID:             userID,
+		AccountID:          req.Id,
+		Principal:          req.Principal,
+		AnnualRate:         req.AnnualRate,
+		TermMonths:         req.TermMonths,
+		StartDate:          req.startDate,
+		Compounding:        req.Compounding,
+		WithholdingTaxRate: req.WithholdingTaxRate,
+		MaturityAction:     req.MaturityAction,
+		SavingsPocketID:    req.SavingsPocketID,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set fixed deposit terms")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newFixedDepositResponse(fd),
+	})
+}
+
+type interestPostingResponse struct {
+	PeriodStart string          `json:"periodStart"`
+	Date        string          `json:"date"`
+	Principal   decimal.Decimal `json:"principal"`
+	Gross       decimal.Decimal `json:"gross"`
+	Tax         decimal.Decimal `json:"tax"`
+	Net         decimal.Decimal `json:"net"`
+	Posted      bool            `json:"posted"`
+}
+
+type projectionResponse struct {
+	Principal     decimal.Decimal           `json:"principal"`
+	MaturityDate  string                    `json:"maturityDate"`
+	GrossInterest decimal.Decimal           `json:"grossInterest"`
+	Tax           decimal.Decimal           `json:"tax"`
+	NetInterest   decimal.Decimal           `json:"netInterest"`
+	MaturityValue decimal.Decimal           `json:"maturityValue"`
+	Schedule      []interestPostingResponse `json:"schedule"`
+}
+
+// GetProjection returns the interest schedule of the current term and the
+// value at maturity.
+func (h *controller) GetProjection(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	fd, err := h.usecase.GetFixedDeposit(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get fixed deposit")
+	}
+
+	schedule := fd.Schedule()
+	response := projectionResponse{
+		Principal:     fd.Principal,
+		MaturityDate:  fd.MaturityDate().Format(dateLayout),
+		MaturityValue: fd.MaturityValue(),
+		Schedule:      make([]interestPostingResponse, 0, len(schedule)),
+	}
+	for _, p := range schedule {
+		response.Schedule = append(response.Schedule, interestPostingResponse{
+			PeriodStart: p.PeriodStart.Format(dateLayout),
+			Date:        p.Date.Format(dateLayout),
+			Principal:   p.Principal,
+			Gross:       p.Gross,
+			Tax:         p.Tax,
+			Net:         p.Net,
+			Posted:      !p.Date.After(fd.AccruedUntil),
+		})
+
+		response.GrossInterest = response.GrossInterest.Add(p.Gross)
+		response.Tax = response.Tax.Add(p.Tax)
+		response.NetInterest = response.NetInterest.Add(p.Net)
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
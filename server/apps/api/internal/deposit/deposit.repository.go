@@ -0,0 +1,113 @@
+package deposit
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.FixedDepositRepository {
+	db.AutoMigrate(&model.FixedDeposit{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func toEntity(fd *model.FixedDeposit) *entity.FixedDeposit {
+	return &entity.FixedDeposit{
+		ID:                 fd.ID,
+		AccountID:          fd.AccountID,
+		Principal:          fd.Principal,
+		AnnualRate:         fd.AnnualRate,
+		TermMonths:         fd.TermMonths,
+		StartDate:          fd.StartDate,
+		Compounding:        fd.Compounding,
+		WithholdingTaxRate: fd.WithholdingTaxRate,
+		MaturityAction:     fd.MaturityAction,
+		SavingsPocketID:    fd.SavingsPocketID,
+		Status:             fd.Status,
+		AccruedUntil:       fd.AccruedUntil,
+		Renewals:           fd.Renewals,
+		MaturedAt:          fd.MaturedAt,
+		CreatedAt:          fd.CreatedAt,
+		UpdatedAt:          fd.UpdatedAt,
+	}
+}
+
+func (r *repository) GetFixedDeposit(ctx context.Context, accountID uuid.UUID) (*entity.FixedDeposit, error) {
+	var fd model.FixedDeposit
+	err := r.getDB(ctx).Where("account_id = ?", accountID).First(&fd).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(entity.ErrFixedDepositNotFound)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fixed deposit")
+	}
+
+	return toEntity(&fd), nil
+}
+
+func (r *repository) GetActiveFixedDeposits(ctx context.Context) ([]entity.FixedDeposit, error) {
+	var fds []*model.FixedDeposit
+	if err := r.getDB(ctx).Where("status = ?", entity.FixedDepositStatusActive).Order("created_at asc").Find(&fds).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get fixed deposits")
+	}
+
+	var result []entity.FixedDeposit
+	for _, fd := range fds {
+		result = append(result, *toEntity(fd))
+	}
+
+	return result, nil
+}
+
+func (r *repository) SaveFixedDeposit(ctx context.Context, input entity.FixedDeposit) (*entity.FixedDeposit, error) {
+	var fd model.FixedDeposit
+	err := r.getDB(ctx).Where("account_id = ?", input.AccountID).First(&fd).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get fixed deposit")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fd = model.FixedDeposit{
+			ID:        uuid.New(),
+			AccountID: input.AccountID,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	fd.Principal = input.Principal
+	fd.AnnualRate = input.AnnualRate
+	fd.TermMonths = input.TermMonths
+	fd.StartDate = input.StartDate
+	fd.Compounding = input.Compounding
+	fd.WithholdingTaxRate = input.WithholdingTaxRate
+	fd.MaturityAction = input.MaturityAction
+	fd.SavingsPocketID = input.SavingsPocketID
+	fd.Status = input.Status
+	fd.AccruedUntil = input.AccruedUntil
+	fd.Renewals = input.Renewals
+	fd.MaturedAt = input.MaturedAt
+	fd.UpdatedAt = time.Now()
+
+	if err := r.getDB(ctx).Save(&fd).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to save fixed deposit")
+	}
+
+	return toEntity(&fd), nil
+}
@@ -0,0 +1,357 @@
+package deposit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	Interval int64 `mapstructure:"interval"` // Seconds between interest postings, 0 disables the runner
+}
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	depositRepo     interfaces.FixedDepositRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(txManager interfaces.TxManager, depositRepo interfaces.FixedDepositRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		depositRepo:     depositRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+func (u *usecase) getFixedDepositAccount(ctx context.Context, userID, accountID uuid.UUID) (*entity.Account, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type != entity.AccountTypeFixedDeposit {
+		return nil, errors.WithStack(entity.ErrNotFixedDepositAccount)
+	}
+
+	return account, nil
+}
+
+func (u *usecase) GetFixedDeposit(ctx context.Context, userID, accountID uuid.UUID) (*entity.FixedDeposit, error) {
+	if _, err := u.getFixedDepositAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fd, err := u.depositRepo.GetFixedDeposit(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fixed deposit")
+	}
+
+	return fd, nil
+}
+
+// SetTerms sets or replaces the term of the account and posts the interest
+// already due when the term started in the past. Interest posted under earlier
+// terms is not posted again.
+func (u *usecase) SetTerms(ctx context.Context, input entity.FixedDepositInput) (*entity.FixedDeposit, error) {
+	if !input.Compounding.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidCompounding)
+	}
+
+	if !input.MaturityAction.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidMaturityAction)
+	}
+
+	account, err := u.getFixedDepositAccount(ctx, input.UserID, input.AccountID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Interest can't accrue before the money was deposited
+	first, err := u.transactionRepo.GetFirstTransactionAt(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get first transaction")
+	}
+
+	if !first.IsZero() && input.StartDate.Before(first.UTC().Truncate(24*time.Hour)) {
+		return nil, errors.WithStack(entity.ErrStartDateTooEarly)
+	}
+
+	if input.MaturityAction == entity.MaturityActionMoveToSavings {
+		if input.SavingsPocketID == nil {
+			return nil, errors.WithStack(entity.ErrInvalidSavingsPocket)
+		}
+
+		pocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, *input.SavingsPocketID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get savings pocket")
+		}
+
+		if pocket.AccountID == input.AccountID || pocket.Currency != account.Currency {
+			return nil, errors.WithStack(entity.ErrInvalidSavingsPocket)
+		}
+	} else {
+		input.SavingsPocketID = nil
+	}
+
+	principal := account.Balance
+	if input.Principal != nil {
+		principal = *input.Principal
+	}
+
+	fd := entity.FixedDeposit{
+		AccountID:          input.AccountID,
+		Principal:          principal,
+		AnnualRate:         input.AnnualRate,
+		TermMonths:         input.TermMonths,
+		StartDate:          input.StartDate,
+		Compounding:        input.Compounding,
+		WithholdingTaxRate: input.WithholdingTaxRate,
+		MaturityAction:     input.MaturityAction,
+		SavingsPocketID:    input.SavingsPocketID,
+		Status:             entity.FixedDepositStatusActive,
+		AccruedUntil:       input.StartDate,
+	}
+
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := u.accountRepo.LockAccount(ctx, input.AccountID); err != nil {
+			return errors.Wrap(err, "failed to lock account")
+		}
+
+		current, err := u.depositRepo.GetFixedDeposit(ctx, input.AccountID)
+		if err != nil && !errors.Is(err, entity.ErrFixedDepositNotFound) {
+			return errors.Wrap(err, "failed to get fixed deposit")
+		}
+		if current != nil && current.AccruedUntil.After(fd.AccruedUntil) {
+			fd.AccruedUntil = current.AccruedUntil
+		}
+
+		if _, err := u.depositRepo.SaveFixedDeposit(ctx, fd); err != nil {
+			return errors.Wrap(err, "failed to save fixed deposit")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := u.accrue(ctx, input.AccountID, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "failed to accrue interest")
+	}
+
+	return u.GetFixedDeposit(ctx, input.UserID, input.AccountID)
+}
+
+// AccrueAll posts the interest due on every active fixed deposit and handles
+// the ones that reached maturity. A failing deposit is logged and retried on
+// the next run.
+func (u *usecase) AccrueAll(ctx context.Context) error {
+	fds, err := u.depositRepo.GetActiveFixedDeposits(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get fixed deposits")
+	}
+
+	now := time.Now()
+	for _, fd := range fds {
+		if err := u.accrue(ctx, fd.AccountID, now); err != nil {
+			logger.ErrorContext(ctx, "failed to accrue fixed deposit interest",
+				slog.String("account_id", fd.AccountID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// accrue posts every interest period that ended by now and was not posted yet.
+// Each posting is an INTEREST deposit of the gross interest and a
+// WITHHOLDING_TAX withdrawal of the tax, dated at the end of the period, both
+// on the cashbox pocket.
+func (u *usecase) accrue(ctx context.Context, accountID uuid.UUID, now time.Time) error {
+	movesToSavings := func(fd *entity.FixedDeposit) bool {
+		return fd.MaturityAction == entity.MaturityActionMoveToSavings && fd.SavingsPocketID != nil && !fd.MaturityDate().After(now)
+	}
+
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		account, err := u.accountRepo.GetAccount(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get account")
+		}
+
+		fd, err := u.depositRepo.GetFixedDeposit(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get fixed deposit")
+		}
+
+		if fd.Status != entity.FixedDepositStatusActive {
+			return nil
+		}
+
+		// Find the savings account first, so both accounts are locked in one
+		// id-ordered call before any pocket
+		accountIDs := []uuid.UUID{accountID}
+		var savingsPocket *entity.Pocket
+		if movesToSavings(fd) {
+			savingsPocket, err = u.pocketRepo.GetPocketByID(ctx, account.UserID, *fd.SavingsPocketID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get savings pocket")
+			}
+			accountIDs = append(accountIDs, savingsPocket.AccountID)
+		}
+
+		// Lock the accounts so concurrent runs don't post the same period twice
+		if _, err := u.accountRepo.LockAccounts(ctx, accountIDs...); err != nil {
+			return errors.Wrap(err, "failed to lock accounts")
+		}
+
+		fd, err = u.depositRepo.GetFixedDeposit(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get fixed deposit")
+		}
+
+		if fd.Status != entity.FixedDepositStatusActive {
+			return nil
+		}
+
+		// The terms were replaced before the lock was taken, the next run picks them up
+		if movesToSavings(fd) != (savingsPocket != nil) || (savingsPocket != nil && *fd.SavingsPocketID != savingsPocket.ID) {
+			return errors.New("fixed deposit terms changed while locking")
+		}
+
+		cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get cashbox pocket")
+		}
+
+		for {
+			for _, p := range fd.Schedule() {
+				if !p.Date.After(fd.AccruedUntil) || p.Date.After(now) {
+					continue
+				}
+
+				if err := u.post(ctx, accountID, cashbox.ID, p); err != nil {
+					return errors.WithStack(err)
+				}
+				fd.AccruedUntil = p.Date
+			}
+
+			maturity := fd.MaturityDate()
+			if maturity.After(now) {
+				break
+			}
+
+			cashbox, err = u.pocketRepo.GetCashboxPocket(ctx, accountID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get cashbox pocket")
+			}
+
+			if fd.MaturityAction == entity.MaturityActionRenew {
+				fd.StartDate = maturity
+				fd.AccruedUntil = maturity
+				fd.Principal = cashbox.Balance
+				fd.Renewals++
+				continue
+			}
+
+			if savingsPocket != nil && cashbox.Balance.IsPositive() {
+				if err := u.moveToSavings(ctx, cashbox, savingsPocket, cashbox.Balance); err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+			fd.Status = entity.FixedDepositStatusMatured
+			fd.MaturedAt = &maturity
+			break
+		}
+
+		if _, err := u.depositRepo.SaveFixedDeposit(ctx, *fd); err != nil {
+			return errors.Wrap(err, "failed to save fixed deposit")
+		}
+
+		return nil
+	})
+}
+
+func (u *usecase) post(ctx context.Context, accountID, cashboxID uuid.UUID, p entity.InterestPosting) error {
+	if p.Gross.IsPositive() {
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:  accountID,
+			ToPocketID: &cashboxID,
+			Type:       entity.TxTypeInterest,
+			Amount:     p.Gross,
+			Note:       "Fixed deposit interest " + p.PeriodStart.Format(dateLayout) + " to " + p.Date.Format(dateLayout),
+			CreatedAt:  p.Date,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create interest transaction")
+		}
+
+		if err := u.accountRepo.Deposit(ctx, accountID, p.Gross); err != nil {
+			return errors.Wrap(err, "failed to deposit to account")
+		}
+
+		if err := u.pocketRepo.Deposit(ctx, cashboxID, p.Gross); err != nil {
+			return errors.Wrap(err, "failed to deposit to cashbox pocket")
+		}
+	}
+
+	if p.Tax.IsPositive() {
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    accountID,
+			FromPocketID: &cashboxID,
+			Type:         entity.TxTypeWithholdingTax,
+			Amount:       p.Tax,
+			Note:         "Withholding tax on fixed deposit interest",
+			CreatedAt:    p.Date,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create withholding tax transaction")
+		}
+
+		if err := u.accountRepo.Withdraw(ctx, accountID, p.Tax); err != nil {
+			return errors.Wrap(err, "failed to withdraw from account")
+		}
+
+		if err := u.pocketRepo.Withdraw(ctx, cashboxID, p.Tax); err != nil {
+			return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+		}
+	}
+
+	return nil
+}
+
+func (u *usecase) moveToSavings(ctx context.Context, cashbox, savingsPocket *entity.Pocket, amount decimal.Decimal) error {
+	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+		AccountID:    cashbox.AccountID,
+		FromPocketID: &cashbox.ID,
+		ToPocketID:   &savingsPocket.ID,
+		ToAccountID:  &savingsPocket.AccountID,
+		Type:         entity.TxTypeTransfer,
+		Amount:       amount,
+		Note:         "Fixed deposit matured",
+	}); err != nil {
+		return errors.Wrap(err, "failed to create transaction")
+	}
+
+	if err := u.accountRepo.Transfer(ctx, cashbox.AccountID, savingsPocket.AccountID, amount); err != nil {
+		return errors.Wrap(err, "failed to transfer between accounts")
+	}
+
+	if err := u.pocketRepo.Transfer(ctx, cashbox.ID, savingsPocket.ID, amount); err != nil {
+		return errors.Wrap(err, "failed to transfer to savings pocket")
+	}
+
+	return nil
+}
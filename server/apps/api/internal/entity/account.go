@@ -9,7 +9,11 @@ import (
 )
 
 var (
-	ErrInvalidAccountType = errors.New("INVALID_ACCOUNT_TYPE")
+	ErrInvalidAccountType  = errors.New("INVALID_ACCOUNT_TYPE")
+	ErrInvalidAccountOrder = errors.New("INVALID_ACCOUNT_ORDER")
+	// ErrAccountClassChange rejects turning an asset account into a liability
+	// or back, which would flip the sign of its stored balances.
+	ErrAccountClassChange = errors.New("ACCOUNT_CLASS_CHANGE")
 )
 
 type AccountType string
@@ -20,19 +24,41 @@ const (
 	AccountTypeFCD          AccountType = "FCD"
 	AccountTypeMutualFund   AccountType = "MUTUAL_FUND"
 	AccountTypeStock        AccountType = "STOCK"
+	AccountTypeCreditCard   AccountType = "CREDIT_CARD"
+	AccountTypeLoan         AccountType = "LOAN"
 )
 
 func (at AccountType) String() string {
 	return string(at)
 }
 
+func (at AccountType) IsValid() bool {
+	switch at {
+	case AccountTypeSaving, AccountTypeFixedDeposit, AccountTypeFCD, AccountTypeMutualFund, AccountTypeStock, AccountTypeCreditCard, AccountTypeLoan:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsLiability reports whether the account holds debt. Its balance is negative
+// while money is owed, and may go below zero.
+func (at AccountType) IsLiability() bool {
+	return at == AccountTypeCreditCard || at == AccountTypeLoan
+}
+
 type Account struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
 	Type      AccountType
 	Name      string
 	Bank      string
+	Currency  string
 	Balance   decimal.Decimal
+	SortOrder int
+	Color     string
+	Icon      string
+	Archived  bool // Hidden from listings, still in totals and history
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -42,8 +68,32 @@ func (a Account) String() string {
 }
 
 type AccountInput struct {
-	UserID uuid.UUID
-	Type   AccountType
-	Name   string
-	Bank   string
+	UserID   uuid.UUID
+	Type     AccountType
+	Name     string
+	Bank     string
+	Currency string
+	Color    *string // Nil leaves the color unchanged
+	Icon     *string // Nil leaves the icon unchanged
+	Archived *bool   // Nil leaves the flag unchanged
+}
+
+// AccountSummary is the user's accounts valued in their base currency, with
+// native totals per currency.
+type AccountSummary struct {
+	BaseCurrency string
+	Accounts     []AccountValue
+	Currencies   []Money
+	// BaseTotal sums every balance that could be converted, so it is
+	// BaseAssets less BaseLiabilities. Currencies without a rate are listed in
+	// Unconverted and left out.
+	BaseTotal       decimal.Decimal
+	BaseAssets      decimal.Decimal
+	BaseLiabilities decimal.Decimal // Owed on liability accounts, positive
+	Unconverted     []string
+}
+
+type AccountValue struct {
+	Account
+	BaseBalance *decimal.Decimal
 }
@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidAllocationDimension = errors.New("INVALID_ALLOCATION_DIMENSION")
+	ErrInvalidAllocationTargets   = errors.New("INVALID_ALLOCATION_TARGETS")
+)
+
+// AllocationDimension is what the asset allocation is grouped by.
+type AllocationDimension string
+
+const (
+	AllocationDimensionType     AllocationDimension = "TYPE"
+	AllocationDimensionBank     AllocationDimension = "BANK"
+	AllocationDimensionCurrency AllocationDimension = "CURRENCY"
+	AllocationDimensionPocket   AllocationDimension = "POCKET"
+)
+
+var AllocationDimensions = []AllocationDimension{
+	AllocationDimensionType,
+	AllocationDimensionBank,
+	AllocationDimensionCurrency,
+	AllocationDimensionPocket,
+}
+
+func (d AllocationDimension) IsValid() bool {
+	for _, dimension := range AllocationDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllocationTarget is the weight, in percent, the user wants Key to have in
+// Dimension. Key is an account type, a bank, a currency or a pocket ID.
+type AllocationTarget struct {
+	Dimension AllocationDimension
+	Key       string
+	Weight    decimal.Decimal
+}
+
+type AllocationTargetsInput struct {
+	UserID    uuid.UUID
+	Dimension AllocationDimension
+	Targets   []AllocationTarget // Weights sum to 100, or empty to clear
+}
+
+// AllocationGroup is one slice of an allocation. The target fields are nil
+// when the dimension has no targets. Delta is what to add to Amount, or take
+// away when negative, to reach the target.
+type AllocationGroup struct {
+	Key          string
+	Label        string
+	Amount       decimal.Decimal
+	Percent      decimal.Decimal
+	TargetWeight *decimal.Decimal
+	TargetAmount *decimal.Decimal
+	Delta        *decimal.Decimal
+}
+
+type Allocation struct {
+	Dimension AllocationDimension
+	Groups    []AllocationGroup
+}
+
+// AllocationReport splits the assets of the user, in their base currency, by
+// every dimension. Liability accounts are left out, and so are balances that
+// could not be converted, whose currencies are listed in Unconverted.
+type AllocationReport struct {
+	BaseCurrency string
+	Total        decimal.Decimal
+	Allocations  []Allocation
+	Unconverted  []string
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrBudgetNotFound      = errors.New("BUDGET_NOT_FOUND")
+	ErrInvalidBudgetPocket = errors.New("INVALID_BUDGET_POCKET")
+)
+
+// MonthStart returns the first instant of the UTC month of t.
+func MonthStart(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Budget is the monthly budget of a NORMAL pocket from Month on, until a
+// budget of a later month replaces it. With Rollover, what is left of a
+// month, or overspent, carries over to the next one.
+type Budget struct {
+	ID        uuid.UUID
+	PocketID  uuid.UUID
+	Month     time.Time
+	Amount    decimal.Decimal
+	Rollover  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type BudgetInput struct {
+	UserID   uuid.UUID
+	PocketID uuid.UUID
+	Month    time.Time
+	Amount   decimal.Decimal
+	Rollover bool
+}
+
+// PocketSpending is what was withdrawn from a pocket in a month, net of the
+// reversals made that month.
+type PocketSpending struct {
+	PocketID uuid.UUID
+	Month    time.Time
+	Amount   decimal.Decimal
+}
+
+// BudgetLine is the state of one pocket's budget in a month. Remaining is
+// Budgeted plus CarriedOver minus Spent, negative when overspent.
+type BudgetLine struct {
+	PocketID    uuid.UUID
+	AccountID   uuid.UUID
+	PocketName  string
+	Currency    string
+	Budgeted    decimal.Decimal
+	CarriedOver decimal.Decimal
+	Spent       decimal.Decimal
+	Remaining   decimal.Decimal
+	Rollover    bool
+}
+
+type MonthlyBudget struct {
+	Month time.Time
+	Lines []BudgetLine
+}
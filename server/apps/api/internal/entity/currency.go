@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidCurrency      = errors.New("INVALID_CURRENCY")
+	ErrCurrencyMismatch     = errors.New("CURRENCY_MISMATCH")
+	ErrExchangeRateNotFound = errors.New("EXCHANGE_RATE_NOT_FOUND")
+)
+
+// DefaultCurrency is used for accounts and users created without a currency.
+const DefaultCurrency = "THB"
+
+// NormalizeCurrency upper-cases an ISO 4217 code, defaulting to DefaultCurrency
+// when empty.
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+
+	if len(code) != 3 {
+		return "", errors.Wrapf(ErrInvalidCurrency, "currency %q", code)
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return "", errors.Wrapf(ErrInvalidCurrency, "currency %q", code)
+		}
+	}
+
+	return code, nil
+}
+
+// ExchangeRate is the price of one unit of From in To on a date.
+type ExchangeRate struct {
+	From string
+	To   string
+	Date time.Time
+	Rate decimal.Decimal
+}
+
+// Money is an amount in its native currency, with its value in the user's
+// base currency when an exchange rate is known.
+type Money struct {
+	Currency   string
+	Amount     decimal.Decimal
+	BaseAmount *decimal.Decimal
+}
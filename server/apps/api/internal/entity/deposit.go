@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"math"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotFixedDepositAccount = errors.New("NOT_FIXED_DEPOSIT_ACCOUNT")
+	ErrFixedDepositNotFound   = errors.New("FIXED_DEPOSIT_NOT_FOUND")
+	ErrInvalidCompounding     = errors.New("INVALID_COMPOUNDING")
+	ErrInvalidMaturityAction  = errors.New("INVALID_MATURITY_ACTION")
+	ErrInvalidSavingsPocket   = errors.New("INVALID_SAVINGS_POCKET")
+	ErrStartDateTooEarly      = errors.New("START_DATE_TOO_EARLY")
+)
+
+// DaysPerYear is the day count basis of interest, actual/365.
+const DaysPerYear = 365
+
+type Compounding string
+
+const (
+	// CompoundingNone pays simple interest on the principal at maturity.
+	CompoundingNone      Compounding = "NONE"
+	CompoundingMonthly   Compounding = "MONTHLY"
+	CompoundingQuarterly Compounding = "QUARTERLY"
+	CompoundingAnnually  Compounding = "ANNUALLY"
+)
+
+// Months is the length of an interest period, 0 for CompoundingNone.
+func (c Compounding) Months() int {
+	switch c {
+	case CompoundingMonthly:
+		return 1
+	case CompoundingQuarterly:
+		return 3
+	case CompoundingAnnually:
+		return 12
+	}
+
+	return 0
+}
+
+func (c Compounding) IsValid() bool {
+	return c == CompoundingNone || c.Months() > 0
+}
+
+type MaturityAction string
+
+const (
+	MaturityActionNone          MaturityAction = "NONE"
+	MaturityActionRenew         MaturityAction = "RENEW"
+	MaturityActionMoveToSavings MaturityAction = "MOVE_TO_SAVINGS"
+)
+
+func (a MaturityAction) IsValid() bool {
+	return a == MaturityActionNone || a == MaturityActionRenew || a == MaturityActionMoveToSavings
+}
+
+type FixedDepositStatus string
+
+const (
+	FixedDepositStatusActive  FixedDepositStatus = "ACTIVE"
+	FixedDepositStatusMatured FixedDepositStatus = "MATURED"
+)
+
+// FixedDeposit is the term of a FIXED_DEPOSIT account. Rates are percentages.
+type FixedDeposit struct {
+	ID                 uuid.UUID
+	AccountID          uuid.UUID
+	Principal          decimal.Decimal
+	AnnualRate         decimal.Decimal
+	TermMonths         int
+	StartDate          time.Time
+	Compounding        Compounding
+	WithholdingTaxRate decimal.Decimal
+	MaturityAction     MaturityAction
+	SavingsPocketID    *uuid.UUID // Destination of MaturityActionMoveToSavings
+	Status             FixedDepositStatus
+	AccruedUntil       time.Time // Date of the last posted interest, StartDate before any
+	Renewals           int
+	MaturedAt          *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (fd FixedDeposit) MaturityDate() time.Time {
+	return fd.StartDate.AddDate(0, fd.TermMonths, 0)
+}
+
+// Schedule returns the interest postings of the term. Interest of a period is
+// principal × rate × days / 365, and with compounding the net interest of each
+// period is added to the principal of the next.
+func (fd FixedDeposit) Schedule() []InterestPosting {
+	maturity := fd.MaturityDate()
+	months := fd.Compounding.Months()
+	if months == 0 {
+		months = fd.TermMonths
+	}
+
+	var postings []InterestPosting
+	balance := fd.Principal
+	for k := 1; ; k++ {
+		start := fd.StartDate.AddDate(0, (k-1)*months, 0)
+		if !start.Before(maturity) {
+			break
+		}
+
+		end := fd.StartDate.AddDate(0, k*months, 0)
+		if end.After(maturity) {
+			end = maturity
+		}
+
+		days := int64(math.Round(end.Sub(start).Hours() / 24))
+		gross := balance.Mul(fd.AnnualRate).Mul(decimal.NewFromInt(days)).Div(decimal.NewFromInt(100 * DaysPerYear)).Round(2)
+		tax := gross.Mul(fd.WithholdingTaxRate).Div(decimal.NewFromInt(100)).Round(2)
+		net := gross.Sub(tax)
+
+		if fd.Compounding != CompoundingNone {
+			balance = balance.Add(net)
+		}
+
+		postings = append(postings, InterestPosting{
+			PeriodStart: start,
+			Date:        end,
+			Principal:   balance,
+			Gross:       gross,
+			Tax:         tax,
+			Net:         net,
+		})
+	}
+
+	return postings
+}
+
+// MaturityValue is the principal plus the net interest of the term.
+func (fd FixedDeposit) MaturityValue() decimal.Decimal {
+	value := fd.Principal
+	for _, p := range fd.Schedule() {
+		value = value.Add(p.Net)
+	}
+
+	return value
+}
+
+type FixedDepositInput struct {
+	UserID             uuid.UUID
+	AccountID          uuid.UUID
+	Principal          *decimal.Decimal // Defaults to the account balance
+	AnnualRate         decimal.Decimal
+	TermMonths         int
+	StartDate          time.Time
+	Compounding        Compounding
+	WithholdingTaxRate decimal.Decimal
+	MaturityAction     MaturityAction
+	SavingsPocketID    *uuid.UUID
+}
+
+// InterestPosting is the interest of one period, posted on Date. Principal is
+// the balance earning interest after the posting.
+type InterestPosting struct {
+	PeriodStart time.Time
+	Date        time.Time
+	Principal   decimal.Decimal
+	Gross       decimal.Decimal
+	Tax         decimal.Decimal
+	Net         decimal.Decimal
+}
@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFixedDepositSchedule(t *testing.T) {
+	type posting struct {
+		start, end            time.Time
+		principal, gross, tax string
+	}
+
+	tests := []struct {
+		name          string
+		fd            FixedDeposit
+		want          []posting
+		maturityValue string
+	}{
+		{
+			name: "simple interest is paid once at maturity",
+			fd: FixedDeposit{
+				Principal:          decimal.NewFromInt(100000),
+				AnnualRate:         decimal.NewFromInt(2),
+				TermMonths:         12,
+				StartDate:          date(2024, 1, 1),
+				Compounding:        CompoundingNone,
+				WithholdingTaxRate: decimal.NewFromInt(15),
+			},
+			want: []posting{
+				{date(2024, 1, 1), date(2025, 1, 1), "100000", "2005.48", "300.82"},
+			},
+			maturityValue: "101704.66",
+		},
+		{
+			name: "quarterly compounding adds the interest to the principal",
+			fd: FixedDeposit{
+				Principal:   decimal.NewFromInt(100000),
+				AnnualRate:  decimal.RequireFromString("2.4"),
+				TermMonths:  6,
+				StartDate:   date(2024, 1, 1),
+				Compounding: CompoundingQuarterly,
+			},
+			want: []posting{
+				{date(2024, 1, 1), date(2024, 4, 1), "100598.36", "598.36", "0"},
+				{date(2024, 4, 1), date(2024, 7, 1), "101200.3", "601.94", "0"},
+			},
+			maturityValue: "101200.3",
+		},
+		{
+			name: "last period is cut short at maturity",
+			fd: FixedDeposit{
+				Principal:          decimal.NewFromInt(50000),
+				AnnualRate:         decimal.RequireFromString("1.5"),
+				TermMonths:         4,
+				StartDate:          date(2024, 1, 15),
+				Compounding:        CompoundingQuarterly,
+				WithholdingTaxRate: decimal.NewFromInt(15),
+			},
+			want: []posting{
+				{date(2024, 1, 15), date(2024, 4, 15), "50158.94", "186.99", "28.05"},
+				{date(2024, 4, 15), date(2024, 5, 15), "50211.5", "61.84", "9.28"},
+			},
+			maturityValue: "50211.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fd.Schedule()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d postings, want %d", len(got), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				p := got[i]
+				if !p.PeriodStart.Equal(want.start) || !p.Date.Equal(want.end) {
+					t.Errorf("posting %d period = %s to %s, want %s to %s", i, p.PeriodStart, p.Date, want.start, want.end)
+				}
+				if !p.Principal.Equal(decimal.RequireFromString(want.principal)) {
+					t.Errorf("posting %d principal = %s, want %s", i, p.Principal, want.principal)
+				}
+				if !p.Gross.Equal(decimal.RequireFromString(want.gross)) || !p.Tax.Equal(decimal.RequireFromString(want.tax)) {
+					t.Errorf("posting %d gross, tax = %s, %s, want %s, %s", i, p.Gross, p.Tax, want.gross, want.tax)
+				}
+				if !p.Net.Equal(p.Gross.Sub(p.Tax)) {
+					t.Errorf("posting %d net = %s, want gross - tax", i, p.Net)
+				}
+			}
+
+			if got := tt.fd.MaturityValue(); !got.Equal(decimal.RequireFromString(tt.maturityValue)) {
+				t.Errorf("MaturityValue() = %s, want %s", got, tt.maturityValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotMutualFundAccount = errors.New("NOT_MUTUAL_FUND_ACCOUNT")
+	ErrInsufficientUnits    = errors.New("INSUFFICIENT_UNITS")
+	ErrInvalidFundOrderSide = errors.New("INVALID_FUND_ORDER_SIDE")
+	ErrFundOrderTooSmall    = errors.New("FUND_ORDER_TOO_SMALL")
+	ErrInvalidNAV           = errors.New("INVALID_NAV")
+)
+
+// FundUnitPlaces is the number of decimal places units are rounded to.
+const FundUnitPlaces = 4
+
+type FundOrderSide string
+
+const (
+	FundOrderSideSubscribe FundOrderSide = "SUBSCRIBE"
+	FundOrderSideRedeem    FundOrderSide = "REDEEM"
+)
+
+func (s FundOrderSide) String() string {
+	return string(s)
+}
+
+// FundOrder is a subscription or redemption of units at the NAV of NAVDate.
+// Its cash side is recorded as the transaction referenced by TransactionID.
+type FundOrder struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	FundCode      string
+	Side          FundOrderSide
+	Units         decimal.Decimal
+	NAV           decimal.Decimal
+	NAVDate       time.Time
+	Amount        decimal.Decimal
+	CreatedAt     time.Time
+}
+
+type FundOrderInput struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	FundCode  string
+	Side      FundOrderSide
+	Amount    decimal.Decimal // Baht to invest, used by subscriptions
+	Units     decimal.Decimal // Units to sell, used by redemptions
+	NAV       *decimal.Decimal
+	NAVDate   time.Time
+	Note      string
+}
+
+type FundPosition struct {
+	FundCode     string
+	Units        decimal.Decimal
+	Invested     decimal.Decimal // Average cost of the units still held
+	NAV          decimal.Decimal
+	NAVDate      time.Time
+	MarketValue  decimal.Decimal
+	Gain         decimal.Decimal
+	RealizedGain decimal.Decimal
+}
+
+// FundSummary is a MUTUAL_FUND account's uninvested cash and its fund positions.
+type FundSummary struct {
+	Currency  string
+	Cash      decimal.Decimal
+	Positions []FundPosition
+}
@@ -0,0 +1,107 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotStockAccount         = errors.New("NOT_STOCK_ACCOUNT")
+	ErrInsufficientQuantity    = errors.New("INSUFFICIENT_QUANTITY")
+	ErrInvalidCostBasisMethod  = errors.New("INVALID_COST_BASIS_METHOD")
+	ErrInvalidTradeSide        = errors.New("INVALID_TRADE_SIDE")
+	ErrFeeExceedsTradeProceeds = errors.New("FEE_EXCEEDS_TRADE_PROCEEDS")
+)
+
+type TradeSide string
+
+const (
+	TradeSideBuy  TradeSide = "BUY"
+	TradeSideSell TradeSide = "SELL"
+)
+
+func (ts TradeSide) String() string {
+	return string(ts)
+}
+
+type CostBasisMethod string
+
+const (
+	CostBasisFIFO    CostBasisMethod = "FIFO"
+	CostBasisAverage CostBasisMethod = "AVERAGE"
+)
+
+func (m CostBasisMethod) String() string {
+	return string(m)
+}
+
+// Trade is a buy or sell of a symbol in a STOCK account. Its cash side is
+// recorded as the transaction referenced by TransactionID.
+type Trade struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID
+	TransactionID uuid.UUID
+	Symbol        string
+	Side          TradeSide
+	Quantity      decimal.Decimal
+	Price         decimal.Decimal
+	Fee           decimal.Decimal
+	CreatedAt     time.Time
+}
+
+// Gross is quantity times price, before fees.
+func (t Trade) Gross() decimal.Decimal {
+	return t.Quantity.Mul(t.Price)
+}
+
+// CashAmount is the cash leaving the cashbox on a buy or entering it on a sell.
+func (t Trade) CashAmount() decimal.Decimal {
+	if t.Side == TradeSideBuy {
+		return t.Gross().Add(t.Fee)
+	}
+
+	return t.Gross().Sub(t.Fee)
+}
+
+type TradeInput struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Symbol    string
+	Side      TradeSide
+	Quantity  decimal.Decimal
+	Price     decimal.Decimal
+	Fee       decimal.Decimal
+	Note      string
+}
+
+// Lot is the quantity bought by one trade. Sells consume open lots oldest first.
+type Lot struct {
+	ID                uuid.UUID
+	AccountID         uuid.UUID
+	TradeID           uuid.UUID
+	Symbol            string
+	Quantity          decimal.Decimal
+	RemainingQuantity decimal.Decimal
+	CostPerUnit       decimal.Decimal // Includes the buy fee
+	CreatedAt         time.Time
+}
+
+// Holdings are the positions of a STOCK account, priced in its currency.
+type Holdings struct {
+	Currency  string
+	Positions []Position
+}
+
+type Position struct {
+	Symbol        string
+	Quantity      decimal.Decimal
+	CostBasis     decimal.Decimal
+	AverageCost   decimal.Decimal
+	MarketPrice   decimal.Decimal
+	MarketValue   decimal.Decimal
+	RealizedPnL   decimal.Decimal
+	UnrealizedPnL decimal.Decimal
+}
@@ -0,0 +1,8 @@
+package entity
+
+type IdempotencyRecord struct {
+	Fingerprint string // Hash of the request the key was first used with
+	Completed   bool   // False while the first request is still in flight
+	StatusCode  int
+	Body        []byte
+}
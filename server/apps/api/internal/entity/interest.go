@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotSavingAccount       = errors.New("NOT_SAVING_ACCOUNT")
+	ErrInterestRuleNotFound   = errors.New("INTEREST_RULE_NOT_FOUND")
+	ErrInvalidInterestTiers   = errors.New("INVALID_INTEREST_TIERS")
+	ErrInvalidPayoutFrequency = errors.New("INVALID_PAYOUT_FREQUENCY")
+)
+
+type PayoutFrequency string
+
+const (
+	PayoutFrequencyMonthly PayoutFrequency = "MONTHLY"
+	// PayoutFrequencySemiAnnual pays at the end of June and December.
+	PayoutFrequencySemiAnnual PayoutFrequency = "SEMI_ANNUAL"
+)
+
+func (f PayoutFrequency) Months() int {
+	switch f {
+	case PayoutFrequencyMonthly:
+		return 1
+	case PayoutFrequencySemiAnnual:
+		return 6
+	}
+
+	return 0
+}
+
+func (f PayoutFrequency) IsValid() bool {
+	return f.Months() > 0
+}
+
+// NextPayout returns the first payout date after t. Interest is paid at the
+// start of the day following the period, so a payout on 1 July covers
+// January to June.
+func (f PayoutFrequency) NextPayout(t time.Time) time.Time {
+	months := f.Months()
+	y, m, _ := t.Date()
+	m = time.Month((int(m)-1)/months*months + 1)
+
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC).AddDate(0, months, 0)
+}
+
+// InterestTier is the annual rate, in percent, earned on the part of the
+// balance above MinBalance and below the next tier.
+type InterestTier struct {
+	MinBalance decimal.Decimal
+	Rate       decimal.Decimal
+}
+
+// InterestRule is how a SAVING account earns interest. Interest accrues daily
+// on the closing balance and is paid every Payout into the cashbox pocket.
+type InterestRule struct {
+	ID                 uuid.UUID
+	AccountID          uuid.UUID
+	Tiers              []InterestTier // Ordered by MinBalance, the first starts at 0
+	Payout             PayoutFrequency
+	WithholdingTaxRate decimal.Decimal
+	PaidUntil          time.Time // Interest before this date has been posted
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// DailyInterest is the unrounded interest earned by balance over one day.
+func (r InterestRule) DailyInterest(balance decimal.Decimal) decimal.Decimal {
+	interest := decimal.Zero
+	for i, tier := range r.Tiers {
+		if !balance.GreaterThan(tier.MinBalance) {
+			break
+		}
+
+		portion := balance.Sub(tier.MinBalance)
+		if i+1 < len(r.Tiers) {
+			portion = decimal.Min(portion, r.Tiers[i+1].MinBalance.Sub(tier.MinBalance))
+		}
+
+		interest = interest.Add(portion.Mul(tier.Rate))
+	}
+
+	return interest.Div(decimal.NewFromInt(100 * DaysPerYear))
+}
+
+// ValidateTiers checks that tiers start at 0, ascend and have no negative rate.
+func ValidateTiers(tiers []InterestTier) error {
+	if len(tiers) == 0 || !tiers[0].MinBalance.IsZero() {
+		return errors.WithStack(ErrInvalidInterestTiers)
+	}
+
+	for i, tier := range tiers {
+		if tier.Rate.IsNegative() {
+			return errors.WithStack(ErrInvalidInterestTiers)
+		}
+
+		if i > 0 && !tier.MinBalance.GreaterThan(tiers[i-1].MinBalance) {
+			return errors.WithStack(ErrInvalidInterestTiers)
+		}
+	}
+
+	return nil
+}
+
+type InterestRuleInput struct {
+	UserID             uuid.UUID
+	AccountID          uuid.UUID
+	Tiers              []InterestTier
+	Payout             PayoutFrequency
+	WithholdingTaxRate decimal.Decimal
+	StartDate          time.Time // Interest accrues from this date
+}
+
+// InterestAccrual is the interest earned from PeriodStart until the day
+// before PeriodEnd.
+type InterestAccrual struct {
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Days        int
+	Gross       decimal.Decimal
+	Tax         decimal.Decimal
+	Net         decimal.Decimal
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/shopspring/decimal"
+)
+
+func TestDailyInterest(t *testing.T) {
+	rule := InterestRule{
+		Tiers: []InterestTier{
+			{MinBalance: decimal.Zero, Rate: decimal.RequireFromString("0.5")},
+			{MinBalance: decimal.NewFromInt(100000), Rate: decimal.NewFromInt(1)},
+			{MinBalance: decimal.NewFromInt(1000000), Rate: decimal.NewFromInt(2)},
+		},
+	}
+
+	// yearly is the interest over a year, balance × rate summed over the tiers
+	tests := []struct {
+		name    string
+		balance int64
+		yearly  int64
+	}{
+		{name: "empty", balance: 0, yearly: 0},
+		{name: "overdrawn", balance: -500, yearly: 0},
+		{name: "first tier", balance: 50000, yearly: 25000},
+		{name: "tier boundary", balance: 100000, yearly: 50000},
+		{name: "second tier", balance: 150000, yearly: 100000*0.5 + 50000*1},
+		{name: "every tier", balance: 2000000, yearly: 100000*0.5 + 900000*1 + 1000000*2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := decimal.NewFromInt(tt.yearly).Div(decimal.NewFromInt(100 * DaysPerYear))
+			if got := rule.DailyInterest(decimal.NewFromInt(tt.balance)); !got.Equal(want) {
+				t.Errorf("DailyInterest(%d) = %s, want %s", tt.balance, got, want)
+			}
+		})
+	}
+
+	if got := (InterestRule{}).DailyInterest(decimal.NewFromInt(1000)); !got.IsZero() {
+		t.Errorf("DailyInterest without tiers = %s, want 0", got)
+	}
+}
+
+func TestValidateTiers(t *testing.T) {
+	tier := func(minBalance, rate int64) InterestTier {
+		return InterestTier{MinBalance: decimal.NewFromInt(minBalance), Rate: decimal.NewFromInt(rate)}
+	}
+
+	tests := []struct {
+		name  string
+		tiers []InterestTier
+		valid bool
+	}{
+		{name: "single", tiers: []InterestTier{tier(0, 1)}, valid: true},
+		{name: "ascending", tiers: []InterestTier{tier(0, 1), tier(1000, 2)}, valid: true},
+		{name: "none", tiers: nil},
+		{name: "not from zero", tiers: []InterestTier{tier(100, 1)}},
+		{name: "repeated minimum", tiers: []InterestTier{tier(0, 1), tier(0, 2)}},
+		{name: "descending", tiers: []InterestTier{tier(0, 1), tier(1000, 2), tier(500, 3)}},
+		{name: "negative rate", tiers: []InterestTier{tier(0, -1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTiers(tt.tiers)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidInterestTiers) {
+				t.Errorf("err = %v, want %v", err, ErrInvalidInterestTiers)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotCreditCardAccount      = errors.New("NOT_CREDIT_CARD_ACCOUNT")
+	ErrNotLoanAccount            = errors.New("NOT_LOAN_ACCOUNT")
+	ErrNotLiabilityAccount       = errors.New("NOT_LIABILITY_ACCOUNT")
+	ErrCreditCardNotFound        = errors.New("CREDIT_CARD_NOT_FOUND")
+	ErrLoanNotFound              = errors.New("LOAN_NOT_FOUND")
+	ErrInvalidPaymentPocket      = errors.New("INVALID_PAYMENT_POCKET")
+	ErrPaymentExceedsOutstanding = errors.New("PAYMENT_EXCEEDS_OUTSTANDING")
+)
+
+// CreditCard is the billing terms of a CREDIT_CARD account. The statement
+// closes on StatementDay every month and is due DueDays later.
+type CreditCard struct {
+	ID               uuid.UUID
+	AccountID        uuid.UUID
+	CreditLimit      decimal.Decimal
+	StatementDay     int             // 1 to 28
+	DueDays          int             // Days from the statement to the due date
+	MinPaymentRate   decimal.Decimal // Percent of the statement balance
+	MinPaymentAmount decimal.Decimal // Floor of the minimum payment
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// LastStatementDate returns the latest statement closing day on or before now.
+func (c CreditCard) LastStatementDate(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	statement := time.Date(y, m, c.StatementDay, 0, 0, 0, 0, time.UTC)
+	if d < c.StatementDay {
+		statement = statement.AddDate(0, -1, 0)
+	}
+
+	return statement
+}
+
+// MinimumPayment is MinPaymentRate of the statement balance, at least
+// MinPaymentAmount and at most the statement balance.
+func (c CreditCard) MinimumPayment(statementBalance decimal.Decimal) decimal.Decimal {
+	if !statementBalance.IsPositive() {
+		return decimal.Zero
+	}
+
+	minimum := statementBalance.Mul(c.MinPaymentRate).Div(decimal.NewFromInt(100)).Round(2)
+	minimum = decimal.Max(minimum, c.MinPaymentAmount)
+
+	return decimal.Min(minimum, statementBalance)
+}
+
+type CreditCardInput struct {
+	UserID           uuid.UUID
+	AccountID        uuid.UUID
+	CreditLimit      decimal.Decimal
+	StatementDay     int
+	DueDays          int
+	MinPaymentRate   decimal.Decimal
+	MinPaymentAmount decimal.Decimal
+}
+
+// CreditCardStatement is the latest statement of a card and what is left to
+// pay on it. Amounts owed are positive.
+type CreditCardStatement struct {
+	StatementDate      time.Time
+	DueDate            time.Time
+	StatementBalance   decimal.Decimal
+	PaidSinceStatement decimal.Decimal
+	MinimumPayment     decimal.Decimal // Still due, after payments since the statement
+	Outstanding        decimal.Decimal
+	AvailableCredit    decimal.Decimal
+}
+
+// Loan is the terms of a LOAN account, repaid in equal monthly installments.
+// AnnualRate is a percentage.
+type Loan struct {
+	ID           uuid.UUID
+	AccountID    uuid.UUID
+	Principal    decimal.Decimal
+	AnnualRate   decimal.Decimal
+	TermMonths   int
+	StartDate    time.Time
+	AccruedUntil time.Time // Interest before this date has been charged
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (l Loan) monthlyRate() decimal.Decimal {
+	return l.AnnualRate.Div(decimal.NewFromInt(1200))
+}
+
+// MonthlyPayment is the installment that repays the principal with interest
+// over the term.
+func (l Loan) MonthlyPayment() decimal.Decimal {
+	if l.TermMonths <= 0 {
+		return decimal.Zero
+	}
+	n := decimal.NewFromInt(int64(l.TermMonths))
+
+	r := l.monthlyRate()
+	if r.IsZero() {
+		return l.Principal.Div(n).RoundUp(2)
+	}
+
+	// P × r × (1+r)^n / ((1+r)^n − 1)
+	f := decimal.NewFromInt(1).Add(r).Pow(n)
+
+	return l.Principal.Mul(r).Mul(f).Div(f.Sub(decimal.NewFromInt(1))).RoundUp(2)
+}
+
+// Schedule is the amortization of the loan, one row per installment. The last
+// installment repays whatever principal is left.
+func (l Loan) Schedule() []AmortizationRow {
+	payment := l.MonthlyPayment()
+	r := l.monthlyRate()
+
+	rows := make([]AmortizationRow, 0, l.TermMonths)
+	balance := l.Principal
+	for k := 1; k <= l.TermMonths && balance.IsPositive(); k++ {
+		interest := balance.Mul(r).Round(2)
+		principal := payment.Sub(interest)
+		if k == l.TermMonths || principal.GreaterThan(balance) {
+			principal = balance
+		}
+		balance = balance.Sub(principal)
+
+		rows = append(rows, AmortizationRow{
+			Number:    k,
+			Date:      l.StartDate.AddDate(0, k, 0),
+			Payment:   principal.Add(interest),
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	return rows
+}
+
+// AccruedInterest is the interest on outstanding from AccruedUntil to now,
+// actual/365.
+func (l Loan) AccruedInterest(outstanding decimal.Decimal, now time.Time) decimal.Decimal {
+	if !outstanding.IsPositive() || !now.After(l.AccruedUntil) {
+		return decimal.Zero
+	}
+
+	days := int64(now.Sub(l.AccruedUntil).Hours() / 24)
+
+	return outstanding.Mul(l.AnnualRate).Mul(decimal.NewFromInt(days)).Div(decimal.NewFromInt(100 * DaysPerYear)).Round(2)
+}
+
+type LoanInput struct {
+	UserID     uuid.UUID
+	AccountID  uuid.UUID
+	Principal  decimal.Decimal
+	AnnualRate decimal.Decimal
+	TermMonths int
+	StartDate  time.Time
+}
+
+type AmortizationRow struct {
+	Number    int
+	Date      time.Time
+	Payment   decimal.Decimal
+	Principal decimal.Decimal
+	Interest  decimal.Decimal
+	Balance   decimal.Decimal // Principal left after the installment
+}
+
+type LiabilityPaymentInput struct {
+	UserID       uuid.UUID
+	AccountID    uuid.UUID
+	FromPocketID uuid.UUID
+	Amount       decimal.Decimal
+	Note         string
+}
+
+// LiabilityPayment is how a payment was split. Interest is charged to the
+// liability first and the rest of the payment repays principal.
+type LiabilityPayment struct {
+	TransactionID uuid.UUID
+	Amount        decimal.Decimal
+	Principal     decimal.Decimal
+	Interest      decimal.Decimal
+	Outstanding   decimal.Decimal // Owed after the payment
+}
@@ -0,0 +1,128 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanMonthlyPayment(t *testing.T) {
+	tests := []struct {
+		name string
+		loan Loan
+		want string
+	}{
+		{
+			name: "zero rate splits the principal, rounded up",
+			loan: Loan{Principal: decimal.NewFromInt(1000), TermMonths: 3},
+			want: "333.34",
+		},
+		{
+			name: "annuity",
+			loan: Loan{Principal: decimal.NewFromInt(1000), AnnualRate: decimal.NewFromInt(12), TermMonths: 3},
+			want: "340.03",
+		},
+		{
+			name: "annuity over a year",
+			loan: Loan{Principal: decimal.NewFromInt(100000), AnnualRate: decimal.NewFromInt(6), TermMonths: 12},
+			want: "8606.65",
+		},
+		{
+			name: "no term",
+			loan: Loan{Principal: decimal.NewFromInt(1000), AnnualRate: decimal.NewFromInt(12)},
+			want: "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loan.MonthlyPayment(); !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("MonthlyPayment() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanSchedule(t *testing.T) {
+	type row struct {
+		payment, principal, interest, balance string
+	}
+
+	tests := []struct {
+		name string
+		loan Loan
+		want []row
+	}{
+		{
+			name: "zero rate, last installment takes the rounding",
+			loan: Loan{Principal: decimal.NewFromInt(1000), TermMonths: 3, StartDate: date(2024, 1, 1)},
+			want: []row{
+				{"333.34", "333.34", "0", "666.66"},
+				{"333.34", "333.34", "0", "333.32"},
+				{"333.32", "333.32", "0", "0"},
+			},
+		},
+		{
+			name: "interest is charged on the remaining balance",
+			loan: Loan{Principal: decimal.NewFromInt(1000), AnnualRate: decimal.NewFromInt(12), TermMonths: 3, StartDate: date(2024, 1, 1)},
+			want: []row{
+				{"340.03", "330.03", "10", "669.97"},
+				{"340.03", "333.33", "6.7", "336.64"},
+				{"340.01", "336.64", "3.37", "0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.loan.Schedule()
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d", len(got), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				r := got[i]
+				if r.Number != i+1 || !r.Date.Equal(tt.loan.StartDate.AddDate(0, i+1, 0)) {
+					t.Errorf("row %d is installment %d on %s", i, r.Number, r.Date)
+				}
+
+				for _, field := range []struct {
+					name string
+					got  decimal.Decimal
+					want string
+				}{
+					{"payment", r.Payment, want.payment},
+					{"principal", r.Principal, want.principal},
+					{"interest", r.Interest, want.interest},
+					{"balance", r.Balance, want.balance},
+				} {
+					if !field.got.Equal(decimal.RequireFromString(field.want)) {
+						t.Errorf("row %d %s = %s, want %s", i, field.name, field.got, field.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLoanScheduleRepaysPrincipal(t *testing.T) {
+	loan := Loan{Principal: decimal.NewFromInt(100000), AnnualRate: decimal.NewFromInt(6), TermMonths: 12, StartDate: date(2024, 1, 31)}
+
+	rows := loan.Schedule()
+	if len(rows) != loan.TermMonths {
+		t.Fatalf("got %d rows, want %d", len(rows), loan.TermMonths)
+	}
+
+	repaid := decimal.Zero
+	for _, r := range rows[:len(rows)-1] {
+		if !r.Payment.Equal(loan.MonthlyPayment()) {
+			t.Errorf("installment %d payment = %s, want %s", r.Number, r.Payment, loan.MonthlyPayment())
+		}
+		repaid = repaid.Add(r.Principal)
+	}
+	repaid = repaid.Add(rows[len(rows)-1].Principal)
+
+	if !repaid.Equal(loan.Principal) || !rows[len(rows)-1].Balance.IsZero() {
+		t.Errorf("repaid %s of %s, %s left", repaid, loan.Principal, rows[len(rows)-1].Balance)
+	}
+}
@@ -3,10 +3,13 @@ package entity
 import (
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+var ErrCashboxNotFound = errors.New("CASHBOX_NOT_FOUND")
+
 type PocketType string
 
 const (
@@ -19,7 +22,14 @@ type Pocket struct {
 	AccountID uuid.UUID
 	Name      string
 	Type      PocketType
+	Currency  string
 	Balance   decimal.Decimal
+	SortOrder int
+	Color     string
+	Icon      string
+	Archived  bool          // Hidden from listings, still in totals and history
+	Goal      *PocketGoal   // Nil when the pocket has no savings goal
+	Progress  *GoalProgress // Filled in by the pocket usecase when Goal is set
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -33,5 +43,75 @@ type PocketInput struct {
 	AccountID uuid.UUID
 	Name      string
 	Type      PocketType
+	Currency  string
 	Balance   decimal.Decimal
+	Color     *string // Nil leaves the color unchanged
+	Icon      *string // Nil leaves the icon unchanged
+	Archived  *bool   // Nil leaves the flag unchanged
+}
+
+// PocketGoal turns a NORMAL pocket into a savings envelope.
+type PocketGoal struct {
+	TargetAmount        decimal.Decimal
+	TargetDate          *time.Time      // Nil for goals without a deadline
+	MonthlyContribution decimal.Decimal // Planned saving per month, zero without a plan
+}
+
+type PocketGoalInput struct {
+	UserID   uuid.UUID
+	PocketID uuid.UUID
+	Goal     *PocketGoal // Nil removes the goal
+}
+
+// GoalProgress is how far a pocket is from its goal.
+type GoalProgress struct {
+	Percent       decimal.Decimal  // Of the target amount, above 100 once exceeded
+	Remaining     decimal.Decimal  // Zero once the goal is reached
+	MonthlySaving decimal.Decimal  // Average net inflow per month over the recent history
+	MonthlyNeeded *decimal.Decimal // To reach the target by its date, nil without one
+	ProjectedDate *time.Time       // Nil when nothing is being saved towards the goal
+}
+
+// averageMonthDays is the length of an average month in days.
+var averageMonthDays = decimal.NewFromInt(365).Div(decimal.NewFromInt(12))
+
+// Progress measures the goal on now for a pocket holding balance that saved
+// monthlySaving a month recently. The projection falls back to the planned
+// contribution when the pocket has not been growing.
+func (g PocketGoal) Progress(balance, monthlySaving decimal.Decimal, now time.Time) GoalProgress {
+	progress := GoalProgress{
+		Remaining:     decimal.Max(g.TargetAmount.Sub(balance), decimal.Zero),
+		MonthlySaving: monthlySaving,
+	}
+	if g.TargetAmount.IsPositive() {
+		progress.Percent = balance.Mul(decimal.NewFromInt(100)).Div(g.TargetAmount).Round(2)
+	}
+
+	if g.TargetDate != nil {
+		// The whole remainder is due this month once the deadline is near or past
+		months := (g.TargetDate.Year()-now.Year())*12 + int(g.TargetDate.Month()-now.Month())
+		if g.TargetDate.Day() < now.Day() {
+			months--
+		}
+		months = max(months, 1)
+
+		needed := progress.Remaining.Div(decimal.NewFromInt(int64(months))).RoundUp(2)
+		progress.MonthlyNeeded = &needed
+	}
+
+	pace := monthlySaving
+	if !pace.IsPositive() {
+		pace = g.MonthlyContribution
+	}
+
+	switch {
+	case progress.Remaining.IsZero():
+		progress.ProjectedDate = &now
+	case pace.IsPositive():
+		days := progress.Remaining.Div(pace).Mul(averageMonthDays).Ceil().IntPart()
+		projected := now.AddDate(0, 0, int(days))
+		progress.ProjectedDate = &projected
+	}
+
+	return progress
 }
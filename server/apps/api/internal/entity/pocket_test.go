@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPocketGoalProgress(t *testing.T) {
+	now := date(2024, 3, 15)
+	deadline := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name          string
+		goal          PocketGoal
+		balance       int64
+		monthlySaving int64
+		percent       string
+		remaining     string
+		monthlyNeeded string // Empty when nil
+		projected     time.Time
+	}{
+		{
+			name:          "projected from the recent saving",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(10000)},
+			balance:       2500,
+			monthlySaving: 500,
+			percent:       "25",
+			remaining:     "7500",
+			projected:     date(2025, 6, 15),
+		},
+		{
+			name:          "reached goal is projected today",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(10000)},
+			balance:       12000,
+			monthlySaving: 500,
+			percent:       "120",
+			remaining:     "0",
+			projected:     now,
+		},
+		{
+			name:          "falls back to the planned contribution",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(4000), MonthlyContribution: decimal.NewFromInt(1000)},
+			balance:       1000,
+			monthlySaving: -200,
+			percent:       "25",
+			remaining:     "3000",
+			projected:     date(2024, 6, 15),
+		},
+		{
+			name:          "no projection without saving",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(10000), TargetDate: deadline(date(2024, 12, 31))},
+			balance:       1000,
+			percent:       "10",
+			remaining:     "9000",
+			monthlyNeeded: "1000",
+		},
+		{
+			name:          "deadline day before today leaves one month less",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(1000), TargetDate: deadline(date(2024, 6, 10))},
+			percent:       "0",
+			remaining:     "1000",
+			monthlyNeeded: "500",
+		},
+		{
+			name:          "past deadline needs everything this month",
+			goal:          PocketGoal{TargetAmount: decimal.NewFromInt(1000), TargetDate: deadline(date(2024, 1, 1))},
+			balance:       400,
+			percent:       "40",
+			remaining:     "600",
+			monthlyNeeded: "600",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.goal.Progress(decimal.NewFromInt(tt.balance), decimal.NewFromInt(tt.monthlySaving), now)
+
+			if !got.Percent.Equal(decimal.RequireFromString(tt.percent)) {
+				t.Errorf("Percent = %s, want %s", got.Percent, tt.percent)
+			}
+			if !got.Remaining.Equal(decimal.RequireFromString(tt.remaining)) {
+				t.Errorf("Remaining = %s, want %s", got.Remaining, tt.remaining)
+			}
+			if !got.MonthlySaving.Equal(decimal.NewFromInt(tt.monthlySaving)) {
+				t.Errorf("MonthlySaving = %s, want %d", got.MonthlySaving, tt.monthlySaving)
+			}
+
+			switch {
+			case tt.monthlyNeeded == "" && got.MonthlyNeeded != nil:
+				t.Errorf("MonthlyNeeded = %s, want nil", got.MonthlyNeeded)
+			case tt.monthlyNeeded != "" && (got.MonthlyNeeded == nil || !got.MonthlyNeeded.Equal(decimal.RequireFromString(tt.monthlyNeeded))):
+				t.Errorf("MonthlyNeeded = %v, want %s", got.MonthlyNeeded, tt.monthlyNeeded)
+			}
+
+			switch {
+			case tt.projected.IsZero() && got.ProjectedDate != nil:
+				t.Errorf("ProjectedDate = %s, want nil", got.ProjectedDate)
+			case !tt.projected.IsZero() && (got.ProjectedDate == nil || !got.ProjectedDate.Equal(tt.projected)):
+				t.Errorf("ProjectedDate = %v, want %s", got.ProjectedDate, tt.projected)
+			}
+		})
+	}
+}
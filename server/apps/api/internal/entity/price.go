@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPriceNotFound = errors.New("PRICE_NOT_FOUND")
+)
+
+type PriceSource string
+
+const (
+	PriceSourceFile   PriceSource = "FILE"
+	PriceSourceHTTP   PriceSource = "HTTP"
+	PriceSourceUpload PriceSource = "UPLOAD"
+)
+
+// Price is the closing price of a symbol on a date. Fund codes are symbols
+// whose price is the NAV per unit.
+type Price struct {
+	Symbol string
+	Date   time.Time
+	Value  decimal.Decimal
+	Source PriceSource
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type PocketReconciliation struct {
+	PocketID      uuid.UUID
+	Name          string
+	Balance       decimal.Decimal // Cached pocket balance
+	LedgerBalance decimal.Decimal // Net of the pocket's transactions
+	Difference    decimal.Decimal // Balance - LedgerBalance
+}
+
+func (p PocketReconciliation) IsBalanced() bool {
+	return p.Difference.IsZero()
+}
+
+type AccountReconciliation struct {
+	AccountID      uuid.UUID
+	Balance        decimal.Decimal // Cached account balance
+	PocketsBalance decimal.Decimal // Sum of the cached pocket balances
+	LedgerBalance  decimal.Decimal // Net of the account's pocket transactions
+	Difference     decimal.Decimal // Balance - PocketsBalance
+	Pockets        []PocketReconciliation
+	Repaired       bool
+
+	// Postings of pockets deleted outright, which no adjustment can balance
+	OrphanedPostings int64
+	OrphanedBalance  decimal.Decimal
+}
+
+func (a AccountReconciliation) IsBalanced() bool {
+	if !a.Difference.IsZero() || !a.PocketsBalance.Equal(a.LedgerBalance) {
+		return false
+	}
+
+	for _, p := range a.Pockets {
+		if !p.IsBalanced() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidAllocationRules = errors.New("INVALID_ALLOCATION_RULES")
+)
+
+// AllocationRuleType is how a rule sizes its share of a deposit.
+type AllocationRuleType string
+
+const (
+	AllocationRuleTypeFixed   AllocationRuleType = "FIXED"   // Value is an amount
+	AllocationRuleTypePercent AllocationRuleType = "PERCENT" // Value is a percentage of the deposit
+)
+
+func (t AllocationRuleType) IsValid() bool {
+	return t == AllocationRuleTypeFixed || t == AllocationRuleTypePercent
+}
+
+// AllocationRule moves part of every deposit from the cashbox of an account
+// into one of its pockets. Rules run by ascending Priority.
+type AllocationRule struct {
+	ID         uuid.UUID
+	AccountID  uuid.UUID
+	PocketID   uuid.UUID
+	Type       AllocationRuleType
+	Value      decimal.Decimal
+	MaxAmount  *decimal.Decimal // Most moved per deposit, nil for no cap
+	MaxBalance *decimal.Decimal // Pocket balance the rule stops filling at, nil for no cap
+	Priority   int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type AllocationRulesInput struct {
+	UserID    uuid.UUID
+	AccountID uuid.UUID
+	Rules     []AllocationRule // Replaces the rules of the account, empty removes them
+}
+
+type AllocationTransfer struct {
+	RuleID   uuid.UUID
+	PocketID uuid.UUID
+	Amount   decimal.Decimal
+}
+
+// AllocationPlan is how a deposit is split. Remaining stays in the cashbox.
+type AllocationPlan struct {
+	Amount    decimal.Decimal
+	Transfers []AllocationTransfer
+	Remaining decimal.Decimal
+}
+
+// PlanAllocation splits amount, just deposited to the cashbox, between the
+// pockets by the rules in priority order. Every rule takes its share of the
+// whole deposit, limited by its caps and by what the earlier rules left.
+// Rules of pockets that are archived or missing from pockets are skipped.
+func PlanAllocation(rules []AllocationRule, amount decimal.Decimal, pockets []Pocket) AllocationPlan {
+	balances := make(map[uuid.UUID]decimal.Decimal, len(pockets))
+	for _, p := range pockets {
+		if !p.Archived {
+			balances[p.ID] = p.Balance
+		}
+	}
+
+	plan := AllocationPlan{
+		Amount:    amount,
+		Remaining: amount,
+	}
+
+	for _, rule := range rules {
+		balance, ok := balances[rule.PocketID]
+		if !ok {
+			continue
+		}
+
+		share := rule.Value
+		if rule.Type == AllocationRuleTypePercent {
+			share = amount.Mul(rule.Value).Div(decimal.NewFromInt(100)).RoundDown(2)
+		}
+		if rule.MaxAmount != nil {
+			share = decimal.Min(share, *rule.MaxAmount)
+		}
+		if rule.MaxBalance != nil {
+			share = decimal.Min(share, rule.MaxBalance.Sub(balance))
+		}
+		share = decimal.Min(share, plan.Remaining)
+
+		if !share.IsPositive() {
+			continue
+		}
+
+		balances[rule.PocketID] = balance.Add(share)
+		plan.Remaining = plan.Remaining.Sub(share)
+		plan.Transfers = append(plan.Transfers, AllocationTransfer{
+			RuleID:   rule.ID,
+			PocketID: rule.PocketID,
+			Amount:   share,
+		})
+	}
+
+	return plan
+}
@@ -0,0 +1,136 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	var (
+		travel    = uuid.New()
+		emergency = uuid.New()
+		archived  = uuid.New()
+		missing   = uuid.New()
+	)
+	pockets := []Pocket{
+		{ID: travel, Balance: decimal.NewFromInt(100)},
+		{ID: emergency, Balance: decimal.NewFromInt(900)},
+		{ID: archived, Balance: decimal.Zero, Archived: true},
+	}
+
+	d := func(s string) *decimal.Decimal {
+		v := decimal.RequireFromString(s)
+		return &v
+	}
+	fixed := func(pocketID uuid.UUID, value string) AllocationRule {
+		return AllocationRule{ID: uuid.New(), PocketID: pocketID, Type: AllocationRuleTypeFixed, Value: decimal.RequireFromString(value)}
+	}
+	percent := func(pocketID uuid.UUID, value string) AllocationRule {
+		return AllocationRule{ID: uuid.New(), PocketID: pocketID, Type: AllocationRuleTypePercent, Value: decimal.RequireFromString(value)}
+	}
+	capped := func(rule AllocationRule, maxAmount, maxBalance *decimal.Decimal) AllocationRule {
+		rule.MaxAmount, rule.MaxBalance = maxAmount, maxBalance
+		return rule
+	}
+
+	type transfer struct {
+		pocketID uuid.UUID
+		amount   string
+	}
+
+	tests := []struct {
+		name      string
+		rules     []AllocationRule
+		amount    string
+		want      []transfer
+		remaining string
+	}{
+		{
+			name:      "no rules keep the deposit in the cashbox",
+			amount:    "1000",
+			remaining: "1000",
+		},
+		{
+			name:      "percent and fixed shares of the whole deposit",
+			rules:     []AllocationRule{percent(travel, "10"), fixed(emergency, "50")},
+			amount:    "1000",
+			want:      []transfer{{travel, "100"}, {emergency, "50"}},
+			remaining: "850",
+		},
+		{
+			name:      "percent share is rounded down",
+			rules:     []AllocationRule{percent(travel, "33")},
+			amount:    "333.33",
+			want:      []transfer{{travel, "109.99"}},
+			remaining: "223.34",
+		},
+		{
+			name:      "max amount caps the share",
+			rules:     []AllocationRule{capped(percent(travel, "50"), d("200"), nil)},
+			amount:    "1000",
+			want:      []transfer{{travel, "200"}},
+			remaining: "800",
+		},
+		{
+			name:      "max balance tops the pocket up",
+			rules:     []AllocationRule{capped(fixed(emergency, "300"), nil, d("1000"))},
+			amount:    "1000",
+			want:      []transfer{{emergency, "100"}},
+			remaining: "900",
+		},
+		{
+			name:      "full pocket is skipped",
+			rules:     []AllocationRule{capped(fixed(emergency, "300"), nil, d("900")), fixed(travel, "10")},
+			amount:    "1000",
+			want:      []transfer{{travel, "10"}},
+			remaining: "990",
+		},
+		{
+			name:      "later rules get what is left",
+			rules:     []AllocationRule{fixed(travel, "800"), fixed(emergency, "500"), fixed(travel, "1")},
+			amount:    "1000",
+			want:      []transfer{{travel, "800"}, {emergency, "200"}},
+			remaining: "0",
+		},
+		{
+			name:      "earlier shares count towards the max balance",
+			rules:     []AllocationRule{fixed(travel, "150"), capped(fixed(travel, "100"), nil, d("300"))},
+			amount:    "1000",
+			want:      []transfer{{travel, "150"}, {travel, "50"}},
+			remaining: "800",
+		},
+		{
+			name:      "archived and missing pockets are skipped",
+			rules:     []AllocationRule{fixed(archived, "100"), fixed(missing, "100"), fixed(travel, "100")},
+			amount:    "1000",
+			want:      []transfer{{travel, "100"}},
+			remaining: "900",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := decimal.RequireFromString(tt.amount)
+			plan := PlanAllocation(tt.rules, amount, pockets)
+
+			if !plan.Amount.Equal(amount) {
+				t.Errorf("Amount = %s, want %s", plan.Amount, amount)
+			}
+			if !plan.Remaining.Equal(decimal.RequireFromString(tt.remaining)) {
+				t.Errorf("Remaining = %s, want %s", plan.Remaining, tt.remaining)
+			}
+
+			if len(plan.Transfers) != len(tt.want) {
+				t.Fatalf("got %d transfers, want %d", len(plan.Transfers), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := plan.Transfers[i]
+				if got.PocketID != want.pocketID || !got.Amount.Equal(decimal.RequireFromString(want.amount)) {
+					t.Errorf("transfer %d = %s to %s, want %s to %s", i, got.Amount, got.PocketID, want.amount, want.pocketID)
+				}
+			}
+		})
+	}
+}
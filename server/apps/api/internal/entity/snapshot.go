@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidInterval = errors.New("INVALID_INTERVAL")
+)
+
+// AccountSnapshot is an account's closing balance on Date.
+type AccountSnapshot struct {
+	Date        time.Time
+	UserID      uuid.UUID
+	AccountID   uuid.UUID
+	AccountType AccountType
+	Currency    string
+	Balance     decimal.Decimal
+	BaseBalance *decimal.Decimal // Nil when there was no exchange rate
+}
+
+// PocketSnapshot is a pocket's closing balance on Date.
+type PocketSnapshot struct {
+	Date      time.Time
+	AccountID uuid.UUID
+	PocketID  uuid.UUID
+	Balance   decimal.Decimal
+}
+
+// NetWorthSnapshot totals a user's account snapshots of Date in BaseCurrency.
+type NetWorthSnapshot struct {
+	Date         time.Time
+	UserID       uuid.UUID
+	BaseCurrency string
+	Total        decimal.Decimal
+	Assets       decimal.Decimal
+	Liabilities  decimal.Decimal // Owed on liability accounts, positive
+}
+
+// DailySnapshot is everything recorded for one user on one day.
+type DailySnapshot struct {
+	NetWorth NetWorthSnapshot
+	Accounts []AccountSnapshot
+	Pockets  []PocketSnapshot
+}
+
+type Interval string
+
+const (
+	IntervalDay   Interval = "day"
+	IntervalWeek  Interval = "week"
+	IntervalMonth Interval = "month"
+)
+
+func (i Interval) IsValid() bool {
+	return i == IntervalDay || i == IntervalWeek || i == IntervalMonth
+}
+
+// Start returns the first day of the interval containing t. Weeks start on
+// Monday.
+func (i Interval) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	switch i {
+	case IntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return day
+}
+
+// NetWorthPoint is the net worth at the end of the interval starting on Date,
+// taken from the last snapshot in it.
+type NetWorthPoint struct {
+	Date         time.Time
+	SnapshotDate time.Time
+	Total        decimal.Decimal
+	Assets       decimal.Decimal
+	Liabilities  decimal.Decimal
+	ByType       map[AccountType]decimal.Decimal
+}
+
+type NetWorthSeries struct {
+	BaseCurrency string
+	Interval     Interval
+	Points       []NetWorthPoint
+}
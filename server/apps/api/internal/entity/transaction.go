@@ -3,16 +3,44 @@ package entity
 import (
 	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrUnbalancedEntry     = errors.New("UNBALANCED_ENTRY")
+	ErrInsufficientBalance = errors.New("INSUFFICIENT_BALANCE")
+	ErrAlreadyReversed     = errors.New("ALREADY_REVERSED")
+	ErrNotReversible       = errors.New("NOT_REVERSIBLE")
+	ErrInvalidAmount       = errors.New("INVALID_AMOUNT")
+)
+
 type TxType string
 
 const (
 	TxTypeDeposit  TxType = "DEPOSIT"
 	TxTypeWithdraw TxType = "WITHDRAW"
 	TxTypeTransfer TxType = "TRANSFER"
+
+	// TxTypeAdjustment corrects drift between a cached balance and the ledger.
+	TxTypeAdjustment TxType = "ADJUSTMENT"
+	// TxTypeReversal undoes the transaction referenced by ReversalOfID.
+	TxTypeReversal TxType = "REVERSAL"
+	// TxTypeFee is a bank fee charged on a transfer between accounts.
+	TxTypeFee TxType = "FEE"
+	// TxTypeBuy and TxTypeSell are the cash side of a trade in a STOCK account.
+	TxTypeBuy  TxType = "BUY"
+	TxTypeSell TxType = "SELL"
+	// TxTypeSubscription and TxTypeRedemption are the cash side of a fund order.
+	TxTypeSubscription TxType = "SUBSCRIPTION"
+	TxTypeRedemption   TxType = "REDEMPTION"
+	// TxTypeInterest is gross interest earned and TxTypeWithholdingTax the tax
+	// withheld from it.
+	TxTypeInterest       TxType = "INTEREST"
+	TxTypeWithholdingTax TxType = "WITHHOLDING_TAX"
+	// TxTypeInterestCharge is interest owed on a liability, added to its debt.
+	TxTypeInterestCharge TxType = "INTEREST_CHARGE"
 )
 
 func (tt TxType) String() string {
@@ -22,10 +50,16 @@ func (tt TxType) String() string {
 type Transaction struct {
 	ID           uuid.UUID
 	AccountID    uuid.UUID
+	ToAccountID  *uuid.UUID // Set when a transfer moves money to another account
 	FromPocketID *uuid.UUID // Deposit == nil, Withdraw == PocketID, Transfer == FromPocketID
 	ToPocketID   *uuid.UUID // Deposit == PocketID, Withdraw == nil, Transfer == ToPocketID
 	Type         TxType
+	Currency     string
 	Amount       decimal.Decimal
+	Note         string
+	Payee        string     // Withdraw == destination of the money
+	ReversalOfID *uuid.UUID // Reversal == reversed transaction ID
+	FeeOfID      *uuid.UUID // Fee == transfer the fee was charged for
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -34,10 +68,90 @@ func (t Transaction) String() string {
 	return t.ID.String() + " " + t.Type.String()
 }
 
+// IsReversible reports whether the transaction can be undone with a reversal. Its
+// pockets must match its type, which rows recorded before the types were
+// backfilled may not.
+func (t Transaction) IsReversible() bool {
+	switch t.Type {
+	case TxTypeDeposit:
+		return t.FromPocketID == nil && t.ToPocketID != nil
+	case TxTypeWithdraw:
+		return t.FromPocketID != nil && t.ToPocketID == nil
+	case TxTypeTransfer:
+		return t.FromPocketID != nil && t.ToPocketID != nil
+	default:
+		return false
+	}
+}
+
 type TransactionInput struct {
 	AccountID    uuid.UUID
+	ToAccountID  *uuid.UUID
 	FromPocketID *uuid.UUID
 	ToPocketID   *uuid.UUID
 	Type         TxType
+	Currency     string // Defaults to the currency of AccountID
 	Amount       decimal.Decimal
+	Note         string
+	Payee        string
+	ReversalOfID *uuid.UUID
+	FeeOfID      *uuid.UUID
+	CreatedAt    time.Time // Defaults to now, set when posting for an earlier date
+}
+
+type TransferInput struct {
+	UserID       uuid.UUID
+	FromPocketID uuid.UUID
+	ToPocketID   uuid.UUID
+	Amount       decimal.Decimal
+	Note         string
+	FeePocketID  *uuid.UUID // Pocket charged for a cross-account fee, defaults to FromPocketID
+}
+
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type TransactionFilter struct {
+	UserID    uuid.UUID
+	AccountID *uuid.UUID
+	PocketID  *uuid.UUID
+	Type      *TxType
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
+	From      *time.Time
+	To        *time.Time
+	Note      string
+	Cursor    *TransactionCursor // Exclusive, in sort order
+	Limit     int
+	Ascending bool
+}
+
+type TransactionPage struct {
+	Transactions []Transaction
+	NextCursor   *TransactionCursor // Nil on the last page
+	Total        int64              // Matching rows across all pages
+}
+
+// PocketTransaction is a transaction seen from one of its pockets.
+type PocketTransaction struct {
+	Transaction
+	SignedAmount           decimal.Decimal // Positive when money entered the pocket
+	CounterpartyPocketID   *uuid.UUID      // Nil for deposits and withdrawals
+	CounterpartyPocketName string
+	RunningBalance         decimal.Decimal // Pocket balance right after this transaction
+}
+
+// Posting is one leg of the double-entry journal entry recorded for a transaction.
+// A posting with a nil PocketID belongs to the account's external ledger account,
+// i.e. money entering or leaving the tracked pockets. The postings of a transaction
+// always sum to zero, and a pocket balance is the sum of its postings.
+type Posting struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	AccountID     uuid.UUID
+	PocketID      *uuid.UUID
+	Amount        decimal.Decimal
+	CreatedAt     time.Time
 }
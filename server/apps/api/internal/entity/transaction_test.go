@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTransactionIsReversible(t *testing.T) {
+	pocket := func() *uuid.UUID {
+		id := uuid.New()
+		return &id
+	}
+
+	tests := []struct {
+		name string
+		tx   Transaction
+		want bool
+	}{
+		{name: "deposit", tx: Transaction{Type: TxTypeDeposit, ToPocketID: pocket()}, want: true},
+		{name: "withdraw", tx: Transaction{Type: TxTypeWithdraw, FromPocketID: pocket()}, want: true},
+		{name: "transfer", tx: Transaction{Type: TxTypeTransfer, FromPocketID: pocket(), ToPocketID: pocket()}, want: true},
+		{name: "legacy withdraw typed deposit", tx: Transaction{Type: TxTypeDeposit, FromPocketID: pocket()}},
+		{name: "legacy transfer typed deposit", tx: Transaction{Type: TxTypeDeposit, FromPocketID: pocket(), ToPocketID: pocket()}},
+		{name: "transfer without destination", tx: Transaction{Type: TxTypeTransfer, FromPocketID: pocket()}},
+		{name: "fee", tx: Transaction{Type: TxTypeFee, FromPocketID: pocket()}},
+		{name: "reversal", tx: Transaction{Type: TxTypeReversal, FromPocketID: pocket(), ToPocketID: pocket()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tx.IsReversible(); got != tt.want {
+				t.Errorf("IsReversible() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
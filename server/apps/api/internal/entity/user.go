@@ -7,12 +7,13 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID
-	Email     string
-	Name      string
-	Password  string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uuid.UUID
+	Email        string
+	Name         string
+	Password     string
+	BaseCurrency string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 func (u User) String() string {
@@ -20,9 +21,10 @@ func (u User) String() string {
 }
 
 type UserInput struct {
-	Email    string
-	Name     string
-	Password string
+	Email        string
+	Name         string
+	Password     string
+	BaseCurrency string
 }
 
 type Token struct {
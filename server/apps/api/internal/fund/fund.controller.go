@@ -0,0 +1,347 @@
+package fund
+
+import (
+	"strings"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+type controller struct {
+	usecase               *usecase
+	currencyUsecase       *currency.Usecase
+	authMiddleware        authentication.AuthMiddleware
+	idempotencyMiddleware idempotency.IdempotencyMiddleware
+}
+
+func NewController(fundUsecase *usecase, currencyUsecase *currency.Usecase, authMiddleware authentication.AuthMiddleware, idempotencyMiddleware idempotency.IdempotencyMiddleware) *controller {
+	return &controller{
+		usecase:               fundUsecase,
+		currencyUsecase:       currencyUsecase,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/funds", h.GetFunds)
+	r.Get("/:id/funds/orders", h.GetFundOrders)
+	r.Post("/:id/funds/subscribe", h.idempotencyMiddleware.Handle, h.Subscribe)
+	r.Post("/:id/funds/redeem", h.idempotencyMiddleware.Handle, h.Redeem)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrNotMutualFundAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a mutual fund account",
+		})
+	case errors.Is(err, entity.ErrPriceNotFound):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "NAV not found",
+		})
+	case errors.Is(err, entity.ErrInsufficientUnits):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient units",
+		})
+	case errors.Is(err, entity.ErrInsufficientBalance):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	case errors.Is(err, entity.ErrFundOrderTooSmall):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Order is too small",
+		})
+	case errors.Is(err, entity.ErrInvalidNAV):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "NAV must be positive",
+		})
+	}
+
+	return false, nil
+}
+
+type fundPositionResponse struct {
+	FundCode     string          `json:"fundCode"`
+	Units        decimal.Decimal `json:"units"`
+	Invested     decimal.Decimal `json:"invested"`
+	NAV          decimal.Decimal `json:"nav"`
+	NAVDate      string          `json:"navDate"`
+	MarketValue  decimal.Decimal `json:"marketValue"`
+	Gain         decimal.Decimal `json:"gain"`
+	GainPercent  decimal.Decimal `json:"gainPercent"`
+	RealizedGain decimal.Decimal `json:"realizedGain"`
+}
+
+type fundsTotalResponse struct {
+	Cash         *decimal.Decimal `json:"cash"`
+	Value        *decimal.Decimal `json:"value"`
+	Invested     *decimal.Decimal `json:"invested"`
+	Gain         *decimal.Decimal `json:"gain"`
+	RealizedGain *decimal.Decimal `json:"realizedGain"`
+}
+
+type fundsResponse struct {
+	Currency     string                 `json:"currency"`
+	Cash         decimal.Decimal        `json:"cash"`
+	Value        decimal.Decimal        `json:"value"`
+	Invested     decimal.Decimal        `json:"invested"`
+	Gain         decimal.Decimal        `json:"gain"`
+	GainPercent  decimal.Decimal        `json:"gainPercent"`
+	RealizedGain decimal.Decimal        `json:"realizedGain"`
+	Funds        []fundPositionResponse `json:"funds"`
+	// Base holds the totals in BaseCurrency, null when there is no exchange rate
+	BaseCurrency string              `json:"baseCurrency"`
+	Base         *fundsTotalResponse `json:"base"`
+}
+
+func gainPercent(gain, invested decimal.Decimal) decimal.Decimal {
+	if !invested.IsPositive() {
+		return decimal.Zero
+	}
+
+	return gain.Mul(decimal.NewFromInt(100)).Div(invested).Round(2)
+}
+
+func (h *controller) GetFunds(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	summary, err := h.usecase.GetFundSummary(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get funds")
+	}
+
+	baseCurrency, rate, err := h.currencyUsecase.BaseRate(ctx.UserContext(), userID, summary.Currency)
+	if err != nil {
+		return errors.Wrap(err, "failed to get base rate")
+	}
+
+	response := fundsResponse{
+		Currency:     summary.Currency,
+		Cash:         summary.Cash,
+		Funds:        make([]fundPositionResponse, 0, len(summary.Positions)),
+		BaseCurrency: baseCurrency,
+	}
+	for _, p := range summary.Positions {
+		response.Funds = append(response.Funds, fundPositionResponse{
+			FundCode:     p.FundCode,
+			Units:        p.Units,
+			Invested:     p.Invested,
+			NAV:          p.NAV,
+			NAVDate:      p.NAVDate.Format(dateLayout),
+			MarketValue:  p.MarketValue,
+			Gain:         p.Gain,
+			GainPercent:  gainPercent(p.Gain, p.Invested),
+			RealizedGain: p.RealizedGain,
+		})
+
+		response.Value = response.Value.Add(p.MarketValue)
+		response.Invested = response.Invested.Add(p.Invested)
+		response.Gain = response.Gain.Add(p.Gain)
+		response.RealizedGain = response.RealizedGain.Add(p.RealizedGain)
+	}
+	response.GainPercent = gainPercent(response.Gain, response.Invested)
+
+	if rate != nil {
+		response.Base = &fundsTotalResponse{
+			Cash:         currency.Convert(response.Cash, rate),
+			Value:        currency.Convert(response.Value, rate),
+			Invested:     currency.Convert(response.Invested, rate),
+			Gain:         currency.Convert(response.Gain, rate),
+			RealizedGain: currency.Convert(response.RealizedGain, rate),
+		}
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type fundOrderResponse struct {
+	ID            uuid.UUID            `json:"id"`
+	AccountID     uuid.UUID            `json:"accountId"`
+	TransactionID uuid.UUID            `json:"transactionId"`
+	FundCode      string               `json:"fundCode"`
+	Side          entity.FundOrderSide `json:"side"`
+	Units         decimal.Decimal      `json:"units"`
+	NAV           decimal.Decimal      `json:"nav"`
+	NAVDate       string               `json:"navDate"`
+	Amount        decimal.Decimal      `json:"amount"`
+	CreatedAt     int64                `json:"createdAt"`
+}
+
+func newFundOrderResponse(o entity.FundOrder) fundOrderResponse {
+	return fundOrderResponse{
+		ID:            o.ID,
+		AccountID:     o.AccountID,
+		TransactionID: o.TransactionID,
+		FundCode:      o.FundCode,
+		Side:          o.Side,
+		Units:         o.Units,
+		NAV:           o.NAV,
+		NAVDate:       o.NAVDate.Format(dateLayout),
+		Amount:        o.Amount,
+		CreatedAt:     o.CreatedAt.Unix(),
+	}
+}
+
+func (h *controller) GetFundOrders(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	orders, err := h.usecase.GetFundOrders(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get fund orders")
+	}
+
+	response := make([]fundOrderResponse, 0, len(orders))
+	for _, o := range orders {
+		response = append(response, newFundOrderResponse(o))
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type fundOrderRequest struct {
+	Id       uuid.UUID        `params:"id"`
+	FundCode string           `json:"fundCode"`
+	Amount   decimal.Decimal  `json:"amount"`
+	Units    decimal.Decimal  `json:"units"`
+	NAV      *decimal.Decimal `json:"nav"`
+	NAVDate  string           `json:"navDate"` // 2006-01-02, defaults to today
+	Note     string           `json:"note"`
+
+	side    entity.FundOrderSide
+	navDate time.Time
+}
+
+func (a *fundOrderRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	a.FundCode = strings.ToUpper(strings.TrimSpace(a.FundCode))
+
+	a.navDate = time.Now()
+	if a.NAVDate != "" {
+		navDate, err := time.Parse(dateLayout, a.NAVDate)
+		if err != nil {
+			return errors.Wrap(err, "invalid navDate")
+		}
+		a.navDate = navDate
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *fundOrderRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.FundCode != "", "fundCode is required")
+	if a.side == entity.FundOrderSideSubscribe {
+		v.Must(a.Amount.IsPositive(), "amount must be positive")
+	} else {
+		v.Must(a.Units.IsPositive(), "units must be positive")
+	}
+	v.Must(a.NAV == nil || a.NAV.IsPositive(), "nav must be positive")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) Subscribe(ctx *fiber.Ctx) error {
+	return h.order(ctx, entity.FundOrderSideSubscribe)
+}
+
+func (h *controller) Redeem(ctx *fiber.Ctx) error {
+	return h.order(ctx, entity.FundOrderSideRedeem)
+}
+
+func (h *controller) order(ctx *fiber.Ctx, side entity.FundOrderSide) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	req := fundOrderRequest{side: side}
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	order, err := h.usecase.Order(ctx.UserContext(), entity.FundOrderInput{
+		UserID:    userID,
+		AccountID: req.Id,
+		FundCode:  req.FundCode,
+		Side:      side,
+		Amount:    req.Amount,
+		Units:     req.Units,
+		NAV:       req.NAV,
+		NAVDate:   req.navDate,
+		Note:      req.Note,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s", strings.ToLower(side.String()))
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newFundOrderResponse(*order),
+	})
+}
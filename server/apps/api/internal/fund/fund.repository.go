@@ -0,0 +1,85 @@
+package fund
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.FundRepository {
+	db.AutoMigrate(&model.FundOrder{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetFundOrders(ctx context.Context, accountID uuid.UUID) ([]entity.FundOrder, error) {
+	var orders []*model.FundOrder
+	if err := r.getDB(ctx).Where("account_id = ?", accountID).Order("created_at asc, id asc").Find(&orders).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get fund orders")
+	}
+
+	var result []entity.FundOrder
+	for _, o := range orders {
+		result = append(result, entity.FundOrder{
+			ID:            o.ID,
+			AccountID:     o.AccountID,
+			TransactionID: o.TransactionID,
+			FundCode:      o.FundCode,
+			Side:          o.Side,
+			Units:         o.Units,
+			NAV:           o.NAV,
+			NAVDate:       o.NAVDate,
+			Amount:        o.Amount,
+			CreatedAt:     o.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) CreateFundOrder(ctx context.Context, order entity.FundOrder) (*entity.FundOrder, error) {
+	o := model.FundOrder{
+		ID:            uuid.New(),
+		AccountID:     order.AccountID,
+		TransactionID: order.TransactionID,
+		FundCode:      order.FundCode,
+		Side:          order.Side,
+		Units:         order.Units,
+		NAV:           order.NAV,
+		NAVDate:       order.NAVDate,
+		Amount:        order.Amount,
+	}
+
+	if err := r.getDB(ctx).Create(&o).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to create fund order")
+	}
+
+	return &entity.FundOrder{
+		ID:            o.ID,
+		AccountID:     o.AccountID,
+		TransactionID: o.TransactionID,
+		FundCode:      o.FundCode,
+		Side:          o.Side,
+		Units:         o.Units,
+		NAV:           o.NAV,
+		NAVDate:       o.NAVDate,
+		Amount:        o.Amount,
+		CreatedAt:     o.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,279 @@
+package fund
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	fundRepo        interfaces.FundRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+	priceProvider   interfaces.PriceProvider
+}
+
+func NewUsecase(txManager interfaces.TxManager, fundRepo interfaces.FundRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository, priceProvider interfaces.PriceProvider) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		fundRepo:        fundRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+		priceProvider:   priceProvider,
+	}
+}
+
+func (u *usecase) getFundAccount(ctx context.Context, userID, accountID uuid.UUID) (*entity.Account, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type != entity.AccountTypeMutualFund {
+		return nil, errors.WithStack(entity.ErrNotMutualFundAccount)
+	}
+
+	return account, nil
+}
+
+func (u *usecase) GetFundOrders(ctx context.Context, userID, accountID uuid.UUID) ([]entity.FundOrder, error) {
+	if _, err := u.getFundAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	orders, err := u.fundRepo.GetFundOrders(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fund orders")
+	}
+
+	return orders, nil
+}
+
+// GetFundSummary values the units held in each fund at its latest NAV. A fund
+// without a published NAV is valued at the NAV of its latest order.
+func (u *usecase) GetFundSummary(ctx context.Context, userID, accountID uuid.UUID) (*entity.FundSummary, error) {
+	account, err := u.getFundAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	orders, err := u.fundRepo.GetFundOrders(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get fund orders")
+	}
+
+	positions := buildPositions(orders)
+	now := time.Now()
+	for i := range positions {
+		p := &positions[i]
+
+		nav, err := u.priceProvider.GetPrice(ctx, p.FundCode, now)
+		if err != nil && !errors.Is(err, entity.ErrPriceNotFound) {
+			return nil, errors.Wrap(err, "failed to get nav")
+		}
+		if nav != nil && !nav.Date.Before(p.NAVDate) {
+			p.NAV = nav.Value
+			p.NAVDate = nav.Date
+		}
+
+		p.MarketValue = p.Units.Mul(p.NAV).Round(2)
+		p.Gain = p.MarketValue.Sub(p.Invested)
+	}
+
+	return &entity.FundSummary{
+		Currency:  account.Currency,
+		Cash:      account.Balance,
+		Positions: positions,
+	}, nil
+}
+
+// Order subscribes or redeems fund units at the NAV of input.NAVDate, looked up
+// from the price provider unless input.NAV is given. The cash side moves through
+// the account's cashbox pocket.
+func (u *usecase) Order(ctx context.Context, input entity.FundOrderInput) (*entity.FundOrder, error) {
+	if input.Side != entity.FundOrderSideSubscribe && input.Side != entity.FundOrderSideRedeem {
+		return nil, errors.WithStack(entity.ErrInvalidFundOrderSide)
+	}
+
+	if _, err := u.getFundAccount(ctx, input.UserID, input.AccountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	nav := entity.Price{
+		Symbol: input.FundCode,
+		Date:   input.NAVDate,
+	}
+	if input.NAV != nil {
+		nav.Value = *input.NAV
+	} else {
+		found, err := u.priceProvider.GetPrice(ctx, input.FundCode, input.NAVDate)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get nav")
+		}
+		nav.Value = found.Value
+		nav.Date = found.Date
+	}
+
+	// Units are the amount divided by the NAV
+	if !nav.Value.IsPositive() {
+		return nil, errors.WithStack(entity.ErrInvalidNAV)
+	}
+
+	order := entity.FundOrder{
+		AccountID: input.AccountID,
+		FundCode:  input.FundCode,
+		Side:      input.Side,
+		NAV:       nav.Value,
+		NAVDate:   nav.Date,
+	}
+
+	txInput := entity.TransactionInput{
+		AccountID: input.AccountID,
+		Note:      input.Note,
+	}
+
+	if input.Side == entity.FundOrderSideSubscribe {
+		order.Amount = input.Amount
+		order.Units = input.Amount.Div(nav.Value).RoundFloor(entity.FundUnitPlaces)
+
+		txInput.Type = entity.TxTypeSubscription
+		txInput.FromPocketID = &cashbox.ID
+	} else {
+		order.Units = input.Units
+		order.Amount = input.Units.Mul(nav.Value).RoundFloor(2)
+
+		txInput.Type = entity.TxTypeRedemption
+		txInput.ToPocketID = &cashbox.ID
+	}
+	txInput.Amount = order.Amount
+
+	if !order.Units.IsPositive() || !order.Amount.IsPositive() {
+		return nil, errors.WithStack(entity.ErrFundOrderTooSmall)
+	}
+
+	var result *entity.FundOrder
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if input.Side == entity.FundOrderSideRedeem {
+			// Lock the account so concurrent redemptions see each other's orders
+			if _, err := u.accountRepo.LockAccount(ctx, input.AccountID); err != nil {
+				return errors.Wrap(err, "failed to lock account")
+			}
+
+			orders, err := u.fundRepo.GetFundOrders(ctx, input.AccountID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get fund orders")
+			}
+
+			units := decimal.Zero
+			for _, p := range buildPositions(orders) {
+				if p.FundCode == input.FundCode {
+					units = p.Units
+				}
+			}
+
+			if units.LessThan(input.Units) {
+				return errors.WithStack(entity.ErrInsufficientUnits)
+			}
+		}
+
+		transaction, err := u.transactionRepo.CreateTransaction(ctx, txInput)
+		if err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+		order.TransactionID = transaction.ID
+
+		if input.Side == entity.FundOrderSideSubscribe {
+			if err := u.accountRepo.Withdraw(ctx, input.AccountID, order.Amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from account")
+			}
+
+			if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, order.Amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+			}
+		} else {
+			if err := u.accountRepo.Deposit(ctx, input.AccountID, order.Amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to account")
+			}
+
+			if err := u.pocketRepo.Deposit(ctx, cashbox.ID, order.Amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to cashbox pocket")
+			}
+		}
+
+		result, err = u.fundRepo.CreateFundOrder(ctx, order)
+		if err != nil {
+			return errors.Wrap(err, "failed to create fund order")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// buildPositions replays orders, oldest first, into units held and their
+// average cost per fund. NAV is taken from the latest order.
+func buildPositions(orders []entity.FundOrder) []entity.FundPosition {
+	positions := make(map[string]*entity.FundPosition)
+
+	for _, o := range orders {
+		p, ok := positions[o.FundCode]
+		if !ok {
+			p = &entity.FundPosition{FundCode: o.FundCode}
+			positions[o.FundCode] = p
+		}
+
+		if !o.NAVDate.Before(p.NAVDate) {
+			p.NAV = o.NAV
+			p.NAVDate = o.NAVDate
+		}
+
+		if o.Side == entity.FundOrderSideSubscribe {
+			p.Units = p.Units.Add(o.Units)
+			p.Invested = p.Invested.Add(o.Amount)
+			continue
+		}
+
+		cost := decimal.Zero
+		if p.Units.IsPositive() {
+			cost = p.Invested.Mul(o.Units).Div(p.Units).Round(2)
+		}
+
+		p.Units = p.Units.Sub(o.Units)
+		p.Invested = p.Invested.Sub(cost)
+		p.RealizedGain = p.RealizedGain.Add(o.Amount.Sub(cost))
+
+		if !p.Units.IsPositive() {
+			p.Invested = decimal.Zero
+		}
+	}
+
+	result := make([]entity.FundPosition, 0, len(positions))
+	for _, p := range positions {
+		result = append(result, *p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FundCode < result[j].FundCode
+	})
+
+	return result
+}
@@ -0,0 +1,367 @@
+package holding
+
+import (
+	"strings"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase               *usecase
+	currencyUsecase       *currency.Usecase
+	authMiddleware        authentication.AuthMiddleware
+	idempotencyMiddleware idempotency.IdempotencyMiddleware
+}
+
+func NewController(holdingUsecase *usecase, currencyUsecase *currency.Usecase, authMiddleware authentication.AuthMiddleware, idempotencyMiddleware idempotency.IdempotencyMiddleware) *controller {
+	return &controller{
+		usecase:               holdingUsecase,
+		currencyUsecase:       currencyUsecase,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/holdings", h.GetHoldings)
+	r.Get("/:id/holdings/trades", h.GetTrades)
+	r.Get("/:id/holdings/:symbol/lots", h.GetLots)
+	r.Post("/:id/holdings/buy", h.idempotencyMiddleware.Handle, h.Buy)
+	r.Post("/:id/holdings/sell", h.idempotencyMiddleware.Handle, h.Sell)
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrNotStockAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a stock account",
+		})
+	case errors.Is(err, entity.ErrInvalidCostBasisMethod):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid cost basis method",
+		})
+	case errors.Is(err, entity.ErrInsufficientQuantity):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient quantity",
+		})
+	case errors.Is(err, entity.ErrInsufficientBalance):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	case errors.Is(err, entity.ErrFeeExceedsTradeProceeds):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Fee exceeds trade proceeds",
+		})
+	}
+
+	return false, nil
+}
+
+type positionResponse struct {
+	Symbol        string          `json:"symbol"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	CostBasis     decimal.Decimal `json:"costBasis"`
+	AverageCost   decimal.Decimal `json:"averageCost"`
+	MarketPrice   decimal.Decimal `json:"marketPrice"`
+	MarketValue   decimal.Decimal `json:"marketValue"`
+	RealizedPnL   decimal.Decimal `json:"realizedPnl"`
+	UnrealizedPnL decimal.Decimal `json:"unrealizedPnl"`
+}
+
+type holdingsTotalResponse struct {
+	CostBasis     *decimal.Decimal `json:"costBasis"`
+	MarketValue   *decimal.Decimal `json:"marketValue"`
+	RealizedPnL   *decimal.Decimal `json:"realizedPnl"`
+	UnrealizedPnL *decimal.Decimal `json:"unrealizedPnl"`
+}
+
+type holdingsResponse struct {
+	Method        entity.CostBasisMethod `json:"method"`
+	Currency      string                 `json:"currency"`
+	Positions     []positionResponse     `json:"positions"`
+	CostBasis     decimal.Decimal        `json:"costBasis"`
+	MarketValue   decimal.Decimal        `json:"marketValue"`
+	RealizedPnL   decimal.Decimal        `json:"realizedPnl"`
+	UnrealizedPnL decimal.Decimal        `json:"unrealizedPnl"`
+	// Base holds the totals in BaseCurrency, null when there is no exchange rate
+	BaseCurrency string                 `json:"baseCurrency"`
+	Base         *holdingsTotalResponse `json:"base"`
+}
+
+func (h *controller) GetHoldings(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	method := entity.CostBasisMethod(strings.ToUpper(ctx.Query("method", entity.CostBasisFIFO.String())))
+
+	holdings, err := h.usecase.GetHoldings(ctx.UserContext(), userID, accountID, method)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get holdings")
+	}
+
+	baseCurrency, rate, err := h.currencyUsecase.BaseRate(ctx.UserContext(), userID, holdings.Currency)
+	if err != nil {
+		return errors.Wrap(err, "failed to get base rate")
+	}
+
+	response := holdingsResponse{
+		Method:       method,
+		Currency:     holdings.Currency,
+		Positions:    make([]positionResponse, 0, len(holdings.Positions)),
+		BaseCurrency: baseCurrency,
+	}
+	for _, p := range holdings.Positions {
+		response.Positions = append(response.Positions, positionResponse{
+			Symbol:        p.Symbol,
+			Quantity:      p.Quantity,
+			CostBasis:     p.CostBasis,
+			AverageCost:   p.AverageCost,
+			MarketPrice:   p.MarketPrice,
+			MarketValue:   p.MarketValue,
+			RealizedPnL:   p.RealizedPnL,
+			UnrealizedPnL: p.UnrealizedPnL,
+		})
+
+		response.CostBasis = response.CostBasis.Add(p.CostBasis)
+		response.MarketValue = response.MarketValue.Add(p.MarketValue)
+		response.RealizedPnL = response.RealizedPnL.Add(p.RealizedPnL)
+		response.UnrealizedPnL = response.UnrealizedPnL.Add(p.UnrealizedPnL)
+	}
+
+	if rate != nil {
+		response.Base = &holdingsTotalResponse{
+			CostBasis:     currency.Convert(response.CostBasis, rate),
+			MarketValue:   currency.Convert(response.MarketValue, rate),
+			RealizedPnL:   currency.Convert(response.RealizedPnL, rate),
+			UnrealizedPnL: currency.Convert(response.UnrealizedPnL, rate),
+		}
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type tradeResponse struct {
+	ID            uuid.UUID        `json:"id"`
+	AccountID     uuid.UUID        `json:"accountId"`
+	TransactionID uuid.UUID        `json:"transactionId"`
+	Symbol        string           `json:"symbol"`
+	Side          entity.TradeSide `json:"side"`
+	Quantity      decimal.Decimal  `json:"quantity"`
+	Price         decimal.Decimal  `json:"price"`
+	Fee           decimal.Decimal  `json:"fee"`
+	Amount        decimal.Decimal  `json:"amount"`
+	CreatedAt     int64            `json:"createdAt"`
+}
+
+func newTradeResponse(t entity.Trade) tradeResponse {
+	return tradeResponse{
+		ID:            t.ID,
+		AccountID:     t.AccountID,
+		TransactionID: t.TransactionID,
+		Symbol:        t.Symbol,
+		Side:          t.Side,
+		Quantity:      t.Quantity,
+		Price:         t.Price,
+		Fee:           t.Fee,
+		Amount:        t.CashAmount(),
+		CreatedAt:     t.CreatedAt.Unix(),
+	}
+}
+
+func (h *controller) GetTrades(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	trades, err := h.usecase.GetTrades(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get trades")
+	}
+
+	response := make([]tradeResponse, 0, len(trades))
+	for _, t := range trades {
+		response = append(response, newTradeResponse(t))
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type lotResponse struct {
+	ID                uuid.UUID       `json:"id"`
+	TradeID           uuid.UUID       `json:"tradeId"`
+	Symbol            string          `json:"symbol"`
+	Quantity          decimal.Decimal `json:"quantity"`
+	RemainingQuantity decimal.Decimal `json:"remainingQuantity"`
+	CostPerUnit       decimal.Decimal `json:"costPerUnit"`
+	CreatedAt         int64           `json:"createdAt"`
+}
+
+func (h *controller) GetLots(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	lots, err := h.usecase.GetLots(ctx.UserContext(), userID, accountID, normalizeSymbol(ctx.Params("symbol")))
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get lots")
+	}
+
+	response := make([]lotResponse, 0, len(lots))
+	for _, l := range lots {
+		response = append(response, lotResponse{
+			ID:                l.ID,
+			TradeID:           l.TradeID,
+			Symbol:            l.Symbol,
+			Quantity:          l.Quantity,
+			RemainingQuantity: l.RemainingQuantity,
+			CostPerUnit:       l.CostPerUnit,
+			CreatedAt:         l.CreatedAt.Unix(),
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type tradeRequest struct {
+	Id       uuid.UUID       `params:"id"`
+	Symbol   string          `json:"symbol"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+	Fee      decimal.Decimal `json:"fee"`
+	Note     string          `json:"note"`
+}
+
+func (a *tradeRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	a.Symbol = normalizeSymbol(a.Symbol)
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *tradeRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Symbol != "", "symbol is required")
+	v.Must(a.Quantity.IsPositive(), "quantity must be positive")
+	v.Must(a.Price.IsPositive(), "price must be positive")
+	v.Must(!a.Fee.IsNegative(), "fee must not be negative")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) Buy(ctx *fiber.Ctx) error {
+	return h.trade(ctx, entity.TradeSideBuy)
+}
+
+func (h *controller) Sell(ctx *fiber.Ctx) error {
+	return h.trade(ctx, entity.TradeSideSell)
+}
+
+func (h *controller) trade(ctx *fiber.Ctx, side entity.TradeSide) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req tradeRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	trade, err := h.usecase.Trade(ctx.UserContext(), entity.TradeInput{
+		UserID:    userID,
+		AccountID: req.Id,
+		Symbol:    req.Symbol,
+		Side:      side,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		Fee:       req.Fee,
+		Note:      req.Note,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to %s", strings.ToLower(side.String()))
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newTradeResponse(*trade),
+	})
+}
@@ -0,0 +1,174 @@
+package holding
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.HoldingRepository {
+	db.AutoMigrate(&model.Trade{}, &model.Lot{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetTrades(ctx context.Context, accountID uuid.UUID) ([]entity.Trade, error) {
+	var trades []*model.Trade
+	if err := r.getDB(ctx).Where("account_id = ?", accountID).Order("created_at asc, id asc").Find(&trades).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get trades")
+	}
+
+	var result []entity.Trade
+	for _, t := range trades {
+		result = append(result, entity.Trade{
+			ID:            t.ID,
+			AccountID:     t.AccountID,
+			TransactionID: t.TransactionID,
+			Symbol:        t.Symbol,
+			Side:          t.Side,
+			Quantity:      t.Quantity,
+			Price:         t.Price,
+			Fee:           t.Fee,
+			CreatedAt:     t.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) CreateTrade(ctx context.Context, trade entity.Trade) (*entity.Trade, error) {
+	t := model.Trade{
+		ID:            uuid.New(),
+		AccountID:     trade.AccountID,
+		TransactionID: trade.TransactionID,
+		Symbol:        trade.Symbol,
+		Side:          trade.Side,
+		Quantity:      trade.Quantity,
+		Price:         trade.Price,
+		Fee:           trade.Fee,
+	}
+
+	if err := r.getDB(ctx).Create(&t).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to create trade")
+	}
+
+	return &entity.Trade{
+		ID:            t.ID,
+		AccountID:     t.AccountID,
+		TransactionID: t.TransactionID,
+		Symbol:        t.Symbol,
+		Side:          t.Side,
+		Quantity:      t.Quantity,
+		Price:         t.Price,
+		Fee:           t.Fee,
+		CreatedAt:     t.CreatedAt,
+	}, nil
+}
+
+func (r *repository) GetLots(ctx context.Context, accountID uuid.UUID, symbol string) ([]entity.Lot, error) {
+	var lots []*model.Lot
+	if err := r.getDB(ctx).Where("account_id = ? AND symbol = ?", accountID, symbol).Order("created_at asc, id asc").Find(&lots).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get lots")
+	}
+
+	var result []entity.Lot
+	for _, l := range lots {
+		result = append(result, entity.Lot{
+			ID:                l.ID,
+			AccountID:         l.AccountID,
+			TradeID:           l.TradeID,
+			Symbol:            l.Symbol,
+			Quantity:          l.Quantity,
+			RemainingQuantity: l.RemainingQuantity,
+			CostPerUnit:       l.CostPerUnit,
+			CreatedAt:         l.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) CreateLot(ctx context.Context, lot entity.Lot) (*entity.Lot, error) {
+	l := model.Lot{
+		ID:                uuid.New(),
+		AccountID:         lot.AccountID,
+		TradeID:           lot.TradeID,
+		Symbol:            lot.Symbol,
+		Quantity:          lot.Quantity,
+		RemainingQuantity: lot.Quantity,
+		CostPerUnit:       lot.CostPerUnit,
+	}
+
+	if err := r.getDB(ctx).Create(&l).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to create lot")
+	}
+
+	return &entity.Lot{
+		ID:                l.ID,
+		AccountID:         l.AccountID,
+		TradeID:           l.TradeID,
+		Symbol:            l.Symbol,
+		Quantity:          l.Quantity,
+		RemainingQuantity: l.RemainingQuantity,
+		CostPerUnit:       l.CostPerUnit,
+		CreatedAt:         l.CreatedAt,
+	}, nil
+}
+
+func (r *repository) ConsumeLots(ctx context.Context, accountID uuid.UUID, symbol string, quantity decimal.Decimal) (decimal.Decimal, error) {
+	cost := decimal.Zero
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var lots []*model.Lot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account_id = ? AND symbol = ? AND remaining_quantity > 0", accountID, symbol).Order("created_at asc, id asc").Find(&lots).Error; err != nil {
+			return errors.Wrap(err, "failed to lock lots")
+		}
+
+		remaining := quantity
+		for _, l := range lots {
+			if !remaining.IsPositive() {
+				break
+			}
+
+			taken := decimal.Min(l.RemainingQuantity, remaining)
+			cost = cost.Add(taken.Mul(l.CostPerUnit))
+			remaining = remaining.Sub(taken)
+
+			l.RemainingQuantity = l.RemainingQuantity.Sub(taken)
+			l.UpdatedAt = time.Now()
+
+			if err := tx.Save(l).Error; err != nil {
+				return errors.Wrap(err, "failed to update lot")
+			}
+		}
+
+		if remaining.IsPositive() {
+			return errors.Wrap(entity.ErrInsufficientQuantity, "failed to consume lots")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return decimal.Zero, errors.WithStack(err)
+	}
+
+	return cost, nil
+}
@@ -0,0 +1,297 @@
+package holding
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	holdingRepo     interfaces.HoldingRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+	priceProvider   interfaces.PriceProvider
+}
+
+func NewUsecase(txManager interfaces.TxManager, holdingRepo interfaces.HoldingRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository, priceProvider interfaces.PriceProvider) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		holdingRepo:     holdingRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+		priceProvider:   priceProvider,
+	}
+}
+
+func (u *usecase) getStockAccount(ctx context.Context, userID, accountID uuid.UUID) (*entity.Account, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type != entity.AccountTypeStock {
+		return nil, errors.WithStack(entity.ErrNotStockAccount)
+	}
+
+	return account, nil
+}
+
+func (u *usecase) GetTrades(ctx context.Context, userID, accountID uuid.UUID) ([]entity.Trade, error) {
+	if _, err := u.getStockAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	trades, err := u.holdingRepo.GetTrades(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get trades")
+	}
+
+	return trades, nil
+}
+
+func (u *usecase) GetLots(ctx context.Context, userID, accountID uuid.UUID, symbol string) ([]entity.Lot, error) {
+	if _, err := u.getStockAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lots, err := u.holdingRepo.GetLots(ctx, accountID, symbol)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get lots")
+	}
+
+	return lots, nil
+}
+
+// GetHoldings replays the account's trades into one position per symbol,
+// including closed positions that only carry realized P&L. Open positions are
+// valued at the latest market price, or the latest trade price without one.
+func (u *usecase) GetHoldings(ctx context.Context, userID, accountID uuid.UUID, method entity.CostBasisMethod) (*entity.Holdings, error) {
+	if method != entity.CostBasisFIFO && method != entity.CostBasisAverage {
+		return nil, errors.WithStack(entity.ErrInvalidCostBasisMethod)
+	}
+
+	account, err := u.getStockAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	trades, err := u.holdingRepo.GetTrades(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get trades")
+	}
+
+	positions := buildPositions(trades, method)
+	now := time.Now()
+	for i := range positions {
+		p := &positions[i]
+		if !p.Quantity.IsPositive() {
+			continue
+		}
+
+		price, err := u.priceProvider.GetPrice(ctx, p.Symbol, now)
+		if errors.Is(err, entity.ErrPriceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get price")
+		}
+
+		p.MarketPrice = price.Value
+		p.MarketValue = p.Quantity.Mul(p.MarketPrice)
+		p.UnrealizedPnL = p.MarketValue.Sub(p.CostBasis)
+	}
+
+	return &entity.Holdings{
+		Currency:  account.Currency,
+		Positions: positions,
+	}, nil
+}
+
+// Trade records a buy or sell. The cash side moves through the account's
+// cashbox pocket as a BUY or SELL transaction.
+func (u *usecase) Trade(ctx context.Context, input entity.TradeInput) (*entity.Trade, error) {
+	if input.Side != entity.TradeSideBuy && input.Side != entity.TradeSideSell {
+		return nil, errors.WithStack(entity.ErrInvalidTradeSide)
+	}
+
+	if _, err := u.getStockAccount(ctx, input.UserID, input.AccountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	trade := entity.Trade{
+		AccountID: input.AccountID,
+		Symbol:    input.Symbol,
+		Side:      input.Side,
+		Quantity:  input.Quantity,
+		Price:     input.Price,
+		Fee:       input.Fee,
+	}
+
+	amount := trade.CashAmount()
+	if !amount.IsPositive() {
+		return nil, errors.WithStack(entity.ErrFeeExceedsTradeProceeds)
+	}
+
+	var result *entity.Trade
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		txInput := entity.TransactionInput{
+			AccountID: input.AccountID,
+			Amount:    amount,
+			Note:      input.Note,
+		}
+
+		if input.Side == entity.TradeSideBuy {
+			txInput.Type = entity.TxTypeBuy
+			txInput.FromPocketID = &cashbox.ID
+		} else {
+			txInput.Type = entity.TxTypeSell
+			txInput.ToPocketID = &cashbox.ID
+		}
+
+		transaction, err := u.transactionRepo.CreateTransaction(ctx, txInput)
+		if err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+		trade.TransactionID = transaction.ID
+
+		if input.Side == entity.TradeSideBuy {
+			if err := u.accountRepo.Withdraw(ctx, input.AccountID, amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from account")
+			}
+
+			if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+			}
+		} else {
+			if err := u.accountRepo.Deposit(ctx, input.AccountID, amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to account")
+			}
+
+			if err := u.pocketRepo.Deposit(ctx, cashbox.ID, amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to cashbox pocket")
+			}
+
+			if _, err := u.holdingRepo.ConsumeLots(ctx, input.AccountID, input.Symbol, input.Quantity); err != nil {
+				return errors.Wrap(err, "failed to consume lots")
+			}
+		}
+
+		result, err = u.holdingRepo.CreateTrade(ctx, trade)
+		if err != nil {
+			return errors.Wrap(err, "failed to create trade")
+		}
+
+		if input.Side == entity.TradeSideBuy {
+			if _, err := u.holdingRepo.CreateLot(ctx, entity.Lot{
+				AccountID:   input.AccountID,
+				TradeID:     result.ID,
+				Symbol:      input.Symbol,
+				Quantity:    input.Quantity,
+				CostPerUnit: amount.Div(input.Quantity),
+			}); err != nil {
+				return errors.Wrap(err, "failed to create lot")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+type fifoLot struct {
+	quantity    decimal.Decimal
+	costPerUnit decimal.Decimal
+}
+
+// buildPositions replays trades, oldest first, with the given cost basis
+// method. The market price of a symbol is the price of its latest trade.
+func buildPositions(trades []entity.Trade, method entity.CostBasisMethod) []entity.Position {
+	positions := make(map[string]*entity.Position)
+	lots := make(map[string][]fifoLot)
+
+	for _, t := range trades {
+		p, ok := positions[t.Symbol]
+		if !ok {
+			p = &entity.Position{Symbol: t.Symbol}
+			positions[t.Symbol] = p
+		}
+		p.MarketPrice = t.Price
+
+		cash := t.CashAmount()
+		if t.Side == entity.TradeSideBuy {
+			p.Quantity = p.Quantity.Add(t.Quantity)
+			p.CostBasis = p.CostBasis.Add(cash)
+			lots[t.Symbol] = append(lots[t.Symbol], fifoLot{
+				quantity:    t.Quantity,
+				costPerUnit: cash.Div(t.Quantity),
+			})
+			continue
+		}
+
+		var cost decimal.Decimal
+		switch method {
+		case entity.CostBasisFIFO:
+			remaining := t.Quantity
+			for remaining.IsPositive() && len(lots[t.Symbol]) > 0 {
+				lot := &lots[t.Symbol][0]
+				taken := decimal.Min(lot.quantity, remaining)
+				cost = cost.Add(taken.Mul(lot.costPerUnit))
+				remaining = remaining.Sub(taken)
+
+				lot.quantity = lot.quantity.Sub(taken)
+				if !lot.quantity.IsPositive() {
+					lots[t.Symbol] = lots[t.Symbol][1:]
+				}
+			}
+		case entity.CostBasisAverage:
+			if p.Quantity.IsPositive() {
+				cost = p.CostBasis.Mul(t.Quantity).Div(p.Quantity)
+			}
+		}
+
+		p.Quantity = p.Quantity.Sub(t.Quantity)
+		p.CostBasis = p.CostBasis.Sub(cost)
+		p.RealizedPnL = p.RealizedPnL.Add(cash.Sub(cost))
+
+		// Drop rounding residue once the position is closed
+		if !p.Quantity.IsPositive() {
+			p.CostBasis = decimal.Zero
+		}
+	}
+
+	result := make([]entity.Position, 0, len(positions))
+	for _, p := range positions {
+		if p.Quantity.IsPositive() {
+			p.AverageCost = p.CostBasis.Div(p.Quantity)
+		}
+		p.MarketValue = p.Quantity.Mul(p.MarketPrice)
+		p.UnrealizedPnL = p.MarketValue.Sub(p.CostBasis)
+
+		result = append(result, *p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Symbol < result[j].Symbol
+	})
+
+	return result
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	IdempotencyKey = "idempotency"
+)
+
+type repository struct {
+	redisClient *redis.Client
+}
+
+func NewRepository(redisClient *redis.Client) interfaces.IdempotencyRepository {
+	return &repository{
+		redisClient: redisClient,
+	}
+}
+
+func getIdempotencyKey(userID uuid.UUID, key string) string {
+	return IdempotencyKey + ":" + userID.String() + ":" + key
+}
+
+type idempotencyRecord struct {
+	Fingerprint string `msgpack:"fingerprint"`
+	Completed   bool   `msgpack:"completed"`
+	StatusCode  int    `msgpack:"status_code"`
+	Body        []byte `msgpack:"body"`
+}
+
+func marshalRecord(record entity.IdempotencyRecord) ([]byte, error) {
+	data, err := msgpack.Marshal(idempotencyRecord{
+		Fingerprint: record.Fingerprint,
+		Completed:   record.Completed,
+		StatusCode:  record.StatusCode,
+		Body:        record.Body,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't marshal idempotency record")
+	}
+
+	return data, nil
+}
+
+func (r *repository) Reserve(ctx context.Context, userID uuid.UUID, key string, record entity.IdempotencyRecord, ttl time.Duration) (*entity.IdempotencyRecord, bool, error) {
+	data, err := marshalRecord(record)
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+
+	// SET NX GET reserves the key and reads the existing record in one step, so
+	// the record can't expire in between
+	stored, err := r.redisClient.SetArgs(ctx, getIdempotencyKey(userID, key), data, redis.SetArgs{
+		Mode: "NX",
+		TTL:  ttl,
+		Get:  true,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrap(err, "can't reserve idempotency key")
+	}
+
+	existing := &idempotencyRecord{}
+	if err := msgpack.Unmarshal([]byte(stored), existing); err != nil {
+		return nil, false, errors.Wrap(err, "can't unmarshal idempotency record")
+	}
+
+	return &entity.IdempotencyRecord{
+		Fingerprint: existing.Fingerprint,
+		Completed:   existing.Completed,
+		StatusCode:  existing.StatusCode,
+		Body:        existing.Body,
+	}, false, nil
+}
+
+func (r *repository) Save(ctx context.Context, userID uuid.UUID, key string, record entity.IdempotencyRecord, ttl time.Duration) error {
+	data, err := marshalRecord(record)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := r.redisClient.Set(ctx, getIdempotencyKey(userID, key), data, ttl).Err(); err != nil {
+		return errors.Wrap(err, "can't save idempotency record")
+	}
+
+	return nil
+}
+
+func (r *repository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	if err := r.redisClient.Del(ctx, getIdempotencyKey(userID, key)).Err(); err != nil {
+		return errors.Wrap(err, "can't release idempotency key")
+	}
+
+	return nil
+}
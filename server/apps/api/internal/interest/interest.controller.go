@@ -0,0 +1,296 @@
+package interest
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// Bound the days paid out in one go, as payouts hold the account lock
+	maxBackdateYears = 5
+)
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(interestUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        interestUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/interest", h.GetInterestRule)
+	r.Put("/:id/interest", h.SetInterestRule)
+	r.Delete("/:id/interest", h.DeleteInterestRule)
+	r.Get("/:id/interest/accrued", h.GetAccrued)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrNotSavingAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a saving account",
+		})
+	case errors.Is(err, entity.ErrInterestRuleNotFound):
+		return true, ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Interest rule not set",
+		})
+	case errors.Is(err, entity.ErrInvalidInterestTiers):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Tiers must start at 0, ascend by minBalance and have non-negative rates",
+		})
+	case errors.Is(err, entity.ErrInvalidPayoutFrequency):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid payout frequency",
+		})
+	case errors.Is(err, entity.ErrStartDateTooEarly):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "startDate must not be before the account's first transaction",
+		})
+	}
+
+	return false, nil
+}
+
+type interestTierDTO struct {
+	MinBalance decimal.Decimal `json:"minBalance"`
+	Rate       decimal.Decimal `json:"rate"`
+}
+
+type interestRuleResponse struct {
+	AccountID          uuid.UUID              `json:"accountId"`
+	Tiers              []interestTierDTO      `json:"tiers"`
+	Payout             entity.PayoutFrequency `json:"payout"`
+	WithholdingTaxRate decimal.Decimal        `json:"withholdingTaxRate"`
+	PaidUntil          string                 `json:"paidUntil"`
+	NextPayout         string                 `json:"nextPayout"`
+	CreatedAt          int64                  `json:"createdAt"`
+	UpdatedAt          int64                  `json:"updatedAt"`
+}
+
+func newInterestRuleResponse(rule *entity.InterestRule) interestRuleResponse {
+	response := interestRuleResponse{
+		AccountID:          rule.AccountID,
+		Tiers:              make([]interestTierDTO, 0, len(rule.Tiers)),
+		Payout:             rule.Payout,
+		WithholdingTaxRate: rule.WithholdingTaxRate,
+		PaidUntil:          rule.PaidUntil.Format(dateLayout),
+		NextPayout:         rule.Payout.NextPayout(rule.PaidUntil).Format(dateLayout),
+		CreatedAt:          rule.CreatedAt.Unix(),
+		UpdatedAt:          rule.UpdatedAt.Unix(),
+	}
+	for _, t := range rule.Tiers {
+		response.Tiers = append(response.Tiers, interestTierDTO{
+			MinBalance: t.MinBalance,
+			Rate:       t.Rate,
+		})
+	}
+
+	return response
+}
+
+func (h *controller) GetInterestRule(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	rule, err := h.usecase.GetInterestRule(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get interest rule")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newInterestRuleResponse(rule),
+	})
+}
+
+type setInterestRuleRequest struct {
+	Id                 uuid.UUID              `params:"id"`
+	Tiers              []interestTierDTO      `json:"tiers"`
+	Payout             entity.PayoutFrequency `json:"payout"`
+	WithholdingTaxRate decimal.Decimal        `json:"withholdingTaxRate"`
+	StartDate          string                 `json:"startDate"` // 2006-01-02, defaults to today
+
+	startDate time.Time
+}
+
+func (a *setInterestRuleRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if a.Payout == "" {
+		a.Payout = entity.PayoutFrequencySemiAnnual
+	}
+
+	a.startDate = today(time.Now())
+	if a.StartDate != "" {
+		startDate, err := time.Parse(dateLayout, a.StartDate)
+		if err != nil {
+			return errors.Wrap(err, "invalid startDate")
+		}
+		a.startDate = startDate
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setInterestRuleRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(len(a.Tiers) > 0, "tiers is required")
+	v.Must(!a.startDate.Before(today(time.Now()).AddDate(-maxBackdateYears, 0, 0)), "startDate must be within the last 5 years")
+	v.Must(!a.WithholdingTaxRate.IsNegative() && a.WithholdingTaxRate.LessThanOrEqual(decimal.NewFromInt(100)), "withholdingTaxRate must be between 0 and 100")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) SetInterestRule(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setInterestRuleRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	tiers := make([]entity.InterestTier, 0, len(req.Tiers))
+	for _, t := range req.Tiers {
+		tiers = append(tiers, entity.InterestTier{
+			MinBalance: t.MinBalance,
+			Rate:       t.Rate,
+		})
+	}
+
+	rule, err := h.usecase.SetInterestRule(ctx.UserContext(), entity.InterestRuleInput{
+		UserID:             userID,
+		AccountID:          req.Id,
+		Tiers:              tiers,
+		Payout:             req.Payout,
+		WithholdingTaxRate: req.WithholdingTaxRate,
+		StartDate:          req.startDate,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set interest rule")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newInterestRuleResponse(rule),
+	})
+}
+
+func (h *controller) DeleteInterestRule(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	err = h.usecase.DeleteInterestRule(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to delete interest rule")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "Interest rule deleted",
+	})
+}
+
+type accruedResponse struct {
+	PeriodStart string          `json:"periodStart"`
+	Days        int             `json:"days"`
+	Gross       decimal.Decimal `json:"gross"`
+	Tax         decimal.Decimal `json:"tax"`
+	Net         decimal.Decimal `json:"net"`
+}
+
+func (h *controller) GetAccrued(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	accrual, err := h.usecase.GetAccrued(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get accrued interest")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: accruedResponse{
+			PeriodStart: accrual.PeriodStart.Format(dateLayout),
+			Days:        accrual.Days,
+			Gross:       accrual.Gross,
+			Tax:         accrual.Tax,
+			Net:         accrual.Net,
+		},
+	})
+}
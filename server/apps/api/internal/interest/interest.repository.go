@@ -0,0 +1,157 @@
+package interest
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.InterestRuleRepository {
+	db.AutoMigrate(&model.InterestRule{}, &model.InterestTier{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func preloadTiers(db *gorm.DB) *gorm.DB {
+	return db.Order("min_balance asc")
+}
+
+func toEntity(rule *model.InterestRule) *entity.InterestRule {
+	tiers := make([]entity.InterestTier, 0, len(rule.Tiers))
+	for _, t := range rule.Tiers {
+		tiers = append(tiers, entity.InterestTier{
+			MinBalance: t.MinBalance,
+			Rate:       t.Rate,
+		})
+	}
+
+	return &entity.InterestRule{
+		ID:                 rule.ID,
+		AccountID:          rule.AccountID,
+		Tiers:              tiers,
+		Payout:             rule.Payout,
+		WithholdingTaxRate: rule.WithholdingTaxRate,
+		PaidUntil:          rule.PaidUntil,
+		CreatedAt:          rule.CreatedAt,
+		UpdatedAt:          rule.UpdatedAt,
+	}
+}
+
+func (r *repository) GetInterestRule(ctx context.Context, accountID uuid.UUID) (*entity.InterestRule, error) {
+	var rule model.InterestRule
+	err := r.getDB(ctx).Preload("Tiers", preloadTiers).Where("account_id = ?", accountID).First(&rule).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(entity.ErrInterestRuleNotFound)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get interest rule")
+	}
+
+	return toEntity(&rule), nil
+}
+
+func (r *repository) GetInterestRules(ctx context.Context) ([]entity.InterestRule, error) {
+	var rules []*model.InterestRule
+	if err := r.getDB(ctx).Preload("Tiers", preloadTiers).Order("created_at asc").Find(&rules).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get interest rules")
+	}
+
+	var result []entity.InterestRule
+	for _, rule := range rules {
+		result = append(result, *toEntity(rule))
+	}
+
+	return result, nil
+}
+
+func (r *repository) SaveInterestRule(ctx context.Context, input entity.InterestRule) (*entity.InterestRule, error) {
+	var rule model.InterestRule
+	err := r.getDB(ctx).Where("account_id = ?", input.AccountID).First(&rule).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get interest rule")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		rule = model.InterestRule{
+			ID:        uuid.New(),
+			AccountID: input.AccountID,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	rule.Payout = input.Payout
+	rule.WithholdingTaxRate = input.WithholdingTaxRate
+	rule.PaidUntil = input.PaidUntil
+	rule.UpdatedAt = time.Now()
+
+	rule.Tiers = make([]model.InterestTier, 0, len(input.Tiers))
+	for _, t := range input.Tiers {
+		rule.Tiers = append(rule.Tiers, model.InterestTier{
+			ID:         uuid.New(),
+			RuleID:     rule.ID,
+			MinBalance: t.MinBalance,
+			Rate:       t.Rate,
+		})
+	}
+
+	err = r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tiers").Save(&rule).Error; err != nil {
+			return errors.Wrap(err, "failed to save interest rule")
+		}
+
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&model.InterestTier{}).Error; err != nil {
+			return errors.Wrap(err, "failed to delete interest tiers")
+		}
+
+		if len(rule.Tiers) > 0 {
+			if err := tx.Create(&rule.Tiers).Error; err != nil {
+				return errors.Wrap(err, "failed to create interest tiers")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return toEntity(&rule), nil
+}
+
+func (r *repository) DeleteInterestRule(ctx context.Context, accountID uuid.UUID) error {
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var rule model.InterestRule
+		if err := tx.Where("account_id = ?", accountID).First(&rule).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.WithStack(entity.ErrInterestRuleNotFound)
+			}
+			return errors.Wrap(err, "failed to get interest rule")
+		}
+
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&model.InterestTier{}).Error; err != nil {
+			return errors.Wrap(err, "failed to delete interest tiers")
+		}
+
+		if err := tx.Delete(&rule).Error; err != nil {
+			return errors.Wrap(err, "failed to delete interest rule")
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,319 @@
+package interest
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	Interval int64 `mapstructure:"interval"` // Seconds between interest postings, 0 disables the runner
+}
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	interestRepo    interfaces.InterestRuleRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(txManager interfaces.TxManager, interestRepo interfaces.InterestRuleRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		interestRepo:    interestRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// today is the start of the current day, the boundary between accrued days.
+func today(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (u *usecase) getSavingAccount(ctx context.Context, userID, accountID uuid.UUID) (*entity.Account, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type != entity.AccountTypeSaving {
+		return nil, errors.WithStack(entity.ErrNotSavingAccount)
+	}
+
+	return account, nil
+}
+
+func (u *usecase) GetInterestRule(ctx context.Context, userID, accountID uuid.UUID) (*entity.InterestRule, error) {
+	if _, err := u.getSavingAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	rule, err := u.interestRepo.GetInterestRule(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get interest rule")
+	}
+
+	return rule, nil
+}
+
+// SetInterestRule sets or replaces the rule of the account and posts the
+// payouts already due when it starts in the past. Periods paid under an
+// earlier rule are not paid again.
+func (u *usecase) SetInterestRule(ctx context.Context, input entity.InterestRuleInput) (*entity.InterestRule, error) {
+	if err := entity.ValidateTiers(input.Tiers); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !input.Payout.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidPayoutFrequency)
+	}
+
+	if _, err := u.getSavingAccount(ctx, input.UserID, input.AccountID); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Interest can't accrue before the money was deposited
+	first, err := u.transactionRepo.GetFirstTransactionAt(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get first transaction")
+	}
+
+	if !first.IsZero() && input.StartDate.Before(today(first)) {
+		return nil, errors.WithStack(entity.ErrStartDateTooEarly)
+	}
+
+	rule := entity.InterestRule{
+		AccountID:          input.AccountID,
+		Tiers:              input.Tiers,
+		Payout:             input.Payout,
+		WithholdingTaxRate: input.WithholdingTaxRate,
+		PaidUntil:          input.StartDate,
+	}
+
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := u.accountRepo.LockAccount(ctx, input.AccountID); err != nil {
+			return errors.Wrap(err, "failed to lock account")
+		}
+
+		current, err := u.interestRepo.GetInterestRule(ctx, input.AccountID)
+		if err != nil && !errors.Is(err, entity.ErrInterestRuleNotFound) {
+			return errors.Wrap(err, "failed to get interest rule")
+		}
+		if current != nil && current.PaidUntil.After(rule.PaidUntil) {
+			rule.PaidUntil = current.PaidUntil
+		}
+
+		if _, err := u.interestRepo.SaveInterestRule(ctx, rule); err != nil {
+			return errors.Wrap(err, "failed to save interest rule")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := u.post(ctx, input.AccountID, time.Now()); err != nil {
+		return nil, errors.Wrap(err, "failed to post interest")
+	}
+
+	return u.GetInterestRule(ctx, input.UserID, input.AccountID)
+}
+
+func (u *usecase) DeleteInterestRule(ctx context.Context, userID, accountID uuid.UUID) error {
+	if _, err := u.getSavingAccount(ctx, userID, accountID); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := u.interestRepo.DeleteInterestRule(ctx, accountID); err != nil {
+		return errors.Wrap(err, "failed to delete interest rule")
+	}
+
+	return nil
+}
+
+// GetAccrued returns the interest accrued since the last payout, today's
+// balance included.
+func (u *usecase) GetAccrued(ctx context.Context, userID, accountID uuid.UUID) (*entity.InterestAccrual, error) {
+	rule, err := u.GetInterestRule(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	accrual, err := u.accrue(ctx, rule, rule.PaidUntil, today(time.Now()).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return accrual, nil
+}
+
+// PostAll pays the interest of every period that ended. A failing account is
+// logged and retried on the next run.
+func (u *usecase) PostAll(ctx context.Context) error {
+	rules, err := u.interestRepo.GetInterestRules(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get interest rules")
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		if err := u.post(ctx, rule.AccountID, now); err != nil {
+			logger.ErrorContext(ctx, "failed to post savings interest",
+				slog.String("account_id", rule.AccountID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+// post pays every period of the rule that ended by now. Each payout is an
+// INTEREST deposit of the gross interest and a WITHHOLDING_TAX withdrawal of
+// the tax on the cashbox pocket, dated on the payout date.
+func (u *usecase) post(ctx context.Context, accountID uuid.UUID, now time.Time) error {
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Lock the account so concurrent runs don't pay the same period twice
+		if _, err := u.accountRepo.LockAccount(ctx, accountID); err != nil {
+			return errors.Wrap(err, "failed to lock account")
+		}
+
+		rule, err := u.interestRepo.GetInterestRule(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get interest rule")
+		}
+
+		paidUntil := rule.PaidUntil
+		for {
+			payout := rule.Payout.NextPayout(rule.PaidUntil)
+			if payout.After(today(now)) {
+				break
+			}
+
+			accrual, err := u.accrue(ctx, rule, rule.PaidUntil, payout)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if err := u.pay(ctx, accountID, accrual); err != nil {
+				return errors.WithStack(err)
+			}
+			rule.PaidUntil = payout
+		}
+
+		if rule.PaidUntil.Equal(paidUntil) {
+			return nil
+		}
+
+		if _, err := u.interestRepo.SaveInterestRule(ctx, *rule); err != nil {
+			return errors.Wrap(err, "failed to save interest rule")
+		}
+
+		return nil
+	})
+}
+
+// accrue sums the daily interest on each day's closing balance in [from, to).
+// A negative balance earns nothing.
+func (u *usecase) accrue(ctx context.Context, rule *entity.InterestRule, from, to time.Time) (*entity.InterestAccrual, error) {
+	balance, err := u.transactionRepo.GetAccountLedgerBalanceAt(ctx, rule.AccountID, from)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get opening balance")
+	}
+
+	postings, err := u.transactionRepo.GetAccountPostings(ctx, rule.AccountID, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get postings")
+	}
+
+	accrual := entity.InterestAccrual{
+		PeriodStart: from,
+		PeriodEnd:   to,
+	}
+
+	interest := decimal.Zero
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		for len(postings) > 0 && postings[0].CreatedAt.Before(next) {
+			balance = balance.Add(postings[0].Amount)
+			postings = postings[1:]
+		}
+
+		interest = interest.Add(rule.DailyInterest(decimal.Max(balance, decimal.Zero)))
+		accrual.Days++
+	}
+
+	accrual.Gross = interest.Round(2)
+	accrual.Tax = accrual.Gross.Mul(rule.WithholdingTaxRate).Div(decimal.NewFromInt(100)).Round(2)
+	accrual.Net = accrual.Gross.Sub(accrual.Tax)
+
+	return &accrual, nil
+}
+
+func (u *usecase) pay(ctx context.Context, accountID uuid.UUID, accrual *entity.InterestAccrual) error {
+	if !accrual.Gross.IsPositive() {
+		return nil
+	}
+
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+		AccountID:  accountID,
+		ToPocketID: &cashbox.ID,
+		Type:       entity.TxTypeInterest,
+		Amount:     accrual.Gross,
+		Note:       "Savings interest " + accrual.PeriodStart.Format(dateLayout) + " to " + accrual.PeriodEnd.AddDate(0, 0, -1).Format(dateLayout),
+		CreatedAt:  accrual.PeriodEnd,
+	}); err != nil {
+		return errors.Wrap(err, "failed to create interest transaction")
+	}
+
+	if err := u.accountRepo.Deposit(ctx, accountID, accrual.Gross); err != nil {
+		return errors.Wrap(err, "failed to deposit to account")
+	}
+
+	if err := u.pocketRepo.Deposit(ctx, cashbox.ID, accrual.Gross); err != nil {
+		return errors.Wrap(err, "failed to deposit to cashbox pocket")
+	}
+
+	if !accrual.Tax.IsPositive() {
+		return nil
+	}
+
+	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+		AccountID:    accountID,
+		FromPocketID: &cashbox.ID,
+		Type:         entity.TxTypeWithholdingTax,
+		Amount:       accrual.Tax,
+		Note:         "Withholding tax on savings interest",
+		CreatedAt:    accrual.PeriodEnd,
+	}); err != nil {
+		return errors.Wrap(err, "failed to create withholding tax transaction")
+	}
+
+	if err := u.accountRepo.Withdraw(ctx, accountID, accrual.Tax); err != nil {
+		return errors.Wrap(err, "failed to withdraw from account")
+	}
+
+	if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, accrual.Tax); err != nil {
+		return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+	}
+
+	return nil
+}
@@ -9,12 +9,26 @@ import (
 )
 
 type AccountRepository interface {
+	GetAccounts(ctx context.Context) ([]entity.Account, error)
 	GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]entity.Account, error)
 	GetUserAccount(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Account, error)
+	// GetAccount is for background jobs, which have no user to check ownership against.
+	GetAccount(ctx context.Context, id uuid.UUID) (*entity.Account, error)
 	CreateAccount(ctx context.Context, input entity.AccountInput) (*entity.Account, error)
 	UpdateAccount(ctx context.Context, id uuid.UUID, input entity.AccountInput) (*entity.Account, error)
 	DeleteAccount(ctx context.Context, id uuid.UUID) error
+	// ReorderAccounts puts the given accounts of the user first, in that order.
+	ReorderAccounts(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) error
 
+	// Balance mutations lock the account row until the surrounding transaction
+	// ends. Within one transaction, accounts must be locked before pockets.
 	Deposit(ctx context.Context, id uuid.UUID, amount decimal.Decimal) error
+	Withdraw(ctx context.Context, id uuid.UUID, amount decimal.Decimal) error
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal) error
 	UpdateBalance(ctx context.Context, id uuid.UUID, amount decimal.Decimal) (account *entity.Account, differenceBalance decimal.Decimal, err error)
+	LockAccount(ctx context.Context, id uuid.UUID) (*entity.Account, error)
+	// LockAccounts locks the accounts in id order, so use it instead of several
+	// LockAccount calls when one transaction needs more than one account.
+	LockAccounts(ctx context.Context, ids ...uuid.UUID) (map[uuid.UUID]*entity.Account, error)
+	SetBalance(ctx context.Context, id uuid.UUID, balance decimal.Decimal) error
 }
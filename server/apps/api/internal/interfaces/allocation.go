@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type AllocationUsecase interface {
+	// Allocate splits amount, just deposited to the cashbox, into the pockets of
+	// its account. It must run in the transaction of the deposit, after the
+	// account and all its pockets have been locked.
+	Allocate(ctx context.Context, cashbox *entity.Pocket, amount decimal.Decimal) (*entity.AllocationPlan, error)
+}
+
+type AllocationRuleRepository interface {
+	// GetAllocationRules returns the rules of the account by ascending priority.
+	GetAllocationRules(ctx context.Context, accountID uuid.UUID) ([]entity.AllocationRule, error)
+	// SetAllocationRules replaces the rules of the account.
+	SetAllocationRules(ctx context.Context, accountID uuid.UUID, rules []entity.AllocationRule) ([]entity.AllocationRule, error)
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type BudgetRepository interface {
+	// GetBudgets returns the budgets of the pockets starting in or before until,
+	// oldest first.
+	GetBudgets(ctx context.Context, pocketIDs []uuid.UUID, until time.Time) ([]entity.Budget, error)
+	// SaveBudget creates or replaces the budget of the pocket for the month.
+	SaveBudget(ctx context.Context, budget entity.Budget) (*entity.Budget, error)
+	DeleteBudget(ctx context.Context, pocketID uuid.UUID, month time.Time) error
+}
@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type CurrencyUsecase interface {
+	GetBaseCurrency(ctx context.Context, userID uuid.UUID) (string, error)
+	// ToBase values amount in the base currency. The base amount is left nil when
+	// there is no exchange rate for the currency.
+	ToBase(ctx context.Context, baseCurrency string, amount decimal.Decimal, currency string, date time.Time) (entity.Money, error)
+}
+
+type ExchangeRateRepository interface {
+	// GetRate returns the latest rate from one currency to another published on
+	// or before date, or entity.ErrExchangeRateNotFound.
+	GetRate(ctx context.Context, from, to string, date time.Time) (*entity.ExchangeRate, error)
+	// SaveRates inserts the rates, replacing any stored for the same pair and date.
+	SaveRates(ctx context.Context, rates []entity.ExchangeRate) error
+}
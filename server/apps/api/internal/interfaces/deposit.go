@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FixedDepositRepository interface {
+	GetFixedDeposit(ctx context.Context, accountID uuid.UUID) (*entity.FixedDeposit, error)
+	GetActiveFixedDeposits(ctx context.Context) ([]entity.FixedDeposit, error)
+	// SaveFixedDeposit creates or replaces the term of fd.AccountID.
+	SaveFixedDeposit(ctx context.Context, fd entity.FixedDeposit) (*entity.FixedDeposit, error)
+}
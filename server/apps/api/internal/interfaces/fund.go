@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type FundRepository interface {
+	// GetFundOrders returns the account's orders oldest first.
+	GetFundOrders(ctx context.Context, accountID uuid.UUID) ([]entity.FundOrder, error)
+	CreateFundOrder(ctx context.Context, order entity.FundOrder) (*entity.FundOrder, error)
+}
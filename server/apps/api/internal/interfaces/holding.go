@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type HoldingRepository interface {
+	// GetTrades returns the account's trades oldest first.
+	GetTrades(ctx context.Context, accountID uuid.UUID) ([]entity.Trade, error)
+	CreateTrade(ctx context.Context, trade entity.Trade) (*entity.Trade, error)
+
+	GetLots(ctx context.Context, accountID uuid.UUID, symbol string) ([]entity.Lot, error)
+	CreateLot(ctx context.Context, lot entity.Lot) (*entity.Lot, error)
+	// ConsumeLots takes quantity out of the open lots of symbol, oldest first,
+	// and returns the cost of the consumed quantity.
+	ConsumeLots(ctx context.Context, accountID uuid.UUID, symbol string, quantity decimal.Decimal) (decimal.Decimal, error)
+}
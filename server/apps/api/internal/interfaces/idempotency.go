@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type IdempotencyRepository interface {
+	// Reserve stores record under key if the key is unused. Otherwise it returns
+	// the record already stored and reserved is false.
+	Reserve(ctx context.Context, userID uuid.UUID, key string, record entity.IdempotencyRecord, ttl time.Duration) (existing *entity.IdempotencyRecord, reserved bool, err error)
+	Save(ctx context.Context, userID uuid.UUID, key string, record entity.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
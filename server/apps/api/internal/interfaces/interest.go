@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type InterestRuleRepository interface {
+	GetInterestRule(ctx context.Context, accountID uuid.UUID) (*entity.InterestRule, error)
+	GetInterestRules(ctx context.Context) ([]entity.InterestRule, error)
+	// SaveInterestRule creates or replaces the rule of rule.AccountID, tiers included.
+	SaveInterestRule(ctx context.Context, rule entity.InterestRule) (*entity.InterestRule, error)
+	DeleteInterestRule(ctx context.Context, accountID uuid.UUID) error
+}
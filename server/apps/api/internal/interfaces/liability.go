@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type LiabilityRepository interface {
+	GetCreditCard(ctx context.Context, accountID uuid.UUID) (*entity.CreditCard, error)
+	// SaveCreditCard creates or replaces the terms of card.AccountID.
+	SaveCreditCard(ctx context.Context, card entity.CreditCard) (*entity.CreditCard, error)
+	GetLoan(ctx context.Context, accountID uuid.UUID) (*entity.Loan, error)
+	// SaveLoan creates or replaces the terms of loan.AccountID.
+	SaveLoan(ctx context.Context, loan entity.Loan) (*entity.Loan, error)
+}
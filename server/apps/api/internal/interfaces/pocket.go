@@ -11,11 +11,19 @@ import (
 type PocketRepository interface {
 	GetPocketByID(ctx context.Context, userID uuid.UUID, pocketID uuid.UUID) (*entity.Pocket, error)
 	GetPocketsByAccountID(ctx context.Context, accountID uuid.UUID) ([]entity.Pocket, error)
+	// GetCashboxPocket returns the cashbox of the account, or entity.ErrCashboxNotFound.
+	GetCashboxPocket(ctx context.Context, accountID uuid.UUID) (*entity.Pocket, error)
 	CreatePocket(ctx context.Context, input entity.PocketInput) (*entity.Pocket, error)
 	UpdatePocket(ctx context.Context, id uuid.UUID, input entity.PocketInput) (*entity.Pocket, error)
+	SetPocketGoal(ctx context.Context, pocketID uuid.UUID, goal *entity.PocketGoal) (*entity.Pocket, error)
 	DeletePocket(ctx context.Context, pocketID uuid.UUID) error
+	// ReorderPockets puts the given pockets of the account first, in that order.
+	ReorderPockets(ctx context.Context, accountID uuid.UUID, ids []uuid.UUID) error
 
+	// Balance mutations lock the pocket rows, ordered by id, until the
+	// surrounding transaction ends.
 	Deposit(ctx context.Context, pocketID uuid.UUID, amount decimal.Decimal) error
 	Transfer(ctx context.Context, fromPocketID, toPocketID uuid.UUID, amount decimal.Decimal) error
 	Withdraw(ctx context.Context, pocketID uuid.UUID, amount decimal.Decimal) error
+	LockPocketsByAccountID(ctx context.Context, accountID uuid.UUID) ([]entity.Pocket, error)
 }
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+)
+
+type PriceProvider interface {
+	// GetPrice returns the latest price of symbol published on or before date,
+	// or entity.ErrPriceNotFound.
+	GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error)
+}
+
+type PriceRepository interface {
+	GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error)
+	// SavePrices inserts the prices, replacing any stored for the same symbol and date.
+	SavePrices(ctx context.Context, prices []entity.Price) error
+	// GetTrackedSymbols returns every symbol that is traded, held in a fund or priced.
+	GetTrackedSymbols(ctx context.Context) ([]string, error)
+}
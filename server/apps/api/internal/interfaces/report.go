@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type ReportRepository interface {
+	GetAllocationTargets(ctx context.Context, userID uuid.UUID) ([]entity.AllocationTarget, error)
+	// SetAllocationTargets replaces the user's targets of the dimension.
+	SetAllocationTargets(ctx context.Context, userID uuid.UUID, dimension entity.AllocationDimension, targets []entity.AllocationTarget) error
+}
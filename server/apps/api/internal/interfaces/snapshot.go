@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/google/uuid"
+)
+
+type SnapshotRepository interface {
+	// SaveDailySnapshot replaces what was recorded for the user on the same day.
+	SaveDailySnapshot(ctx context.Context, snapshot entity.DailySnapshot) error
+	// GetAccountSnapshots returns the user's account snapshots in [from, to], oldest first.
+	GetAccountSnapshots(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]entity.AccountSnapshot, error)
+	GetNetWorthSnapshots(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]entity.NetWorthSnapshot, error)
+}
@@ -2,12 +2,41 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type TransactionRepository interface {
-	GetTransactionByAccountID(ctx context.Context, userID uuid.UUID, pocketID uuid.UUID) ([]entity.Transaction, error)
+	GetTransactionByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Transaction, error)
+	// LockTransaction locks the transaction row until the surrounding tx ends.
+	LockTransaction(ctx context.Context, id uuid.UUID) error
+	IsReversed(ctx context.Context, id uuid.UUID) (bool, error)
+	// GetFirstTransactionAt returns when money first moved in or out of the
+	// account, the zero time when it never did.
+	GetFirstTransactionAt(ctx context.Context, accountID uuid.UUID) (time.Time, error)
+	// GetFeeTransaction returns the fee charged for the transfer, nil when none was.
+	GetFeeTransaction(ctx context.Context, transactionID uuid.UUID) (*entity.Transaction, error)
+	GetTransactionByAccountID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]entity.Transaction, error)
+	GetTransactions(ctx context.Context, filter entity.TransactionFilter) (*entity.TransactionPage, error)
+	GetPocketTransactions(ctx context.Context, pocketID uuid.UUID) ([]entity.PocketTransaction, error)
+	// CreateTransaction records the transaction together with its balanced postings.
 	CreateTransaction(ctx context.Context, transaction entity.TransactionInput) (*entity.Transaction, error)
+
+	GetPostingsByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]entity.Posting, error)
+	GetPocketLedgerBalance(ctx context.Context, pocketID uuid.UUID) (decimal.Decimal, error)
+	GetAccountLedgerBalance(ctx context.Context, accountID uuid.UUID) (decimal.Decimal, error)
+	// GetOrphanedPostings counts and sums the account's postings whose pocket no
+	// longer exists.
+	GetOrphanedPostings(ctx context.Context, accountID uuid.UUID) (int64, decimal.Decimal, error)
+	// GetAccountLedgerBalanceAt sums the account's pocket postings created before at.
+	GetAccountLedgerBalanceAt(ctx context.Context, accountID uuid.UUID, at time.Time) (decimal.Decimal, error)
+	// GetAccountPostings returns the account's pocket postings created in [from, to), oldest first.
+	GetAccountPostings(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]entity.Posting, error)
+	// GetPocketSpending sums the withdrawals from the pockets per UTC month of [from, to).
+	GetPocketSpending(ctx context.Context, pocketIDs []uuid.UUID, from, to time.Time) ([]entity.PocketSpending, error)
+	BackfillPostings(ctx context.Context) (int, error)
+	BackfillTypes(ctx context.Context) (int64, error)
 }
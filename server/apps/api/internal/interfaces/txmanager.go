@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+)
+
+type TxManager interface {
+	// WithTx runs fn inside a database transaction. Repositories called with the
+	// context passed to fn join the same transaction, which is committed when fn
+	// returns nil and rolled back otherwise. Nested calls join the outer transaction.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
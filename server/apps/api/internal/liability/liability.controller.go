@@ -0,0 +1,534 @@
+package liability
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+type controller struct {
+	usecase               *usecase
+	authMiddleware        authentication.AuthMiddleware
+	idempotencyMiddleware idempotency.IdempotencyMiddleware
+}
+
+func NewController(liabilityUsecase *usecase, authMiddleware authentication.AuthMiddleware, idempotencyMiddleware idempotency.IdempotencyMiddleware) *controller {
+	return &controller{
+		usecase:               liabilityUsecase,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/credit-card", h.GetCreditCard)
+	r.Put("/:id/credit-card", h.SetCreditCard)
+	r.Get("/:id/credit-card/statement", h.GetStatement)
+	r.Get("/:id/loan", h.GetLoan)
+	r.Put("/:id/loan", h.SetLoan)
+	r.Get("/:id/loan/schedule", h.GetSchedule)
+	r.Post("/:id/pay", h.idempotencyMiddleware.Handle, h.Pay)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrNotCreditCardAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a credit card account",
+		})
+	case errors.Is(err, entity.ErrNotLoanAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a loan account",
+		})
+	case errors.Is(err, entity.ErrNotLiabilityAccount):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Account is not a credit card or loan account",
+		})
+	case errors.Is(err, entity.ErrCreditCardNotFound):
+		return true, ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Credit card terms not set",
+		})
+	case errors.Is(err, entity.ErrLoanNotFound):
+		return true, ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Loan terms not set",
+		})
+	case errors.Is(err, entity.ErrInvalidPaymentPocket):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Payments must come from a pocket of an asset account",
+		})
+	case errors.Is(err, entity.ErrCurrencyMismatch):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Pocket must have the same currency as the account",
+		})
+	case errors.Is(err, entity.ErrPaymentExceedsOutstanding):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Payment exceeds the outstanding balance",
+		})
+	case errors.Is(err, entity.ErrInsufficientBalance):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	}
+
+	return false, nil
+}
+
+type creditCardResponse struct {
+	AccountID        uuid.UUID       `json:"accountId"`
+	CreditLimit      decimal.Decimal `json:"creditLimit"`
+	StatementDay     int             `json:"statementDay"`
+	DueDays          int             `json:"dueDays"`
+	MinPaymentRate   decimal.Decimal `json:"minPaymentRate"`
+	MinPaymentAmount decimal.Decimal `json:"minPaymentAmount"`
+	CreatedAt        int64           `json:"createdAt"`
+	UpdatedAt        int64           `json:"updatedAt"`
+}
+
+func newCreditCardResponse(c *entity.CreditCard) creditCardResponse {
+	return creditCardResponse{
+		AccountID:        c.AccountID,
+		CreditLimit:      c.CreditLimit,
+		StatementDay:     c.StatementDay,
+		DueDays:          c.DueDays,
+		MinPaymentRate:   c.MinPaymentRate,
+		MinPaymentAmount: c.MinPaymentAmount,
+		CreatedAt:        c.CreatedAt.Unix(),
+		UpdatedAt:        c.UpdatedAt.Unix(),
+	}
+}
+
+func (h *controller) GetCreditCard(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	card, err := h.usecase.GetCreditCard(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get credit card")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newCreditCardResponse(card),
+	})
+}
+
+type setCreditCardRequest struct {
+	Id               uuid.UUID       `params:"id"`
+	CreditLimit      decimal.Decimal `json:"creditLimit"`
+	StatementDay     int             `json:"statementDay"`
+	DueDays          int             `json:"dueDays"`
+	MinPaymentRate   decimal.Decimal `json:"minPaymentRate"`
+	MinPaymentAmount decimal.Decimal `json:"minPaymentAmount"`
+}
+
+func (a *setCreditCardRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setCreditCardRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(!a.CreditLimit.IsNegative(), "creditLimit must not be negative")
+	v.Must(a.StatementDay >= 1 && a.StatementDay <= 28, "statementDay must be between 1 and 28")
+	v.Must(a.DueDays >= 0, "dueDays must not be negative")
+	v.Must(!a.MinPaymentRate.IsNegative() && a.MinPaymentRate.LessThanOrEqual(decimal.NewFromInt(100)), "minPaymentRate must be between 0 and 100")
+	v.Must(!a.MinPaymentAmount.IsNegative(), "minPaymentAmount must not be negative")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) SetCreditCard(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setCreditCardRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	card, err := h.usecase.SetCreditCard(ctx.UserContext(), entity.CreditCardInput{
+		UserID:           userID,
+		AccountID:        req.Id,
+		CreditLimit:      req.CreditLimit,
+		StatementDay:     req.StatementDay,
+		DueDays:          req.DueDays,
+		MinPaymentRate:   req.MinPaymentRate,
+		MinPaymentAmount: req.MinPaymentAmount,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set credit card")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newCreditCardResponse(card),
+	})
+}
+
+type statementResponse struct {
+	StatementDate      string          `json:"statementDate"`
+	DueDate            string          `json:"dueDate"`
+	StatementBalance   decimal.Decimal `json:"statementBalance"`
+	PaidSinceStatement decimal.Decimal `json:"paidSinceStatement"`
+	MinimumPayment     decimal.Decimal `json:"minimumPayment"`
+	Outstanding        decimal.Decimal `json:"outstanding"`
+	AvailableCredit    decimal.Decimal `json:"availableCredit"`
+}
+
+func (h *controller) GetStatement(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	statement, err := h.usecase.GetStatement(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get statement")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: statementResponse{
+			StatementDate:      statement.StatementDate.Format(dateLayout),
+			DueDate:            statement.DueDate.Format(dateLayout),
+			StatementBalance:   statement.StatementBalance,
+			PaidSinceStatement: statement.PaidSinceStatement,
+			MinimumPayment:     statement.MinimumPayment,
+			Outstanding:        statement.Outstanding,
+			AvailableCredit:    statement.AvailableCredit,
+		},
+	})
+}
+
+type loanResponse struct {
+	AccountID      uuid.UUID       `json:"accountId"`
+	Principal      decimal.Decimal `json:"principal"`
+	AnnualRate     decimal.Decimal `json:"annualRate"`
+	TermMonths     int             `json:"termMonths"`
+	StartDate      string          `json:"startDate"`
+	MonthlyPayment decimal.Decimal `json:"monthlyPayment"`
+	AccruedUntil   string          `json:"accruedUntil"`
+	CreatedAt      int64           `json:"createdAt"`
+	UpdatedAt      int64           `json:"updatedAt"`
+}
+
+func newLoanResponse(l *entity.Loan) loanResponse {
+	return loanResponse{
+		AccountID:      l.AccountID,
+		Principal:      l.Principal,
+		AnnualRate:     l.AnnualRate,
+		TermMonths:     l.TermMonths,
+		StartDate:      l.StartDate.Format(dateLayout),
+		MonthlyPayment: l.MonthlyPayment(),
+		AccruedUntil:   l.AccruedUntil.Format(dateLayout),
+		CreatedAt:      l.CreatedAt.Unix(),
+		UpdatedAt:      l.UpdatedAt.Unix(),
+	}
+}
+
+func (h *controller) GetLoan(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	loan, err := h.usecase.GetLoan(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get loan")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newLoanResponse(loan),
+	})
+}
+
+type setLoanRequest struct {
+	Id         uuid.UUID       `params:"id"`
+	Principal  decimal.Decimal `json:"principal"`
+	AnnualRate decimal.Decimal `json:"annualRate"`
+	TermMonths int             `json:"termMonths"`
+	StartDate  string          `json:"startDate"` // 2006-01-02, defaults to today
+
+	startDate time.Time
+}
+
+func (a *setLoanRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	a.startDate = today(time.Now())
+	if a.StartDate != "" {
+		startDate, err := time.Parse(dateLayout, a.StartDate)
+		if err != nil {
+			return errors.Wrap(err, "invalid startDate")
+		}
+		a.startDate = startDate
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setLoanRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.Principal.IsPositive(), "principal must be positive")
+	v.Must(!a.AnnualRate.IsNegative(), "annualRate must not be negative")
+	v.Must(a.TermMonths > 0, "termMonths must be positive")
+
+	return errors.WithStack(v.Error())
+}
+
+func (h *controller) SetLoan(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setLoanRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	loan, err := h.usecase.SetLoan(ctx.UserContext(), entity.LoanInput{
+		UserID:     userID,
+		AccountID:  req.Id,
+		Principal:  req.Principal,
+		AnnualRate: req.AnnualRate,
+		TermMonths: req.TermMonths,
+		StartDate:  req.startDate,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set loan")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newLoanResponse(loan),
+	})
+}
+
+type amortizationRowResponse struct {
+	Number    int             `json:"number"`
+	Date      string          `json:"date"`
+	Payment   decimal.Decimal `json:"payment"`
+	Principal decimal.Decimal `json:"principal"`
+	Interest  decimal.Decimal `json:"interest"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+type scheduleResponse struct {
+	MonthlyPayment decimal.Decimal           `json:"monthlyPayment"`
+	TotalInterest  decimal.Decimal           `json:"totalInterest"`
+	TotalPayment   decimal.Decimal           `json:"totalPayment"`
+	Schedule       []amortizationRowResponse `json:"schedule"`
+}
+
+func (h *controller) GetSchedule(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	loan, err := h.usecase.GetLoan(ctx.UserContext(), userID, accountID)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get loan")
+	}
+
+	schedule := loan.Schedule()
+	response := scheduleResponse{
+		MonthlyPayment: loan.MonthlyPayment(),
+		Schedule:       make([]amortizationRowResponse, 0, len(schedule)),
+	}
+	for _, row := range schedule {
+		response.Schedule = append(response.Schedule, amortizationRowResponse{
+			Number:    row.Number,
+			Date:      row.Date.Format(dateLayout),
+			Payment:   row.Payment,
+			Principal: row.Principal,
+			Interest:  row.Interest,
+			Balance:   row.Balance,
+		})
+
+		response.TotalInterest = response.TotalInterest.Add(row.Interest)
+		response.TotalPayment = response.TotalPayment.Add(row.Payment)
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type payRequest struct {
+	Id           uuid.UUID       `params:"id"`
+	FromPocketID uuid.UUID       `json:"fromPocketId"`
+	Amount       decimal.Decimal `json:"amount"`
+	Note         string          `json:"note"`
+}
+
+func (a *payRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *payRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Id != uuid.Nil, "id is required")
+	v.Must(a.FromPocketID != uuid.Nil, "fromPocketId is required")
+	v.Must(a.Amount.IsPositive(), "amount must be positive")
+
+	return errors.WithStack(v.Error())
+}
+
+type paymentResponse struct {
+	TransactionID uuid.UUID       `json:"transactionId"`
+	Amount        decimal.Decimal `json:"amount"`
+	Principal     decimal.Decimal `json:"principal"`
+	Interest      decimal.Decimal `json:"interest"`
+	Outstanding   decimal.Decimal `json:"outstanding"`
+}
+
+func (h *controller) Pay(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req payRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	payment, err := h.usecase.Pay(ctx.UserContext(), entity.LiabilityPaymentInput{
+		UserID:       userID,
+		AccountID:    req.Id,
+		FromPocketID: req.FromPocketID,
+		Amount:       req.Amount,
+		Note:         req.Note,
+	})
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to pay")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: paymentResponse{
+			TransactionID: payment.TransactionID,
+			Amount:        payment.Amount,
+			Principal:     payment.Principal,
+			Interest:      payment.Interest,
+			Outstanding:   payment.Outstanding,
+		},
+	})
+}
@@ -0,0 +1,152 @@
+package liability
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.LiabilityRepository {
+	db.AutoMigrate(&model.CreditCard{}, &model.Loan{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetCreditCard(ctx context.Context, accountID uuid.UUID) (*entity.CreditCard, error) {
+	var c model.CreditCard
+	err := r.getDB(ctx).Where("account_id = ?", accountID).First(&c).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(entity.ErrCreditCardNotFound)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get credit card")
+	}
+
+	return &entity.CreditCard{
+		ID:               c.ID,
+		AccountID:        c.AccountID,
+		CreditLimit:      c.CreditLimit,
+		StatementDay:     c.StatementDay,
+		DueDays:          c.DueDays,
+		MinPaymentRate:   c.MinPaymentRate,
+		MinPaymentAmount: c.MinPaymentAmount,
+		CreatedAt:        c.CreatedAt,
+		UpdatedAt:        c.UpdatedAt,
+	}, nil
+}
+
+func (r *repository) SaveCreditCard(ctx context.Context, input entity.CreditCard) (*entity.CreditCard, error) {
+	var c model.CreditCard
+	err := r.getDB(ctx).Where("account_id = ?", input.AccountID).First(&c).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get credit card")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c = model.CreditCard{
+			ID:        uuid.New(),
+			AccountID: input.AccountID,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	c.CreditLimit = input.CreditLimit
+	c.StatementDay = input.StatementDay
+	c.DueDays = input.DueDays
+	c.MinPaymentRate = input.MinPaymentRate
+	c.MinPaymentAmount = input.MinPaymentAmount
+	c.UpdatedAt = time.Now()
+
+	if err := r.getDB(ctx).Save(&c).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to save credit card")
+	}
+
+	return &entity.CreditCard{
+		ID:               c.ID,
+		AccountID:        c.AccountID,
+		CreditLimit:      c.CreditLimit,
+		StatementDay:     c.StatementDay,
+		DueDays:          c.DueDays,
+		MinPaymentRate:   c.MinPaymentRate,
+		MinPaymentAmount: c.MinPaymentAmount,
+		CreatedAt:        c.CreatedAt,
+		UpdatedAt:        c.UpdatedAt,
+	}, nil
+}
+
+func (r *repository) GetLoan(ctx context.Context, accountID uuid.UUID) (*entity.Loan, error) {
+	var l model.Loan
+	err := r.getDB(ctx).Where("account_id = ?", accountID).First(&l).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.WithStack(entity.ErrLoanNotFound)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get loan")
+	}
+
+	return &entity.Loan{
+		ID:           l.ID,
+		AccountID:    l.AccountID,
+		Principal:    l.Principal,
+		AnnualRate:   l.AnnualRate,
+		TermMonths:   l.TermMonths,
+		StartDate:    l.StartDate,
+		AccruedUntil: l.AccruedUntil,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}, nil
+}
+
+func (r *repository) SaveLoan(ctx context.Context, input entity.Loan) (*entity.Loan, error) {
+	var l model.Loan
+	err := r.getDB(ctx).Where("account_id = ?", input.AccountID).First(&l).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, "failed to get loan")
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		l = model.Loan{
+			ID:        uuid.New(),
+			AccountID: input.AccountID,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	l.Principal = input.Principal
+	l.AnnualRate = input.AnnualRate
+	l.TermMonths = input.TermMonths
+	l.StartDate = input.StartDate
+	l.AccruedUntil = input.AccruedUntil
+	l.UpdatedAt = time.Now()
+
+	if err := r.getDB(ctx).Save(&l).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to save loan")
+	}
+
+	return &entity.Loan{
+		ID:           l.ID,
+		AccountID:    l.AccountID,
+		Principal:    l.Principal,
+		AnnualRate:   l.AnnualRate,
+		TermMonths:   l.TermMonths,
+		StartDate:    l.StartDate,
+		AccruedUntil: l.AccruedUntil,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,348 @@
+package liability
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	liabilityRepo   interfaces.LiabilityRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(txManager interfaces.TxManager, liabilityRepo interfaces.LiabilityRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		liabilityRepo:   liabilityRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// today is the start of the current day, the boundary between accrued days.
+func today(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (u *usecase) getAccount(ctx context.Context, userID, accountID uuid.UUID, accountType entity.AccountType, errWrongType error) (*entity.Account, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if account.Type != accountType {
+		return nil, errors.WithStack(errWrongType)
+	}
+
+	return account, nil
+}
+
+func (u *usecase) GetCreditCard(ctx context.Context, userID, accountID uuid.UUID) (*entity.CreditCard, error) {
+	if _, err := u.getAccount(ctx, userID, accountID, entity.AccountTypeCreditCard, entity.ErrNotCreditCardAccount); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	card, err := u.liabilityRepo.GetCreditCard(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get credit card")
+	}
+
+	return card, nil
+}
+
+func (u *usecase) SetCreditCard(ctx context.Context, input entity.CreditCardInput) (*entity.CreditCard, error) {
+	if _, err := u.getAccount(ctx, input.UserID, input.AccountID, entity.AccountTypeCreditCard, entity.ErrNotCreditCardAccount); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	card, err := u.liabilityRepo.SaveCreditCard(ctx, entity.CreditCard{
+		AccountID:        input.AccountID,
+		CreditLimit:      input.CreditLimit,
+		StatementDay:     input.StatementDay,
+		DueDays:          input.DueDays,
+		MinPaymentRate:   input.MinPaymentRate,
+		MinPaymentAmount: input.MinPaymentAmount,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save credit card")
+	}
+
+	return card, nil
+}
+
+// GetStatement rebuilds the latest statement of the card from the ledger. The
+// statement balance is what was owed when the statement closed, and payments
+// are money that entered the account since.
+func (u *usecase) GetStatement(ctx context.Context, userID, accountID uuid.UUID) (*entity.CreditCardStatement, error) {
+	account, err := u.getAccount(ctx, userID, accountID, entity.AccountTypeCreditCard, entity.ErrNotCreditCardAccount)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	card, err := u.liabilityRepo.GetCreditCard(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get credit card")
+	}
+
+	now := time.Now()
+	statementDate := card.LastStatementDate(now)
+	closedAt := statementDate.AddDate(0, 0, 1)
+
+	balance, err := u.transactionRepo.GetAccountLedgerBalanceAt(ctx, accountID, closedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get statement balance")
+	}
+
+	postings, err := u.transactionRepo.GetAccountPostings(ctx, accountID, closedAt, now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get postings")
+	}
+
+	// Moves between pockets of the card net to zero per transaction
+	net := make(map[uuid.UUID]decimal.Decimal)
+	for _, p := range postings {
+		net[p.TransactionID] = net[p.TransactionID].Add(p.Amount)
+	}
+
+	paid := decimal.Zero
+	for _, amount := range net {
+		if amount.IsPositive() {
+			paid = paid.Add(amount)
+		}
+	}
+
+	statementBalance := decimal.Max(balance.Neg(), decimal.Zero)
+	outstanding := account.Balance.Neg()
+
+	return &entity.CreditCardStatement{
+		StatementDate:      statementDate,
+		DueDate:            statementDate.AddDate(0, 0, card.DueDays),
+		StatementBalance:   statementBalance,
+		PaidSinceStatement: paid,
+		MinimumPayment:     decimal.Max(card.MinimumPayment(statementBalance).Sub(paid), decimal.Zero),
+		Outstanding:        outstanding,
+		AvailableCredit:    card.CreditLimit.Sub(decimal.Max(outstanding, decimal.Zero)),
+	}, nil
+}
+
+func (u *usecase) GetLoan(ctx context.Context, userID, accountID uuid.UUID) (*entity.Loan, error) {
+	if _, err := u.getAccount(ctx, userID, accountID, entity.AccountTypeLoan, entity.ErrNotLoanAccount); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	loan, err := u.liabilityRepo.GetLoan(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get loan")
+	}
+
+	return loan, nil
+}
+
+// SetLoan sets or replaces the terms of the loan. The first terms set on an
+// account without a balance book the principal as owed, dated on StartDate.
+func (u *usecase) SetLoan(ctx context.Context, input entity.LoanInput) (*entity.Loan, error) {
+	if _, err := u.getAccount(ctx, input.UserID, input.AccountID, entity.AccountTypeLoan, entity.ErrNotLoanAccount); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	loan := entity.Loan{
+		AccountID:    input.AccountID,
+		Principal:    input.Principal,
+		AnnualRate:   input.AnnualRate,
+		TermMonths:   input.TermMonths,
+		StartDate:    input.StartDate,
+		AccruedUntil: input.StartDate,
+	}
+
+	var result *entity.Loan
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		account, err := u.accountRepo.LockAccount(ctx, input.AccountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock account")
+		}
+
+		current, err := u.liabilityRepo.GetLoan(ctx, input.AccountID)
+		if err != nil && !errors.Is(err, entity.ErrLoanNotFound) {
+			return errors.Wrap(err, "failed to get loan")
+		}
+		if current != nil && current.AccruedUntil.After(loan.AccruedUntil) {
+			loan.AccruedUntil = current.AccruedUntil
+		}
+
+		if current == nil && account.Balance.IsZero() {
+			if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+				AccountID:    input.AccountID,
+				FromPocketID: &cashbox.ID,
+				Type:         entity.TxTypeWithdraw,
+				Amount:       input.Principal,
+				Note:         "Loan disbursement",
+				CreatedAt:    input.StartDate,
+			}); err != nil {
+				return errors.Wrap(err, "failed to create transaction")
+			}
+
+			if err := u.accountRepo.Withdraw(ctx, input.AccountID, input.Principal); err != nil {
+				return errors.Wrap(err, "failed to withdraw from account")
+			}
+
+			if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, input.Principal); err != nil {
+				return errors.Wrap(err, "failed to withdraw from cashbox pocket")
+			}
+		}
+
+		result, err = u.liabilityRepo.SaveLoan(ctx, loan)
+		if err != nil {
+			return errors.Wrap(err, "failed to save loan")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// Pay repays a credit card or loan from a pocket of an asset account. On a
+// loan, the interest accrued since the last payment is charged first and the
+// rest of the payment repays principal.
+func (u *usecase) Pay(ctx context.Context, input entity.LiabilityPaymentInput) (*entity.LiabilityPayment, error) {
+	// Check ownership
+	account, err := u.accountRepo.GetUserAccount(ctx, input.UserID, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if !account.Type.IsLiability() {
+		return nil, errors.WithStack(entity.ErrNotLiabilityAccount)
+	}
+
+	fromPocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, input.FromPocketID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket")
+	}
+
+	fromAccount, err := u.accountRepo.GetUserAccount(ctx, input.UserID, fromPocket.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	if fromAccount.Type.IsLiability() {
+		return nil, errors.WithStack(entity.ErrInvalidPaymentPocket)
+	}
+
+	// Converting between currencies is not supported
+	if fromPocket.Currency != account.Currency {
+		return nil, errors.Wrap(entity.ErrCurrencyMismatch, "failed to pay")
+	}
+
+	cashbox, err := u.pocketRepo.GetCashboxPocket(ctx, input.AccountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	payment := entity.LiabilityPayment{
+		Amount: input.Amount,
+	}
+
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Both accounts are locked in one id-ordered call, before any pocket
+		locked, err := u.accountRepo.LockAccounts(ctx, input.AccountID, fromPocket.AccountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock accounts")
+		}
+		outstanding := locked[input.AccountID].Balance.Neg()
+
+		loan, err := u.liabilityRepo.GetLoan(ctx, input.AccountID)
+		if err != nil && !errors.Is(err, entity.ErrLoanNotFound) {
+			return errors.Wrap(err, "failed to get loan")
+		}
+
+		now := today(time.Now())
+		if loan != nil {
+			payment.Interest = decimal.Min(loan.AccruedInterest(outstanding, now), input.Amount)
+		}
+		payment.Principal = input.Amount.Sub(payment.Interest)
+		payment.Outstanding = outstanding.Sub(payment.Principal)
+
+		if payment.Outstanding.IsNegative() {
+			return errors.WithStack(entity.ErrPaymentExceedsOutstanding)
+		}
+
+		if payment.Interest.IsPositive() {
+			if err := u.accountRepo.Withdraw(ctx, input.AccountID, payment.Interest); err != nil {
+				return errors.Wrap(err, "failed to charge interest to account")
+			}
+		}
+
+		if err := u.accountRepo.Transfer(ctx, fromPocket.AccountID, input.AccountID, input.Amount); err != nil {
+			return errors.Wrap(err, "failed to transfer between accounts")
+		}
+
+		if payment.Interest.IsPositive() {
+			if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+				AccountID:    input.AccountID,
+				FromPocketID: &cashbox.ID,
+				Type:         entity.TxTypeInterestCharge,
+				Amount:       payment.Interest,
+				Note:         "Loan interest " + loan.AccruedUntil.Format(dateLayout) + " to " + now.Format(dateLayout),
+			}); err != nil {
+				return errors.Wrap(err, "failed to create interest transaction")
+			}
+
+			if err := u.pocketRepo.Withdraw(ctx, cashbox.ID, payment.Interest); err != nil {
+				return errors.Wrap(err, "failed to charge interest to cashbox pocket")
+			}
+		}
+
+		transaction, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    fromPocket.AccountID,
+			ToAccountID:  &input.AccountID,
+			FromPocketID: &fromPocket.ID,
+			ToPocketID:   &cashbox.ID,
+			Type:         entity.TxTypeTransfer,
+			Amount:       input.Amount,
+			Note:         input.Note,
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+		payment.TransactionID = transaction.ID
+
+		if err := u.pocketRepo.Transfer(ctx, fromPocket.ID, cashbox.ID, input.Amount); err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		if loan != nil && now.After(loan.AccruedUntil) {
+			loan.AccruedUntil = now
+			if _, err := u.liabilityRepo.SaveLoan(ctx, *loan); err != nil {
+				return errors.Wrap(err, "failed to save loan")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &payment, nil
+}
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"strings"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/gofiber/fiber/v2"
+)
+
+type Config struct {
+	Emails []string `mapstructure:"emails"` // Users allowed on admin routes
+}
+
+type AdminMiddleware interface {
+	// Admin lets the request through only for users listed in Config.Emails.
+	// It must run after AuthMiddleware.Auth.
+	Admin(ctx *fiber.Ctx) error
+}
+
+type adminMiddleware struct {
+	userRepo       interfaces.UserRepository
+	authMiddleware authentication.AuthMiddleware
+	emails         map[string]struct{}
+}
+
+func NewAdminMiddleware(userRepo interfaces.UserRepository, authMiddleware authentication.AuthMiddleware, config *Config) AdminMiddleware {
+	emails := make(map[string]struct{}, len(config.Emails))
+	for _, email := range config.Emails {
+		emails[strings.ToLower(email)] = struct{}{}
+	}
+
+	return &adminMiddleware{
+		userRepo:       userRepo,
+		authMiddleware: authMiddleware,
+		emails:         emails,
+	}
+}
+
+func (m *adminMiddleware) Admin(ctx *fiber.Ctx) error {
+	userID, err := m.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	user, err := m.userRepo.GetUser(ctx.UserContext(), userID)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	if _, ok := m.emails[strings.ToLower(user.Email)]; !ok {
+		return ctx.Status(fiber.StatusForbidden).JSON(dto.HttpResponse{
+			Error: "Forbidden",
+		})
+	}
+
+	return ctx.Next()
+}
@@ -0,0 +1,138 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	defaultTTL   = 24 * time.Hour
+)
+
+type Config struct {
+	TTL int64 `mapstructure:"ttl"` // Seconds a key is remembered
+}
+
+type IdempotencyMiddleware interface {
+	// Handle replays the stored response when a request is retried with the same
+	// Idempotency-Key. Requests without the header pass through unchanged.
+	Handle(ctx *fiber.Ctx) error
+}
+
+type idempotencyMiddleware struct {
+	idempotencyRepo interfaces.IdempotencyRepository
+	authMiddleware  authentication.AuthMiddleware
+	config          *Config
+}
+
+func NewIdempotencyMiddleware(idempotencyRepo interfaces.IdempotencyRepository, authMiddleware authentication.AuthMiddleware, config *Config) IdempotencyMiddleware {
+	return &idempotencyMiddleware{
+		idempotencyRepo: idempotencyRepo,
+		authMiddleware:  authMiddleware,
+		config:          config,
+	}
+}
+
+func fingerprint(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (m *idempotencyMiddleware) Handle(ctx *fiber.Ctx) error {
+	key := ctx.Get(HeaderIdempotencyKey)
+	if key == "" {
+		return ctx.Next()
+	}
+
+	if len(key) > maxKeyLength {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Idempotency-Key is too long",
+		})
+	}
+
+	userID, err := m.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	ttl := time.Second * time.Duration(m.config.TTL)
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	record := entity.IdempotencyRecord{
+		Fingerprint: fingerprint(ctx),
+	}
+
+	existing, reserved, err := m.idempotencyRepo.Reserve(ctx.UserContext(), userID, key, record, ttl)
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve idempotency key")
+	}
+
+	if !reserved {
+		if existing.Fingerprint != record.Fingerprint {
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(dto.HttpResponse{
+				Error: "Idempotency-Key was already used with a different request",
+			})
+		}
+
+		if !existing.Completed {
+			return ctx.Status(fiber.StatusConflict).JSON(dto.HttpResponse{
+				Error: "A request with this Idempotency-Key is in progress",
+			})
+		}
+
+		ctx.Set(HeaderReplayed, "true")
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return ctx.Status(existing.StatusCode).Send(existing.Body)
+	}
+
+	if err := ctx.Next(); err != nil {
+		m.release(ctx, userID, key)
+		return errors.WithStack(err)
+	}
+
+	// Server errors may be transient, let the client retry them
+	if ctx.Response().StatusCode() >= fiber.StatusInternalServerError {
+		m.release(ctx, userID, key)
+		return nil
+	}
+
+	record.Completed = true
+	record.StatusCode = ctx.Response().StatusCode()
+	record.Body = append([]byte(nil), ctx.Response().Body()...)
+
+	if err := m.idempotencyRepo.Save(ctx.UserContext(), userID, key, record, ttl); err != nil {
+		logger.ErrorContext(ctx.UserContext(), "failed to save idempotency record", slog.Any("error", err))
+	}
+
+	return nil
+}
+
+func (m *idempotencyMiddleware) release(ctx *fiber.Ctx, userID uuid.UUID, key string) {
+	if err := m.idempotencyRepo.Release(ctx.UserContext(), userID, key); err != nil {
+		logger.ErrorContext(ctx.UserContext(), "failed to release idempotency key", slog.Any("error", err))
+	}
+}
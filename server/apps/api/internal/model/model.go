@@ -6,15 +6,17 @@ import (
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"id"`
-	Email     string    `gorm:"email"`
-	Name      string    `gorm:"name"`
-	Password  string    `gorm:"password"`
-	CreatedAt time.Time `gorm:"created_at"`
-	UpdatedAt time.Time `gorm:"updated_at"`
+	ID           uuid.UUID `gorm:"id"`
+	Email        string    `gorm:"email"`
+	Name         string    `gorm:"name"`
+	Password     string    `gorm:"password"`
+	BaseCurrency string    `gorm:"type:text;default:THB"`
+	CreatedAt    time.Time `gorm:"created_at"`
+	UpdatedAt    time.Time `gorm:"updated_at"`
 }
 
 type Account struct {
@@ -23,7 +25,12 @@ type Account struct {
 	Type      entity.AccountType `gorm:"type:text"`
 	Name      string             `gorm:"name"`
 	Bank      string             `gorm:"bank"`
+	Currency  string             `gorm:"type:text;default:THB"`
 	Balance   decimal.Decimal    `gorm:"balance"`
+	SortOrder int                `gorm:"sort_order"`
+	Color     string             `gorm:"color"`
+	Icon      string             `gorm:"icon"`
+	Archived  bool               `gorm:"archived"`
 	CreatedAt time.Time          `gorm:"created_at"`
 	UpdatedAt time.Time          `gorm:"updated_at"`
 }
@@ -33,18 +40,228 @@ type Pocket struct {
 	AccountID uuid.UUID         `gorm:"references:Account"`
 	Name      string            `gorm:"name"`
 	Type      entity.PocketType `gorm:"type:text"`
+	Currency  string            `gorm:"type:text;default:THB"`
 	Balance   decimal.Decimal   `gorm:"balance"`
-	CreatedAt time.Time         `gorm:"created_at"`
-	UpdatedAt time.Time         `gorm:"updated_at"`
+	SortOrder int               `gorm:"sort_order"`
+	Color     string            `gorm:"color"`
+	Icon      string            `gorm:"icon"`
+	Archived  bool              `gorm:"archived"`
+
+	// Savings goal, all null when the pocket has none
+	GoalAmount              decimal.NullDecimal `gorm:"goal_amount"`
+	GoalDate                *time.Time          `gorm:"type:date"`
+	GoalMonthlyContribution decimal.NullDecimal `gorm:"goal_monthly_contribution"`
+
+	CreatedAt time.Time      `gorm:"created_at"`
+	UpdatedAt time.Time      `gorm:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index"` // Kept so past transactions still resolve the pocket
 }
 
 type Transaction struct {
-	ID           uuid.UUID       `gorm:"id"`
-	AccountID    uuid.UUID       `gorm:"references:Account"`
-	FromPocketID *uuid.UUID      `gorm:"references:Pocket"`
-	ToPocketID   *uuid.UUID      `gorm:"references:Pocket"`
+	ID           uuid.UUID       `gorm:"id;index:idx_transactions_account_created_at,priority:3"`
+	AccountID    uuid.UUID       `gorm:"references:Account;index:idx_transactions_account_created_at,priority:1"`
+	ToAccountID  *uuid.UUID      `gorm:"references:Account;index"`
+	FromPocketID *uuid.UUID      `gorm:"references:Pocket;index"`
+	ToPocketID   *uuid.UUID      `gorm:"references:Pocket;index"`
 	Type         entity.TxType   `gorm:"type:text"`
+	Currency     string          `gorm:"type:text;default:THB"`
 	Amount       decimal.Decimal `gorm:"amount"`
+	Note         string          `gorm:"note"`
+	Payee        string          `gorm:"payee"`
+	ReversalOfID *uuid.UUID      `gorm:"uniqueIndex"`
+	FeeOfID      *uuid.UUID      `gorm:"index"`
+	CreatedAt    time.Time       `gorm:"created_at;index:idx_transactions_account_created_at,priority:2"`
+	UpdatedAt    time.Time       `gorm:"updated_at"`
+}
+
+type Posting struct {
+	ID            uuid.UUID       `gorm:"id"`
+	TransactionID uuid.UUID       `gorm:"references:Transaction;index"`
+	AccountID     uuid.UUID       `gorm:"references:Account;index"`
+	PocketID      *uuid.UUID      `gorm:"references:Pocket;index"`
+	Amount        decimal.Decimal `gorm:"amount"`
+	CreatedAt     time.Time       `gorm:"created_at"`
+}
+
+type Trade struct {
+	ID            uuid.UUID        `gorm:"id"`
+	AccountID     uuid.UUID        `gorm:"references:Account;index:idx_trades_account_symbol,priority:1"`
+	TransactionID uuid.UUID        `gorm:"references:Transaction;index"`
+	Symbol        string           `gorm:"index:idx_trades_account_symbol,priority:2"`
+	Side          entity.TradeSide `gorm:"type:text"`
+	Quantity      decimal.Decimal  `gorm:"quantity"`
+	Price         decimal.Decimal  `gorm:"price"`
+	Fee           decimal.Decimal  `gorm:"fee"`
+	CreatedAt     time.Time        `gorm:"created_at"`
+}
+
+type Lot struct {
+	ID                uuid.UUID       `gorm:"id"`
+	AccountID         uuid.UUID       `gorm:"references:Account;index:idx_lots_account_symbol,priority:1"`
+	TradeID           uuid.UUID       `gorm:"references:Trade;index"`
+	Symbol            string          `gorm:"index:idx_lots_account_symbol,priority:2"`
+	Quantity          decimal.Decimal `gorm:"quantity"`
+	RemainingQuantity decimal.Decimal `gorm:"remaining_quantity"`
+	CostPerUnit       decimal.Decimal `gorm:"cost_per_unit"`
+	CreatedAt         time.Time       `gorm:"created_at"`
+	UpdatedAt         time.Time       `gorm:"updated_at"`
+}
+
+type FundOrder struct {
+	ID            uuid.UUID            `gorm:"id"`
+	AccountID     uuid.UUID            `gorm:"references:Account;index:idx_fund_orders_account_fund,priority:1"`
+	TransactionID uuid.UUID            `gorm:"references:Transaction;index"`
+	FundCode      string               `gorm:"index:idx_fund_orders_account_fund,priority:2"`
+	Side          entity.FundOrderSide `gorm:"type:text"`
+	Units         decimal.Decimal      `gorm:"units"`
+	NAV           decimal.Decimal      `gorm:"nav"`
+	NAVDate       time.Time            `gorm:"type:date"`
+	Amount        decimal.Decimal      `gorm:"amount"`
+	CreatedAt     time.Time            `gorm:"created_at"`
+}
+
+type Price struct {
+	ID        uuid.UUID          `gorm:"id"`
+	Symbol    string             `gorm:"uniqueIndex:idx_prices_symbol_date,priority:1"`
+	Date      time.Time          `gorm:"type:date;uniqueIndex:idx_prices_symbol_date,priority:2"`
+	Value     decimal.Decimal    `gorm:"value"`
+	Source    entity.PriceSource `gorm:"type:text"`
+	CreatedAt time.Time          `gorm:"created_at"`
+	UpdatedAt time.Time          `gorm:"updated_at"`
+}
+
+type ExchangeRate struct {
+	ID           uuid.UUID       `gorm:"id"`
+	FromCurrency string          `gorm:"uniqueIndex:idx_exchange_rates_pair_date,priority:1"`
+	ToCurrency   string          `gorm:"uniqueIndex:idx_exchange_rates_pair_date,priority:2"`
+	Date         time.Time       `gorm:"type:date;uniqueIndex:idx_exchange_rates_pair_date,priority:3"`
+	Rate         decimal.Decimal `gorm:"rate"`
 	CreatedAt    time.Time       `gorm:"created_at"`
 	UpdatedAt    time.Time       `gorm:"updated_at"`
 }
+
+type FixedDeposit struct {
+	ID                 uuid.UUID                 `gorm:"id"`
+	AccountID          uuid.UUID                 `gorm:"references:Account;uniqueIndex"`
+	Principal          decimal.Decimal           `gorm:"principal"`
+	AnnualRate         decimal.Decimal           `gorm:"annual_rate"`
+	TermMonths         int                       `gorm:"term_months"`
+	StartDate          time.Time                 `gorm:"type:date"`
+	Compounding        entity.Compounding        `gorm:"type:text"`
+	WithholdingTaxRate decimal.Decimal           `gorm:"withholding_tax_rate"`
+	MaturityAction     entity.MaturityAction     `gorm:"type:text"`
+	SavingsPocketID    *uuid.UUID                `gorm:"references:Pocket"`
+	Status             entity.FixedDepositStatus `gorm:"type:text;index"`
+	AccruedUntil       time.Time                 `gorm:"type:date"`
+	Renewals           int                       `gorm:"renewals"`
+	MaturedAt          *time.Time                `gorm:"matured_at"`
+	CreatedAt          time.Time                 `gorm:"created_at"`
+	UpdatedAt          time.Time                 `gorm:"updated_at"`
+}
+
+type InterestRule struct {
+	ID                 uuid.UUID              `gorm:"id"`
+	AccountID          uuid.UUID              `gorm:"references:Account;uniqueIndex"`
+	Payout             entity.PayoutFrequency `gorm:"type:text"`
+	WithholdingTaxRate decimal.Decimal        `gorm:"withholding_tax_rate"`
+	PaidUntil          time.Time              `gorm:"type:date"`
+	Tiers              []InterestTier         `gorm:"foreignKey:RuleID"`
+	CreatedAt          time.Time              `gorm:"created_at"`
+	UpdatedAt          time.Time              `gorm:"updated_at"`
+}
+
+type InterestTier struct {
+	ID         uuid.UUID       `gorm:"id"`
+	RuleID     uuid.UUID       `gorm:"references:InterestRule;index"`
+	MinBalance decimal.Decimal `gorm:"min_balance"`
+	Rate       decimal.Decimal `gorm:"rate"`
+}
+
+type CreditCard struct {
+	ID               uuid.UUID       `gorm:"id"`
+	AccountID        uuid.UUID       `gorm:"references:Account;uniqueIndex"`
+	CreditLimit      decimal.Decimal `gorm:"credit_limit"`
+	StatementDay     int             `gorm:"statement_day"`
+	DueDays          int             `gorm:"due_days"`
+	MinPaymentRate   decimal.Decimal `gorm:"min_payment_rate"`
+	MinPaymentAmount decimal.Decimal `gorm:"min_payment_amount"`
+	CreatedAt        time.Time       `gorm:"created_at"`
+	UpdatedAt        time.Time       `gorm:"updated_at"`
+}
+
+type Loan struct {
+	ID           uuid.UUID       `gorm:"id"`
+	AccountID    uuid.UUID       `gorm:"references:Account;uniqueIndex"`
+	Principal    decimal.Decimal `gorm:"principal"`
+	AnnualRate   decimal.Decimal `gorm:"annual_rate"`
+	TermMonths   int             `gorm:"term_months"`
+	StartDate    time.Time       `gorm:"type:date"`
+	AccruedUntil time.Time       `gorm:"type:date"`
+	CreatedAt    time.Time       `gorm:"created_at"`
+	UpdatedAt    time.Time       `gorm:"updated_at"`
+}
+
+type AccountSnapshot struct {
+	ID          uuid.UUID           `gorm:"id"`
+	Date        time.Time           `gorm:"type:date;uniqueIndex:idx_account_snapshots_account_date,priority:2"`
+	UserID      uuid.UUID           `gorm:"references:User;index"`
+	AccountID   uuid.UUID           `gorm:"references:Account;uniqueIndex:idx_account_snapshots_account_date,priority:1"`
+	AccountType entity.AccountType  `gorm:"type:text"`
+	Currency    string              `gorm:"type:text"`
+	Balance     decimal.Decimal     `gorm:"balance"`
+	BaseBalance decimal.NullDecimal `gorm:"base_balance"`
+	CreatedAt   time.Time           `gorm:"created_at"`
+}
+
+type PocketSnapshot struct {
+	ID        uuid.UUID       `gorm:"id"`
+	Date      time.Time       `gorm:"type:date;uniqueIndex:idx_pocket_snapshots_pocket_date,priority:2"`
+	AccountID uuid.UUID       `gorm:"references:Account;index"`
+	PocketID  uuid.UUID       `gorm:"references:Pocket;uniqueIndex:idx_pocket_snapshots_pocket_date,priority:1"`
+	Balance   decimal.Decimal `gorm:"balance"`
+	CreatedAt time.Time       `gorm:"created_at"`
+}
+
+type NetWorthSnapshot struct {
+	ID           uuid.UUID       `gorm:"id"`
+	Date         time.Time       `gorm:"type:date;uniqueIndex:idx_net_worth_snapshots_user_date,priority:2"`
+	UserID       uuid.UUID       `gorm:"references:User;uniqueIndex:idx_net_worth_snapshots_user_date,priority:1"`
+	BaseCurrency string          `gorm:"type:text"`
+	Total        decimal.Decimal `gorm:"total"`
+	Assets       decimal.Decimal `gorm:"assets"`
+	Liabilities  decimal.Decimal `gorm:"liabilities"`
+	CreatedAt    time.Time       `gorm:"created_at"`
+}
+
+type AllocationTarget struct {
+	ID        uuid.UUID                  `gorm:"id"`
+	UserID    uuid.UUID                  `gorm:"references:User;uniqueIndex:idx_allocation_targets_user_dimension_key,priority:1"`
+	Dimension entity.AllocationDimension `gorm:"type:text;uniqueIndex:idx_allocation_targets_user_dimension_key,priority:2"`
+	Key       string                     `gorm:"uniqueIndex:idx_allocation_targets_user_dimension_key,priority:3"`
+	Weight    decimal.Decimal            `gorm:"weight"`
+	CreatedAt time.Time                  `gorm:"created_at"`
+	UpdatedAt time.Time                  `gorm:"updated_at"`
+}
+
+type AllocationRule struct {
+	ID         uuid.UUID                 `gorm:"id"`
+	AccountID  uuid.UUID                 `gorm:"references:Account;index"`
+	PocketID   uuid.UUID                 `gorm:"references:Pocket"`
+	Type       entity.AllocationRuleType `gorm:"type:text"`
+	Value      decimal.Decimal           `gorm:"value"`
+	MaxAmount  decimal.NullDecimal       `gorm:"max_amount"`
+	MaxBalance decimal.NullDecimal       `gorm:"max_balance"`
+	Priority   int                       `gorm:"priority"`
+	CreatedAt  time.Time                 `gorm:"created_at"`
+	UpdatedAt  time.Time                 `gorm:"updated_at"`
+}
+
+type Budget struct {
+	ID        uuid.UUID       `gorm:"id"`
+	PocketID  uuid.UUID       `gorm:"references:Pocket;uniqueIndex:idx_budgets_pocket_month,priority:1"`
+	Month     time.Time       `gorm:"type:date;uniqueIndex:idx_budgets_pocket_month,priority:2"`
+	Amount    decimal.Decimal `gorm:"amount"`
+	Rollover  bool            `gorm:"rollover"`
+	CreatedAt time.Time       `gorm:"created_at"`
+	UpdatedAt time.Time       `gorm:"updated_at"`
+}
@@ -0,0 +1,148 @@
+package networth
+
+import (
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(networthUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        networthUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/", h.GetSeries)
+}
+
+type seriesRequest struct {
+	From     string `query:"from"`     // 2006-01-02, defaults to a month before to
+	To       string `query:"to"`       // 2006-01-02, defaults to today
+	Interval string `query:"interval"` // day, week or month, defaults to day
+
+	from time.Time
+	to   time.Time
+}
+
+func (a *seriesRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.QueryParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if a.Interval == "" {
+		a.Interval = string(entity.IntervalDay)
+	}
+
+	a.to = today(time.Now())
+	if a.To != "" {
+		to, err := time.Parse(dateLayout, a.To)
+		if err != nil {
+			return errors.Wrap(err, "invalid to")
+		}
+		a.to = to
+	}
+
+	a.from = a.to.AddDate(0, -1, 0)
+	if a.From != "" {
+		from, err := time.Parse(dateLayout, a.From)
+		if err != nil {
+			return errors.Wrap(err, "invalid from")
+		}
+		a.from = from
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *seriesRequest) Validate() error {
+	v := validator.New()
+	v.Must(entity.Interval(a.Interval).IsValid(), "interval must be day, week or month")
+	v.Must(!a.from.After(a.to), "from must not be after to")
+
+	return errors.WithStack(v.Error())
+}
+
+type pointResponse struct {
+	Date         string                                 `json:"date"`
+	SnapshotDate string                                 `json:"snapshotDate"`
+	Total        decimal.Decimal                        `json:"total"`
+	Assets       decimal.Decimal                        `json:"assets"`
+	Liabilities  decimal.Decimal                        `json:"liabilities"`
+	ByType       map[entity.AccountType]decimal.Decimal `json:"byType"`
+}
+
+type seriesResponse struct {
+	BaseCurrency string          `json:"baseCurrency"`
+	Interval     entity.Interval `json:"interval"`
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	Points       []pointResponse `json:"points"`
+}
+
+// GetSeries returns the net worth over time for the dashboard chart.
+func (h *controller) GetSeries(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req seriesRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	series, err := h.usecase.GetSeries(ctx.UserContext(), userID, req.from, req.to, entity.Interval(req.Interval))
+	if errors.Is(err, entity.ErrInvalidInterval) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid interval",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get net worth")
+	}
+
+	response := seriesResponse{
+		BaseCurrency: series.BaseCurrency,
+		Interval:     series.Interval,
+		From:         req.from.Format(dateLayout),
+		To:           req.to.Format(dateLayout),
+		Points:       make([]pointResponse, 0, len(series.Points)),
+	}
+	for _, p := range series.Points {
+		response.Points = append(response.Points, pointResponse{
+			Date:         p.Date.Format(dateLayout),
+			SnapshotDate: p.SnapshotDate.Format(dateLayout),
+			Total:        p.Total,
+			Assets:       p.Assets,
+			Liabilities:  p.Liabilities,
+			ByType:       p.ByType,
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
@@ -0,0 +1,152 @@
+package networth
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.SnapshotRepository {
+	db.AutoMigrate(&model.AccountSnapshot{}, &model.PocketSnapshot{}, &model.NetWorthSnapshot{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) SaveDailySnapshot(ctx context.Context, snapshot entity.DailySnapshot) error {
+	now := time.Now()
+
+	accounts := make([]model.AccountSnapshot, 0, len(snapshot.Accounts))
+	for _, a := range snapshot.Accounts {
+		row := model.AccountSnapshot{
+			ID:          uuid.New(),
+			Date:        a.Date,
+			UserID:      a.UserID,
+			AccountID:   a.AccountID,
+			AccountType: a.AccountType,
+			Currency:    a.Currency,
+			Balance:     a.Balance,
+			CreatedAt:   now,
+		}
+		if a.BaseBalance != nil {
+			row.BaseBalance = decimal.NewNullDecimal(*a.BaseBalance)
+		}
+		accounts = append(accounts, row)
+	}
+
+	pockets := make([]model.PocketSnapshot, 0, len(snapshot.Pockets))
+	for _, p := range snapshot.Pockets {
+		pockets = append(pockets, model.PocketSnapshot{
+			ID:        uuid.New(),
+			Date:      p.Date,
+			AccountID: p.AccountID,
+			PocketID:  p.PocketID,
+			Balance:   p.Balance,
+			CreatedAt: now,
+		})
+	}
+
+	netWorth := model.NetWorthSnapshot{
+		ID:           uuid.New(),
+		Date:         snapshot.NetWorth.Date,
+		UserID:       snapshot.NetWorth.UserID,
+		BaseCurrency: snapshot.NetWorth.BaseCurrency,
+		Total:        snapshot.NetWorth.Total,
+		Assets:       snapshot.NetWorth.Assets,
+		Liabilities:  snapshot.NetWorth.Liabilities,
+		CreatedAt:    now,
+	}
+
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(accounts) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "account_id"}, {Name: "date"}},
+				DoUpdates: clause.AssignmentColumns([]string{"account_type", "currency", "balance", "base_balance", "created_at"}),
+			}).Create(&accounts).Error; err != nil {
+				return errors.Wrap(err, "failed to save account snapshots")
+			}
+		}
+
+		if len(pockets) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "pocket_id"}, {Name: "date"}},
+				DoUpdates: clause.AssignmentColumns([]string{"balance", "created_at"}),
+			}).Create(&pockets).Error; err != nil {
+				return errors.Wrap(err, "failed to save pocket snapshots")
+			}
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"base_currency", "total", "assets", "liabilities", "created_at"}),
+		}).Create(&netWorth).Error; err != nil {
+			return errors.Wrap(err, "failed to save net worth snapshot")
+		}
+
+		return nil
+	})
+}
+
+func (r *repository) GetAccountSnapshots(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]entity.AccountSnapshot, error) {
+	var snapshots []*model.AccountSnapshot
+	if err := r.getDB(ctx).Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date asc").Find(&snapshots).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get account snapshots")
+	}
+
+	var result []entity.AccountSnapshot
+	for _, s := range snapshots {
+		snapshot := entity.AccountSnapshot{
+			Date:        s.Date,
+			UserID:      s.UserID,
+			AccountID:   s.AccountID,
+			AccountType: s.AccountType,
+			Currency:    s.Currency,
+			Balance:     s.Balance,
+		}
+		if s.BaseBalance.Valid {
+			snapshot.BaseBalance = &s.BaseBalance.Decimal
+		}
+		result = append(result, snapshot)
+	}
+
+	return result, nil
+}
+
+func (r *repository) GetNetWorthSnapshots(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]entity.NetWorthSnapshot, error) {
+	var snapshots []*model.NetWorthSnapshot
+	if err := r.getDB(ctx).Where("user_id = ? AND date >= ? AND date <= ?", userID, from, to).Order("date asc").Find(&snapshots).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get net worth snapshots")
+	}
+
+	var result []entity.NetWorthSnapshot
+	for _, s := range snapshots {
+		result = append(result, entity.NetWorthSnapshot{
+			Date:         s.Date,
+			UserID:       s.UserID,
+			BaseCurrency: s.BaseCurrency,
+			Total:        s.Total,
+			Assets:       s.Assets,
+			Liabilities:  s.Liabilities,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,328 @@
+package networth
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	Interval int64 `mapstructure:"interval"` // Seconds between snapshots of today, 0 disables the runner
+}
+
+type usecase struct {
+	snapshotRepo    interfaces.SnapshotRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+	currencyUsecase *currency.Usecase
+}
+
+func NewUsecase(snapshotRepo interfaces.SnapshotRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository, currencyUsecase *currency.Usecase) *usecase {
+	return &usecase{
+		snapshotRepo:    snapshotRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+		currencyUsecase: currencyUsecase,
+	}
+}
+
+// today is the start of the current day, the date of a snapshot taken now.
+func today(now time.Time) time.Time {
+	y, m, d := now.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// accountsByUser groups every account by its owner, in a stable order.
+func (u *usecase) accountsByUser(ctx context.Context) ([]uuid.UUID, map[uuid.UUID][]entity.Account, error) {
+	accounts, err := u.accountRepo.GetAccounts(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get accounts")
+	}
+
+	var userIDs []uuid.UUID
+	byUser := make(map[uuid.UUID][]entity.Account)
+	for _, account := range accounts {
+		if _, ok := byUser[account.UserID]; !ok {
+			userIDs = append(userIDs, account.UserID)
+		}
+		byUser[account.UserID] = append(byUser[account.UserID], account)
+	}
+
+	return userIDs, byUser, nil
+}
+
+// SnapshotAll records today's balances of every user. A failing user is
+// logged and retried on the next run.
+func (u *usecase) SnapshotAll(ctx context.Context) error {
+	userIDs, byUser, err := u.accountsByUser(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	date := today(time.Now())
+	for _, userID := range userIDs {
+		if err := u.snapshot(ctx, userID, byUser[userID], date); err != nil {
+			logger.ErrorContext(ctx, "failed to snapshot net worth",
+				slog.String("user_id", userID.String()),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (u *usecase) snapshot(ctx context.Context, userID uuid.UUID, accounts []entity.Account, date time.Time) error {
+	var pockets []entity.PocketSnapshot
+	for _, account := range accounts {
+		accountPockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, account.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pockets")
+		}
+
+		for _, p := range accountPockets {
+			pockets = append(pockets, entity.PocketSnapshot{
+				Date:      date,
+				AccountID: account.ID,
+				PocketID:  p.ID,
+				Balance:   p.Balance,
+			})
+		}
+	}
+
+	snapshot, err := u.buildSnapshot(ctx, userID, accounts, pockets, date)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := u.snapshotRepo.SaveDailySnapshot(ctx, *snapshot); err != nil {
+		return errors.Wrap(err, "failed to save snapshot")
+	}
+
+	return nil
+}
+
+// buildSnapshot sums the pocket balances into account balances, converted to
+// the user's base currency at the rate of date.
+func (u *usecase) buildSnapshot(ctx context.Context, userID uuid.UUID, accounts []entity.Account, pockets []entity.PocketSnapshot, date time.Time) (*entity.DailySnapshot, error) {
+	baseCurrency, err := u.currencyUsecase.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base currency")
+	}
+
+	balances := make(map[uuid.UUID]decimal.Decimal)
+	for _, p := range pockets {
+		balances[p.AccountID] = balances[p.AccountID].Add(p.Balance)
+	}
+
+	snapshot := &entity.DailySnapshot{
+		NetWorth: entity.NetWorthSnapshot{
+			Date:         date,
+			UserID:       userID,
+			BaseCurrency: baseCurrency,
+		},
+		Pockets: pockets,
+	}
+
+	for _, account := range accounts {
+		value, err := u.currencyUsecase.ToBase(ctx, baseCurrency, balances[account.ID], account.Currency, date)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to convert balance")
+		}
+
+		snapshot.Accounts = append(snapshot.Accounts, entity.AccountSnapshot{
+			Date:        date,
+			UserID:      userID,
+			AccountID:   account.ID,
+			AccountType: account.Type,
+			Currency:    account.Currency,
+			Balance:     value.Amount,
+			BaseBalance: value.BaseAmount,
+		})
+
+		if value.BaseAmount == nil {
+			continue
+		}
+
+		snapshot.NetWorth.Total = snapshot.NetWorth.Total.Add(*value.BaseAmount)
+		if account.Type.IsLiability() {
+			snapshot.NetWorth.Liabilities = snapshot.NetWorth.Liabilities.Sub(*value.BaseAmount)
+		} else {
+			snapshot.NetWorth.Assets = snapshot.NetWorth.Assets.Add(*value.BaseAmount)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Backfill rebuilds the daily snapshots before today from the ledger, from the
+// day each account was opened or first posted to. It returns the number of
+// snapshots written and is safe to run more than once.
+func (u *usecase) Backfill(ctx context.Context) (int, error) {
+	userIDs, byUser, err := u.accountsByUser(ctx)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	end := today(time.Now())
+	count := 0
+	for _, userID := range userIDs {
+		n, err := u.backfillUser(ctx, userID, byUser[userID], end)
+		if err != nil {
+			return count, errors.Wrapf(err, "failed to backfill user %s", userID)
+		}
+		count += n
+	}
+
+	return count, nil
+}
+
+func (u *usecase) backfillUser(ctx context.Context, userID uuid.UUID, accounts []entity.Account, end time.Time) (int, error) {
+	postings := make(map[uuid.UUID][]entity.Posting, len(accounts))
+	opened := make(map[uuid.UUID]time.Time, len(accounts))
+	start := end
+	for _, account := range accounts {
+		accountPostings, err := u.transactionRepo.GetAccountPostings(ctx, account.ID, time.Time{}, end)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get postings")
+		}
+		postings[account.ID] = accountPostings
+
+		opened[account.ID] = today(account.CreatedAt)
+		if len(accountPostings) > 0 && accountPostings[0].CreatedAt.Before(opened[account.ID]) {
+			opened[account.ID] = today(accountPostings[0].CreatedAt)
+		}
+
+		if opened[account.ID].Before(start) {
+			start = opened[account.ID]
+		}
+	}
+
+	count := 0
+	pocketBalances := make(map[uuid.UUID]map[uuid.UUID]decimal.Decimal, len(accounts))
+	for date := start; date.Before(end); date = date.AddDate(0, 0, 1) {
+		next := date.AddDate(0, 0, 1)
+
+		var open []entity.Account
+		var pockets []entity.PocketSnapshot
+		for _, account := range accounts {
+			if opened[account.ID].After(date) {
+				continue
+			}
+			open = append(open, account)
+
+			balances, ok := pocketBalances[account.ID]
+			if !ok {
+				balances = make(map[uuid.UUID]decimal.Decimal)
+				pocketBalances[account.ID] = balances
+			}
+
+			for len(postings[account.ID]) > 0 && postings[account.ID][0].CreatedAt.Before(next) {
+				p := postings[account.ID][0]
+				balances[*p.PocketID] = balances[*p.PocketID].Add(p.Amount)
+				postings[account.ID] = postings[account.ID][1:]
+			}
+
+			pocketIDs := make([]uuid.UUID, 0, len(balances))
+			for id := range balances {
+				pocketIDs = append(pocketIDs, id)
+			}
+			sort.Slice(pocketIDs, func(i, j int) bool {
+				return pocketIDs[i].String() < pocketIDs[j].String()
+			})
+
+			for _, id := range pocketIDs {
+				pockets = append(pockets, entity.PocketSnapshot{
+					Date:      date,
+					AccountID: account.ID,
+					PocketID:  id,
+					Balance:   balances[id],
+				})
+			}
+		}
+
+		snapshot, err := u.buildSnapshot(ctx, userID, open, pockets, date)
+		if err != nil {
+			return count, errors.WithStack(err)
+		}
+
+		if err := u.snapshotRepo.SaveDailySnapshot(ctx, *snapshot); err != nil {
+			return count, errors.Wrap(err, "failed to save snapshot")
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// GetSeries returns the user's net worth in [from, to], one point per
+// interval, each taken from the last snapshot in the interval.
+func (u *usecase) GetSeries(ctx context.Context, userID uuid.UUID, from, to time.Time, interval entity.Interval) (*entity.NetWorthSeries, error) {
+	if !interval.IsValid() {
+		return nil, errors.WithStack(entity.ErrInvalidInterval)
+	}
+
+	baseCurrency, err := u.currencyUsecase.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base currency")
+	}
+
+	netWorths, err := u.snapshotRepo.GetNetWorthSnapshots(ctx, userID, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get net worth snapshots")
+	}
+
+	accounts, err := u.snapshotRepo.GetAccountSnapshots(ctx, userID, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account snapshots")
+	}
+
+	series := &entity.NetWorthSeries{
+		BaseCurrency: baseCurrency,
+		Interval:     interval,
+	}
+
+	// Snapshots are oldest first, so the last one of an interval replaces the others
+	index := make(map[string]int)
+	for _, s := range netWorths {
+		start := interval.Start(s.Date)
+		key := start.Format(dateLayout)
+		point := entity.NetWorthPoint{
+			Date:         start,
+			SnapshotDate: s.Date,
+			Total:        s.Total,
+			Assets:       s.Assets,
+			Liabilities:  s.Liabilities,
+			ByType:       make(map[entity.AccountType]decimal.Decimal),
+		}
+
+		if i, ok := index[key]; ok {
+			series.Points[i] = point
+			continue
+		}
+		index[key] = len(series.Points)
+		series.Points = append(series.Points, point)
+	}
+
+	for _, s := range accounts {
+		i, ok := index[interval.Start(s.Date).Format(dateLayout)]
+		if !ok || !series.Points[i].SnapshotDate.Equal(s.Date) || s.BaseBalance == nil {
+			continue
+		}
+
+		series.Points[i].ByType[s.AccountType] = series.Points[i].ByType[s.AccountType].Add(*s.BaseBalance)
+	}
+
+	return series, nil
+}
@@ -1,9 +1,13 @@
 package pocket
 
 import (
+	"regexp"
+	"time"
+
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/idempotency"
 	"github.com/cockroachdb/errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -11,27 +15,38 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const dateLayout = "2006-01-02"
+
+// colorPattern matches a hex color such as #1E90FF.
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
 type controller struct {
-	usecase        *Usecase
-	authMiddleware authentication.AuthMiddleware
+	usecase               *Usecase
+	authMiddleware        authentication.AuthMiddleware
+	idempotencyMiddleware idempotency.IdempotencyMiddleware
 }
 
-func NewController(pocketUsecase *Usecase, authMiddleware authentication.AuthMiddleware) *controller {
+func NewController(pocketUsecase *Usecase, authMiddleware authentication.AuthMiddleware, idempotencyMiddleware idempotency.IdempotencyMiddleware) *controller {
 	return &controller{
-		usecase:        pocketUsecase,
-		authMiddleware: authMiddleware,
+		usecase:               pocketUsecase,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
 	}
 }
 
 func (h *controller) Mount(r fiber.Router) {
 	r.Get("/account/:id", h.GetPocketsByAccountID)
 	r.Get("/:id", h.GetPocket)
+	r.Get("/:id/transactions", h.GetPocketTransactions)
 	r.Post("/", h.CreatePocket)
+	r.Put("/reorder", h.ReorderPockets)
 	r.Put("/:id", h.UpdatePocket)
 	r.Delete("/:id", h.DeletePocket)
+	r.Put("/:id/goal", h.SetGoal)
+	r.Delete("/:id/goal", h.DeleteGoal)
 
-	r.Post("/:id/transfer", h.Transfer)
-	r.Post("/:id/withdraw", h.Withdraw)
+	r.Post("/:id/transfer", h.idempotencyMiddleware.Handle, h.Transfer)
+	r.Post("/:id/withdraw", h.idempotencyMiddleware.Handle, h.Withdraw)
 }
 
 type PocketResponse struct {
@@ -39,11 +54,72 @@ type PocketResponse struct {
 	AccountID uuid.UUID         `json:"accountId"`
 	Name      string            `json:"name"`
 	Type      entity.PocketType `json:"type"`
+	Currency  string            `json:"currency"`
 	Balance   decimal.Decimal   `json:"balance"`
+	SortOrder int               `json:"sortOrder"`
+	Color     string            `json:"color"`
+	Icon      string            `json:"icon"`
+	Archived  bool              `json:"archived"`
+	Goal      *goalResponse     `json:"goal"`
 	CreatedAt int64             `json:"createdAt"`
 	UpdatedAt int64             `json:"updatedAt"`
 }
 
+type goalResponse struct {
+	TargetAmount        decimal.Decimal  `json:"targetAmount"`
+	TargetDate          *string          `json:"targetDate"`
+	MonthlyContribution decimal.Decimal  `json:"monthlyContribution"`
+	Percent             decimal.Decimal  `json:"percent"`
+	Remaining           decimal.Decimal  `json:"remaining"`
+	MonthlySaving       decimal.Decimal  `json:"monthlySaving"`
+	MonthlyNeeded       *decimal.Decimal `json:"monthlyNeeded"`
+	ProjectedDate       *string          `json:"projectedDate"`
+}
+
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	date := t.Format(dateLayout)
+	return &date
+}
+
+func NewPocketResponse(pocket *entity.Pocket) PocketResponse {
+	res := PocketResponse{
+		ID:        pocket.ID,
+		AccountID: pocket.AccountID,
+		Name:      pocket.Name,
+		Type:      pocket.Type,
+		Currency:  pocket.Currency,
+		Balance:   pocket.Balance,
+		SortOrder: pocket.SortOrder,
+		Color:     pocket.Color,
+		Icon:      pocket.Icon,
+		Archived:  pocket.Archived,
+		CreatedAt: pocket.CreatedAt.Unix(),
+		UpdatedAt: pocket.UpdatedAt.Unix(),
+	}
+
+	if pocket.Goal != nil {
+		res.Goal = &goalResponse{
+			TargetAmount:        pocket.Goal.TargetAmount,
+			TargetDate:          formatDate(pocket.Goal.TargetDate),
+			MonthlyContribution: pocket.Goal.MonthlyContribution,
+		}
+	}
+
+	if res.Goal != nil && pocket.Progress != nil {
+		res.Goal.Percent = pocket.Progress.Percent
+		res.Goal.Remaining = pocket.Progress.Remaining
+		res.Goal.MonthlySaving = pocket.Progress.MonthlySaving
+		res.Goal.MonthlyNeeded = pocket.Progress.MonthlyNeeded
+		res.Goal.ProjectedDate = formatDate(pocket.Progress.ProjectedDate)
+	}
+
+	return res
+}
+
 func (h *controller) GetPocketsByAccountID(ctx *fiber.Ctx) error {
 	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
 	if err != nil {
@@ -59,22 +135,15 @@ func (h *controller) GetPocketsByAccountID(ctx *fiber.Ctx) error {
 		})
 	}
 
-	pockets, err := h.usecase.GetPocketsByAccountID(ctx.UserContext(), userID, accountID)
+	// Archived pockets are only listed on request
+	pockets, err := h.usecase.GetPocketsByAccountID(ctx.UserContext(), userID, accountID, ctx.QueryBool("archived"))
 	if err != nil {
 		return errors.Wrap(err, "failed to get pockets")
 	}
 
 	res := make([]PocketResponse, 0, len(pockets))
 	for _, pocket := range pockets {
-		res = append(res, PocketResponse{
-			ID:        pocket.ID,
-			AccountID: pocket.AccountID,
-			Name:      pocket.Name,
-			Type:      pocket.Type,
-			Balance:   pocket.Balance,
-			CreatedAt: pocket.CreatedAt.Unix(),
-			UpdatedAt: pocket.UpdatedAt.Unix(),
-		})
+		res = append(res, NewPocketResponse(&pocket))
 	}
 
 	return ctx.JSON(dto.HttpResponse{
@@ -103,21 +172,71 @@ func (h *controller) GetPocket(ctx *fiber.Ctx) error {
 	}
 
 	return ctx.JSON(dto.HttpResponse{
-		Result: PocketResponse{
-			ID:        pocket.ID,
-			AccountID: pocket.AccountID,
-			Name:      pocket.Name,
-			Type:      pocket.Type,
-			Balance:   pocket.Balance,
-			CreatedAt: pocket.CreatedAt.Unix(),
-			UpdatedAt: pocket.UpdatedAt.Unix(),
-		},
+		Result: NewPocketResponse(pocket),
+	})
+}
+
+type pocketTransactionResponse struct {
+	ID                     uuid.UUID       `json:"id"`
+	AccountID              uuid.UUID       `json:"accountId"`
+	Type                   entity.TxType   `json:"type"`
+	Currency               string          `json:"currency"`
+	Amount                 decimal.Decimal `json:"amount"`
+	Note                   string          `json:"note"`
+	Payee                  string          `json:"payee"`
+	CounterpartyPocketID   *uuid.UUID      `json:"counterpartyPocketId"`
+	CounterpartyPocketName string          `json:"counterpartyPocketName"`
+	RunningBalance         decimal.Decimal `json:"runningBalance"`
+	CreatedAt              int64           `json:"createdAt"`
+}
+
+func (h *controller) GetPocketTransactions(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	pocketID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Bad Request",
+		})
+	}
+
+	transactions, err := h.usecase.GetPocketTransactions(ctx.UserContext(), userID, pocketID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pocket transactions")
+	}
+
+	res := make([]pocketTransactionResponse, 0, len(transactions))
+	for _, t := range transactions {
+		res = append(res, pocketTransactionResponse{
+			ID:                     t.ID,
+			AccountID:              t.AccountID,
+			Type:                   t.Type,
+			Currency:               t.Currency,
+			Amount:                 t.SignedAmount,
+			Note:                   t.Note,
+			Payee:                  t.Payee,
+			CounterpartyPocketID:   t.CounterpartyPocketID,
+			CounterpartyPocketName: t.CounterpartyPocketName,
+			RunningBalance:         t.RunningBalance,
+			CreatedAt:              t.CreatedAt.Unix(),
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: res,
 	})
 }
 
 type createPocketRequest struct {
 	AccountID uuid.UUID `json:"accountId"`
 	Name      string    `json:"name"`
+	Color     *string   `json:"color"`
+	Icon      *string   `json:"icon"`
 }
 
 func (p *createPocketRequest) Parse(ctx *fiber.Ctx) error {
@@ -136,6 +255,8 @@ func (p *createPocketRequest) Validate() error {
 	v := validator.New()
 	v.Must(p.AccountID != uuid.Nil, "accountId is required")
 	v.Must(p.Name != "", "name is required")
+	v.Must(p.Color == nil || *p.Color == "" || colorPattern.MatchString(*p.Color), "color must be a hex color like #1E90FF")
+	v.Must(p.Icon == nil || len(*p.Icon) <= 64, "icon must be at most 64 characters")
 
 	return errors.WithStack(v.Error())
 }
@@ -159,27 +280,24 @@ func (h *controller) CreatePocket(ctx *fiber.Ctx) error {
 		UserID:    userID,
 		AccountID: req.AccountID,
 		Name:      req.Name,
+		Color:     req.Color,
+		Icon:      req.Icon,
 	})
 	if err != nil {
 		return errors.Wrap(err, "failed to create pocket")
 	}
 
 	return ctx.JSON(dto.HttpResponse{
-		Result: PocketResponse{
-			ID:        pocket.ID,
-			AccountID: pocket.AccountID,
-			Name:      pocket.Name,
-			Type:      pocket.Type,
-			Balance:   pocket.Balance,
-			CreatedAt: pocket.CreatedAt.Unix(),
-			UpdatedAt: pocket.UpdatedAt.Unix(),
-		},
+		Result: NewPocketResponse(pocket),
 	})
 }
 
 type updatePocketRequest struct {
-	Id   uuid.UUID `params:"id"`
-	Name string    `json:"name"`
+	Id       uuid.UUID `params:"id"`
+	Name     string    `json:"name"`
+	Color    *string   `json:"color"`
+	Icon     *string   `json:"icon"`
+	Archived *bool     `json:"archived"`
 }
 
 func (p *updatePocketRequest) Parse(ctx *fiber.Ctx) error {
@@ -201,7 +319,9 @@ func (p *updatePocketRequest) Parse(ctx *fiber.Ctx) error {
 func (p *updatePocketRequest) Validate() error {
 	v := validator.New()
 	v.Must(p.Id != uuid.Nil, "id is required")
-	v.Must(p.Name != "", "name is required")
+	v.Must(p.Name != "" || p.Color != nil || p.Icon != nil || p.Archived != nil, "name, color, icon or archived is required")
+	v.Must(p.Color == nil || *p.Color == "" || colorPattern.MatchString(*p.Color), "color must be a hex color like #1E90FF")
+	v.Must(p.Icon == nil || len(*p.Icon) <= 64, "icon must be at most 64 characters")
 
 	return errors.WithStack(v.Error())
 }
@@ -222,26 +342,240 @@ func (h *controller) UpdatePocket(ctx *fiber.Ctx) error {
 	}
 
 	pocket, err := h.usecase.UpdatePocket(ctx.UserContext(), userID, req.Id, entity.PocketInput{
-		Name: req.Name,
+		Name:     req.Name,
+		Color:    req.Color,
+		Icon:     req.Icon,
+		Archived: req.Archived,
 	})
+	if errors.Is(err, ErrArchiveCashbox) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Cashbox pockets cannot be archived",
+		})
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to update pocket")
 	}
 
 	return ctx.JSON(dto.HttpResponse{
-		Result: PocketResponse{
-			ID:        pocket.ID,
-			AccountID: pocket.AccountID,
-			Name:      pocket.Name,
-			Type:      pocket.Type,
-			Balance:   pocket.Balance,
-			CreatedAt: pocket.CreatedAt.Unix(),
-			UpdatedAt: pocket.UpdatedAt.Unix(),
-		},
+		Result: NewPocketResponse(pocket),
 	})
 }
 
+type reorderPocketsRequest struct {
+	AccountID uuid.UUID   `json:"accountId"`
+	PocketIDs []uuid.UUID `json:"pocketIds"` // In display order
+}
+
+func (p *reorderPocketsRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.BodyParser(p); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := p.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (p *reorderPocketsRequest) Validate() error {
+	v := validator.New()
+	v.Must(p.AccountID != uuid.Nil, "accountId is required")
+	v.Must(len(p.PocketIDs) > 0, "pocketIds is required")
+
+	return errors.WithStack(v.Error())
+}
+
+// ReorderPockets saves the order of the pockets of an account, as arranged by
+// drag and drop.
+func (h *controller) ReorderPockets(ctx *fiber.Ctx) error {
+	var req reorderPocketsRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	err = h.usecase.ReorderPockets(ctx.UserContext(), userID, req.AccountID, req.PocketIDs)
+	if errors.Is(err, ErrInvalidOrder) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Pockets must belong to the account and be listed once",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to reorder pockets")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "success",
+	})
+}
+
+type deletePocketRequest struct {
+	Id         uuid.UUID `params:"id"`
+	ToPocketID string    `query:"toPocketId"` // Takes the remaining balance, defaults to the cashbox
+
+	toPocketID *uuid.UUID
+}
+
+func (p *deletePocketRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(p); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.QueryParser(p); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if p.ToPocketID != "" {
+		toPocketID, err := uuid.Parse(p.ToPocketID)
+		if err != nil {
+			return errors.Wrap(err, "invalid toPocketId")
+		}
+		p.toPocketID = &toPocketID
+	}
+
+	if err := p.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (p *deletePocketRequest) Validate() error {
+	v := validator.New()
+	v.Must(p.Id != uuid.Nil, "id is required")
+	v.Must(p.toPocketID == nil || *p.toPocketID != p.Id, "toPocketId must be another pocket")
+
+	return errors.WithStack(v.Error())
+}
+
+// DeletePocket moves the remaining balance of the pocket to another pocket of
+// the account, then deletes it.
 func (h *controller) DeletePocket(ctx *fiber.Ctx) error {
+	var req deletePocketRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	err = h.usecase.DeletePocket(ctx.UserContext(), userID, req.Id, req.toPocketID)
+	if errors.Is(err, ErrDeleteCashbox) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Cashbox pockets cannot be deleted",
+		})
+	}
+	if errors.Is(err, ErrInvalidSweep) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "toPocketId must be another pocket of the same account",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to delete pocket")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "success",
+	})
+}
+
+type setGoalRequest struct {
+	Id                  uuid.UUID       `params:"id"`
+	TargetAmount        decimal.Decimal `json:"targetAmount"`
+	TargetDate          string          `json:"targetDate"` // 2006-01-02, optional
+	MonthlyContribution decimal.Decimal `json:"monthlyContribution"`
+
+	targetDate *time.Time
+}
+
+func (p *setGoalRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.ParamsParser(p); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := ctx.BodyParser(p); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if p.TargetDate != "" {
+		targetDate, err := time.Parse(dateLayout, p.TargetDate)
+		if err != nil {
+			return errors.Wrap(err, "invalid targetDate")
+		}
+		p.targetDate = &targetDate
+	}
+
+	if err := p.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (p *setGoalRequest) Validate() error {
+	v := validator.New()
+	v.Must(p.Id != uuid.Nil, "id is required")
+	v.Must(p.TargetAmount.IsPositive(), "targetAmount must be positive")
+	v.Must(!p.MonthlyContribution.IsNegative(), "monthlyContribution must not be negative")
+
+	return errors.WithStack(v.Error())
+}
+
+// SetGoal sets the savings goal of the pocket.
+func (h *controller) SetGoal(ctx *fiber.Ctx) error {
+	var req setGoalRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	pocket, err := h.usecase.SetGoal(ctx.UserContext(), entity.PocketGoalInput{
+		UserID:   userID,
+		PocketID: req.Id,
+		Goal: &entity.PocketGoal{
+			TargetAmount:        req.TargetAmount,
+			TargetDate:          req.targetDate,
+			MonthlyContribution: req.MonthlyContribution,
+		},
+	})
+	if errors.Is(err, ErrCashboxGoal) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Cashbox pockets cannot have a goal",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set pocket goal")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: NewPocketResponse(pocket),
+	})
+}
+
+func (h *controller) DeleteGoal(ctx *fiber.Ctx) error {
 	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
 	if err != nil {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
@@ -256,12 +590,16 @@ func (h *controller) DeletePocket(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.usecase.DeletePocket(ctx.UserContext(), userID, pocketID); err != nil {
-		return errors.Wrap(err, "failed to delete pocket")
+	pocket, err := h.usecase.SetGoal(ctx.UserContext(), entity.PocketGoalInput{
+		UserID:   userID,
+		PocketID: pocketID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete pocket goal")
 	}
 
 	return ctx.JSON(dto.HttpResponse{
-		Result: "success",
+		Result: NewPocketResponse(pocket),
 	})
 }
 
@@ -269,6 +607,8 @@ type transferRequest struct {
 	FromPocketID uuid.UUID       `params:"id"`
 	ToPocketID   uuid.UUID       `json:"toPocketId"`
 	Amount       decimal.Decimal `json:"amount"`
+	Note         string          `json:"note"`
+	FeePocketID  *uuid.UUID      `json:"feePocketId"`
 }
 
 func (p *transferRequest) Parse(ctx *fiber.Ctx) error {
@@ -291,7 +631,7 @@ func (p *transferRequest) Validate() error {
 	v := validator.New()
 	v.Must(p.FromPocketID != uuid.Nil, "fromPocketId is required")
 	v.Must(p.ToPocketID != uuid.Nil, "toPocketId is required")
-	v.Must(!p.Amount.IsZero(), "amount is required")
+	v.Must(p.Amount.IsPositive(), "amount must be positive")
 
 	return errors.WithStack(v.Error())
 }
@@ -311,7 +651,35 @@ func (h *controller) Transfer(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.usecase.Transfer(ctx.UserContext(), userID, req.FromPocketID, req.ToPocketID, req.Amount); err != nil {
+	err = h.usecase.Transfer(ctx.UserContext(), entity.TransferInput{
+		UserID:       userID,
+		FromPocketID: req.FromPocketID,
+		ToPocketID:   req.ToPocketID,
+		Amount:       req.Amount,
+		Note:         req.Note,
+		FeePocketID:  req.FeePocketID,
+	})
+	if errors.Is(err, ErrInvalidFeePocket) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Fee pocket must belong to the sending account",
+		})
+	}
+	if errors.Is(err, entity.ErrCurrencyMismatch) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Pockets must have the same currency",
+		})
+	}
+	if errors.Is(err, ErrSamePocket) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Can't transfer to the same pocket",
+		})
+	}
+	if errors.Is(err, entity.ErrInsufficientBalance) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	}
+	if err != nil {
 		return errors.Wrap(err, "failed to transfer")
 	}
 
@@ -323,6 +691,8 @@ func (h *controller) Transfer(ctx *fiber.Ctx) error {
 type withdrawRequest struct {
 	Id     uuid.UUID       `params:"id"`
 	Amount decimal.Decimal `json:"amount"`
+	Note   string          `json:"note"`
+	Payee  string          `json:"payee"`
 }
 
 func (p *withdrawRequest) Parse(ctx *fiber.Ctx) error {
@@ -344,7 +714,7 @@ func (p *withdrawRequest) Parse(ctx *fiber.Ctx) error {
 func (p *withdrawRequest) Validate() error {
 	v := validator.New()
 	v.Must(p.Id != uuid.Nil, "id is required")
-	v.Must(!p.Amount.IsZero(), "amount is required")
+	v.Must(p.Amount.IsPositive(), "amount must be positive")
 
 	return errors.WithStack(v.Error())
 }
@@ -364,7 +734,13 @@ func (h *controller) Withdraw(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.usecase.Withdraw(ctx.UserContext(), userID, req.Id, req.Amount); err != nil {
+	err = h.usecase.Withdraw(ctx.UserContext(), userID, req.Id, req.Amount, req.Note, req.Payee)
+	if errors.Is(err, entity.ErrInsufficientBalance) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	}
+	if err != nil {
 		return errors.Wrap(err, "failed to withdraw")
 	}
 
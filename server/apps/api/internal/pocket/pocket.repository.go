@@ -2,19 +2,23 @@ package pocket
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
 	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrInsufficientBalance = errors.New("INSUFFICIENT_BALANCE")
+	ErrInsufficientBalance = entity.ErrInsufficientBalance
+	ErrSamePocket          = errors.New("SAME_POCKET")
 )
 
 type repository struct {
@@ -29,73 +33,114 @@ func NewRepository(db *gorm.DB) interfaces.PocketRepository {
 	}
 }
 
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func pocketFromModel(p *model.Pocket) *entity.Pocket {
+	pocket := &entity.Pocket{
+		ID:        p.ID,
+		AccountID: p.AccountID,
+		Name:      p.Name,
+		Type:      p.Type,
+		Currency:  p.Currency,
+		Balance:   p.Balance,
+		SortOrder: p.SortOrder,
+		Color:     p.Color,
+		Icon:      p.Icon,
+		Archived:  p.Archived,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+
+	if p.GoalAmount.Valid {
+		pocket.Goal = &entity.PocketGoal{
+			TargetAmount:        p.GoalAmount.Decimal,
+			TargetDate:          p.GoalDate,
+			MonthlyContribution: p.GoalMonthlyContribution.Decimal,
+		}
+	}
+
+	return pocket
+}
+
 func (r *repository) GetPocketsByAccountID(ctx context.Context, accountID uuid.UUID) ([]entity.Pocket, error) {
 	var pockets []*model.Pocket
-	if err := r.db.Where("account_id = ?", accountID).Order("created_at asc").Find(&pockets).Error; err != nil {
+	if err := r.getDB(ctx).Where("account_id = ?", accountID).Order("sort_order asc, created_at asc").Find(&pockets).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get pockets")
 	}
 
 	var result []entity.Pocket
 	for _, p := range pockets {
-		result = append(result, entity.Pocket{
-			ID:        p.ID,
-			AccountID: p.AccountID,
-			Name:      p.Name,
-			Type:      p.Type,
-			Balance:   p.Balance,
-			CreatedAt: p.CreatedAt,
-			UpdatedAt: p.UpdatedAt,
-		})
+		result = append(result, *pocketFromModel(p))
 	}
 
 	return result, nil
 }
 
+func (r *repository) GetCashboxPocket(ctx context.Context, accountID uuid.UUID) (*entity.Pocket, error) {
+	var pocket model.Pocket
+	err := r.getDB(ctx).Where("account_id = ? AND type = ?", accountID, entity.PocketTypeCashBox).Order("created_at asc").First(&pocket).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(entity.ErrCashboxNotFound, "account %s has no cashbox", accountID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cashbox pocket")
+	}
+
+	return pocketFromModel(&pocket), nil
+}
+
 func (r *repository) GetPocketByID(ctx context.Context, userID uuid.UUID, pocketID uuid.UUID) (*entity.Pocket, error) {
 	var pocket model.Pocket
 	// where userID == pocket.Account.UserID
-	if err := r.db.Where("account_id IN (?)", r.db.Model(&model.Account{}).Select("id").Where("user_id = ?", userID)).First(&pocket, pocketID).Error; err != nil {
+	if err := r.getDB(ctx).Where("account_id IN (?)", r.getDB(ctx).Model(&model.Account{}).Select("id").Where("user_id = ?", userID)).First(&pocket, pocketID).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get pocket")
 	}
 
-	return &entity.Pocket{
-		ID:        pocket.ID,
-		AccountID: pocket.AccountID,
-		Name:      pocket.Name,
-		Type:      pocket.Type,
-		Balance:   pocket.Balance,
-		CreatedAt: pocket.CreatedAt,
-		UpdatedAt: pocket.UpdatedAt,
-	}, nil
+	return pocketFromModel(&pocket), nil
 }
 
 func (r *repository) CreatePocket(ctx context.Context, input entity.PocketInput) (*entity.Pocket, error) {
+	pocketType := input.Type
+	if pocketType == "" {
+		pocketType = entity.PocketTypeNormal
+	}
+
+	// New pockets go last
+	var sortOrder int
+	if err := r.getDB(ctx).Model(&model.Pocket{}).Select("COALESCE(MAX(sort_order) + 1, 0)").Where("account_id = ?", input.AccountID).Scan(&sortOrder).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get sort order")
+	}
+
 	p := model.Pocket{
 		ID:        uuid.New(),
 		AccountID: input.AccountID,
 		Name:      input.Name,
-		Type:      entity.PocketTypeNormal,
+		Type:      pocketType,
+		Currency:  input.Currency,
 		Balance:   decimal.NewFromInt(0), // Initial balance is 0
+		SortOrder: sortOrder,
 	}
 
-	if err := r.db.Create(&p).Error; err != nil {
+	if input.Color != nil {
+		p.Color = *input.Color
+	}
+
+	if input.Icon != nil {
+		p.Icon = *input.Icon
+	}
+
+	if err := r.getDB(ctx).Create(&p).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to create pocket")
 	}
 
-	return &entity.Pocket{
-		ID:        p.ID,
-		AccountID: p.AccountID,
-		Name:      p.Name,
-		Type:      p.Type,
-		Balance:   p.Balance,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
-	}, nil
+	return pocketFromModel(&p), nil
 }
 
 func (r *repository) UpdatePocket(ctx context.Context, id uuid.UUID, input entity.PocketInput) (*entity.Pocket, error) {
 	var p model.Pocket
-	if err := r.db.First(&p, id).Error; err != nil {
+	if err := r.getDB(ctx).First(&p, id).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get pocket")
 	}
 
@@ -103,106 +148,225 @@ func (r *repository) UpdatePocket(ctx context.Context, id uuid.UUID, input entit
 		p.Name = input.Name
 	}
 
+	if input.Color != nil {
+		p.Color = *input.Color
+	}
+
+	if input.Icon != nil {
+		p.Icon = *input.Icon
+	}
+
+	if input.Archived != nil {
+		p.Archived = *input.Archived
+	}
+
 	p.UpdatedAt = time.Now()
 
-	if err := r.db.Save(&p).Error; err != nil {
+	if err := r.getDB(ctx).Save(&p).Error; err != nil {
 		return nil, err
 	}
 
-	return &entity.Pocket{
-		ID:        p.ID,
-		AccountID: p.AccountID,
-		Name:      p.Name,
-		Type:      p.Type,
-		Balance:   p.Balance,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
-	}, nil
+	return pocketFromModel(&p), nil
+}
+
+// SetPocketGoal replaces the savings goal of the pocket, a nil goal removes it.
+func (r *repository) SetPocketGoal(ctx context.Context, pocketID uuid.UUID, goal *entity.PocketGoal) (*entity.Pocket, error) {
+	var p model.Pocket
+	if err := r.getDB(ctx).First(&p, pocketID).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket")
+	}
+
+	p.GoalAmount = decimal.NullDecimal{}
+	p.GoalDate = nil
+	p.GoalMonthlyContribution = decimal.NullDecimal{}
+	if goal != nil {
+		p.GoalAmount = decimal.NewNullDecimal(goal.TargetAmount)
+		p.GoalDate = goal.TargetDate
+		p.GoalMonthlyContribution = decimal.NewNullDecimal(goal.MonthlyContribution)
+	}
+	p.UpdatedAt = time.Now()
+
+	if err := r.getDB(ctx).Save(&p).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to set pocket goal")
+	}
+
+	return pocketFromModel(&p), nil
 }
 
+// ReorderPockets numbers the given pockets of the account from 0 in order, then
+// the rest after them in their current order.
+func (r *repository) ReorderPockets(ctx context.Context, accountID uuid.UUID, ids []uuid.UUID) error {
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var rest []uuid.UUID
+		if err := tx.Model(&model.Pocket{}).Where("account_id = ? AND id NOT IN ?", accountID, ids).Order("sort_order asc, created_at asc").Pluck("id", &rest).Error; err != nil {
+			return errors.Wrap(err, "failed to get pockets")
+		}
+
+		for i, id := range slices.Concat(ids, rest) {
+			if err := tx.Model(&model.Pocket{}).Where("account_id = ? AND id = ?", accountID, id).Update("sort_order", i).Error; err != nil {
+				return errors.Wrap(err, "failed to update sort order")
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeletePocket soft-deletes the pocket, which then no longer shows up in or
+// can be changed through this repository.
 func (r *repository) DeletePocket(ctx context.Context, pocketID uuid.UUID) error {
-	if err := r.db.Delete(&model.Pocket{}, pocketID).Error; err != nil {
+	if err := r.getDB(ctx).Delete(&model.Pocket{}, pocketID).Error; err != nil {
 		return errors.Wrap(err, "failed to delete pocket")
 	}
 
 	return nil
 }
 
-// Deposit can be done only Cashbox pocket
-func (r *repository) Deposit(ctx context.Context, pocketID uuid.UUID, amount decimal.Decimal) error {
-	var pocket model.Pocket
-	if err := r.db.First(&pocket, pocketID).Error; err != nil {
-		return errors.Wrap(err, "failed to get pocket")
+// canOverdraw reports whether the pocket may go below zero, which only pockets
+// of liability accounts can.
+func canOverdraw(tx *gorm.DB, pocket *model.Pocket) (bool, error) {
+	var accountType entity.AccountType
+	if err := tx.Model(&model.Account{}).Select("type").Where("id = ?", pocket.AccountID).Scan(&accountType).Error; err != nil {
+		return false, errors.Wrap(err, "failed to get account type")
 	}
 
-	if pocket.Type != entity.PocketTypeCashBox {
-		return errors.Wrap(errors.New("INVALID_POCKET"), "failed to deposit")
+	return accountType.IsLiability(), nil
+}
+
+// lockPockets selects the pockets with SELECT ... FOR UPDATE ordered by id, so
+// concurrent balance mutations always acquire row locks in the same order.
+func lockPockets(tx *gorm.DB, ids ...uuid.UUID) (map[uuid.UUID]*model.Pocket, error) {
+	var pockets []*model.Pocket
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&pockets).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to lock pockets")
 	}
 
-	// TODO: Lock db transaction
-	pocket.Balance = pocket.Balance.Add(amount)
-	pocket.UpdatedAt = time.Now()
+	result := make(map[uuid.UUID]*model.Pocket, len(pockets))
+	for _, p := range pockets {
+		result[p.ID] = p
+	}
 
-	if err := r.db.Save(&pocket).Error; err != nil {
-		return errors.Wrap(err, "failed to deposit")
+	for _, id := range ids {
+		if _, ok := result[id]; !ok {
+			return nil, errors.Wrap(gorm.ErrRecordNotFound, "failed to get pocket")
+		}
 	}
 
-	return nil
+	return result, nil
 }
 
-func (r *repository) Transfer(ctx context.Context, fromPocketID, toPocketID uuid.UUID, amount decimal.Decimal) error {
-	var fromPocket model.Pocket
-	if err := r.db.First(&fromPocket, fromPocketID).Error; err != nil {
-		return errors.Wrap(err, "failed to get pocket")
+// Deposit credits any pocket. Money enters an account only through its cashbox,
+// which the callers are responsible for.
+func (r *repository) Deposit(ctx context.Context, pocketID uuid.UUID, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to deposit")
 	}
 
-	var toPocket model.Pocket
-	if err := r.db.First(&toPocket, toPocketID).Error; err != nil {
-		return errors.Wrap(err, "failed to get pocket")
-	}
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		pockets, err := lockPockets(tx, pocketID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pocket")
+		}
+		pocket := pockets[pocketID]
 
-	if fromPocket.Balance.LessThan(amount) {
-		return errors.Wrap(ErrInsufficientBalance, "failed to transfer")
-	}
+		pocket.Balance = pocket.Balance.Add(amount)
+		pocket.UpdatedAt = time.Now()
 
-	fromPocket.Balance = fromPocket.Balance.Sub(amount)
-	fromPocket.UpdatedAt = time.Now()
+		if err := tx.Save(pocket).Error; err != nil {
+			return errors.Wrap(err, "failed to deposit")
+		}
 
-	toPocket.Balance = toPocket.Balance.Add(amount)
-	toPocket.UpdatedAt = time.Now()
+		return nil
+	})
+}
 
-	tx := r.db.Begin()
-	if err := tx.Save(&fromPocket).Error; err != nil {
-		tx.Rollback()
-		return errors.Wrap(err, "failed to transfer")
+func (r *repository) Transfer(ctx context.Context, fromPocketID, toPocketID uuid.UUID, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to transfer")
 	}
 
-	if err := tx.Save(&toPocket).Error; err != nil {
-		tx.Rollback()
-		return errors.Wrap(err, "failed to transfer")
+	if fromPocketID == toPocketID {
+		return errors.Wrap(ErrSamePocket, "failed to transfer")
 	}
 
-	tx.Commit()
-
-	return nil
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		pockets, err := lockPockets(tx, fromPocketID, toPocketID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pocket")
+		}
+		fromPocket, toPocket := pockets[fromPocketID], pockets[toPocketID]
+
+		if fromPocket.Balance.LessThan(amount) {
+			overdraw, err := canOverdraw(tx, fromPocket)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if !overdraw {
+				return errors.Wrap(ErrInsufficientBalance, "failed to transfer")
+			}
+		}
+
+		fromPocket.Balance = fromPocket.Balance.Sub(amount)
+		fromPocket.UpdatedAt = time.Now()
+
+		toPocket.Balance = toPocket.Balance.Add(amount)
+		toPocket.UpdatedAt = time.Now()
+
+		if err := tx.Save(fromPocket).Error; err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		if err := tx.Save(toPocket).Error; err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		return nil
+	})
 }
 
 func (r *repository) Withdraw(ctx context.Context, pocketID uuid.UUID, amount decimal.Decimal) error {
-	var pocket model.Pocket
-	if err := r.db.First(&pocket, pocketID).Error; err != nil {
-		return errors.Wrap(err, "failed to get pocket")
+	if !amount.IsPositive() {
+		return errors.Wrap(entity.ErrInvalidAmount, "failed to withdraw")
 	}
 
-	if pocket.Balance.LessThan(amount) {
-		return errors.Wrap(ErrInsufficientBalance, "failed to withdraw")
-	}
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		pockets, err := lockPockets(tx, pocketID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pocket")
+		}
+		pocket := pockets[pocketID]
+
+		if pocket.Balance.LessThan(amount) {
+			overdraw, err := canOverdraw(tx, pocket)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			if !overdraw {
+				return errors.Wrap(ErrInsufficientBalance, "failed to withdraw")
+			}
+		}
+
+		pocket.Balance = pocket.Balance.Sub(amount)
+		pocket.UpdatedAt = time.Now()
+
+		if err := tx.Save(pocket).Error; err != nil {
+			return errors.Wrap(err, "failed to withdraw")
+		}
+
+		return nil
+	})
+}
 
-	pocket.Balance = pocket.Balance.Sub(amount)
-	pocket.UpdatedAt = time.Now()
+func (r *repository) LockPocketsByAccountID(ctx context.Context, accountID uuid.UUID) ([]entity.Pocket, error) {
+	var pockets []*model.Pocket
+	if err := r.getDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("account_id = ?", accountID).Order("id").Find(&pockets).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to lock pockets")
+	}
 
-	if err := r.db.Save(&pocket).Error; err != nil {
-		return errors.Wrap(err, "failed to withdraw")
+	var result []entity.Pocket
+	for _, p := range pockets {
+		result = append(result, *pocketFromModel(p))
 	}
 
-	return nil
+	return result, nil
 }
@@ -2,6 +2,7 @@ package pocket
 
 import (
 	"context"
+	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
@@ -10,17 +11,37 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrInvalidFeePocket = errors.New("INVALID_FEE_POCKET")
+	ErrCashboxGoal      = errors.New("CASHBOX_GOAL")
+	ErrArchiveCashbox   = errors.New("ARCHIVE_CASHBOX")
+	ErrInvalidOrder     = errors.New("INVALID_POCKET_ORDER")
+	ErrDeleteCashbox    = errors.New("DELETE_CASHBOX")
+	ErrInvalidSweep     = errors.New("INVALID_SWEEP_POCKET")
+)
+
+// goalHistoryMonths is how far back the saving pace of a goal is measured.
+const goalHistoryMonths = 3
+
+type Config struct {
+	CrossAccountFee float64 `mapstructure:"cross_account_fee"` // Charged on transfers between accounts, 0 disables
+}
+
 type Usecase struct {
+	txManager       interfaces.TxManager
 	pocketRepo      interfaces.PocketRepository
 	accountRepo     interfaces.AccountRepository
 	transactionRepo interfaces.TransactionRepository
+	config          *Config
 }
 
-func NewUsecase(pocketRepo interfaces.PocketRepository, accountRepo interfaces.AccountRepository, transactionRepo interfaces.TransactionRepository) *Usecase {
+func NewUsecase(txManager interfaces.TxManager, pocketRepo interfaces.PocketRepository, accountRepo interfaces.AccountRepository, transactionRepo interfaces.TransactionRepository, config *Config) *Usecase {
 	return &Usecase{
+		txManager:       txManager,
 		pocketRepo:      pocketRepo,
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
+		config:          config,
 	}
 }
 
@@ -30,28 +51,65 @@ func (u *Usecase) GetPocket(ctx context.Context, userID, pocketID uuid.UUID) (*e
 		return nil, errors.Wrap(err, "failed to get pocket")
 	}
 
+	if err := u.setProgress(ctx, pocket.AccountID, []*entity.Pocket{pocket}); err != nil {
+		return nil, errors.Wrap(err, "failed to get goal progress")
+	}
+
 	return pocket, nil
 }
 
-func (u *Usecase) GetPocketsByAccountID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]entity.Pocket, error) {
+// GetPocketsByAccountID lists the pockets of the account in their sort order,
+// leaving out archived pockets unless includeArchived is set.
+func (u *Usecase) GetPocketsByAccountID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID, includeArchived bool) ([]entity.Pocket, error) {
 	// Check account ownership
 	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
 		return nil, errors.Wrap(err, "failed to get account")
 	}
 
-	pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, accountID)
+	all, err := u.pocketRepo.GetPocketsByAccountID(ctx, accountID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get pockets")
 	}
 
+	pockets := make([]entity.Pocket, 0, len(all))
+	for _, p := range all {
+		if includeArchived || !p.Archived {
+			pockets = append(pockets, p)
+		}
+	}
+
+	withGoals := make([]*entity.Pocket, 0, len(pockets))
+	for i := range pockets {
+		withGoals = append(withGoals, &pockets[i])
+	}
+	if err := u.setProgress(ctx, accountID, withGoals); err != nil {
+		return nil, errors.Wrap(err, "failed to get goal progress")
+	}
+
 	return pockets, nil
 }
 
+func (u *Usecase) GetPocketTransactions(ctx context.Context, userID, pocketID uuid.UUID) ([]entity.PocketTransaction, error) {
+	// Check ownership
+	if _, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID); err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket")
+	}
+
+	transactions, err := u.transactionRepo.GetPocketTransactions(ctx, pocketID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket transactions")
+	}
+
+	return transactions, nil
+}
+
 func (u *Usecase) CreatePocket(ctx context.Context, input entity.PocketInput) (*entity.Pocket, error) {
 	// Check account ownership
-	if _, err := u.accountRepo.GetUserAccount(ctx, input.UserID, input.AccountID); err != nil {
+	account, err := u.accountRepo.GetUserAccount(ctx, input.UserID, input.AccountID)
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to get account")
 	}
+	input.Currency = account.Currency
 
 	pocket, err := u.pocketRepo.CreatePocket(ctx, input)
 	if err != nil {
@@ -63,82 +121,342 @@ func (u *Usecase) CreatePocket(ctx context.Context, input entity.PocketInput) (*
 
 func (u *Usecase) UpdatePocket(ctx context.Context, userID, pocketID uuid.UUID, input entity.PocketInput) (*entity.Pocket, error) {
 	// Check ownership
-	if _, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID); err != nil {
+	current, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID)
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to get pocket")
 	}
 
+	// Deposits land in the cashbox, so it must stay visible
+	if current.Type == entity.PocketTypeCashBox && input.Archived != nil && *input.Archived {
+		return nil, errors.WithStack(ErrArchiveCashbox)
+	}
+
 	pocket, err := u.pocketRepo.UpdatePocket(ctx, pocketID, input)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to update pocket")
 	}
 
+	if err := u.setProgress(ctx, pocket.AccountID, []*entity.Pocket{pocket}); err != nil {
+		return nil, errors.Wrap(err, "failed to get goal progress")
+	}
+
 	return pocket, nil
 }
 
-func (u *Usecase) DeletePocket(ctx context.Context, userID, pocketID uuid.UUID) error {
+// ReorderPockets saves the order of the pockets of an account. The given
+// pockets come first, the ones left out keep their order after them.
+func (u *Usecase) ReorderPockets(ctx context.Context, userID, accountID uuid.UUID, pocketIDs []uuid.UUID) error {
+	// Check account ownership
+	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
+		return errors.Wrap(err, "failed to get account")
+	}
+
+	pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, accountID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pockets")
+	}
+
+	valid := make(map[uuid.UUID]bool, len(pockets))
+	for _, p := range pockets {
+		valid[p.ID] = true
+	}
+
+	for _, id := range pocketIDs {
+		if !valid[id] {
+			return errors.WithStack(ErrInvalidOrder)
+		}
+		// Each pocket can be listed once
+		valid[id] = false
+	}
+
+	if err := u.pocketRepo.ReorderPockets(ctx, accountID, pocketIDs); err != nil {
+		return errors.Wrap(err, "failed to reorder pockets")
+	}
+
+	return nil
+}
+
+// SetGoal sets or, with a nil goal, removes the savings goal of a NORMAL pocket.
+func (u *Usecase) SetGoal(ctx context.Context, input entity.PocketGoalInput) (*entity.Pocket, error) {
 	// Check ownership
-	if _, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID); err != nil {
-		return errors.Wrap(err, "failed to get pocket")
+	pocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, input.PocketID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket")
 	}
 
-	if err := u.pocketRepo.DeletePocket(ctx, pocketID); err != nil {
-		return errors.Wrap(err, "failed to delete pocket")
+	if pocket.Type == entity.PocketTypeCashBox && input.Goal != nil {
+		return nil, errors.WithStack(ErrCashboxGoal)
+	}
+
+	pocket, err = u.pocketRepo.SetPocketGoal(ctx, input.PocketID, input.Goal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set pocket goal")
+	}
+
+	if err := u.setProgress(ctx, pocket.AccountID, []*entity.Pocket{pocket}); err != nil {
+		return nil, errors.Wrap(err, "failed to get goal progress")
+	}
+
+	return pocket, nil
+}
+
+// setProgress fills in the progress of the pockets with a goal, all of which
+// belong to accountID. The saving pace is the net inflow of the pocket over the
+// last goalHistoryMonths, or since it was created when it is younger than that
+// but at least a month old.
+func (u *Usecase) setProgress(ctx context.Context, accountID uuid.UUID, pockets []*entity.Pocket) error {
+	hasGoal := false
+	for _, pocket := range pockets {
+		hasGoal = hasGoal || pocket.Goal != nil
+	}
+	if !hasGoal {
+		return nil
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, -goalHistoryMonths, 0)
+	postings, err := u.transactionRepo.GetAccountPostings(ctx, accountID, from, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to get postings")
+	}
+
+	inflows := make(map[uuid.UUID]decimal.Decimal)
+	for _, posting := range postings {
+		if posting.PocketID != nil {
+			inflows[*posting.PocketID] = inflows[*posting.PocketID].Add(posting.Amount)
+		}
+	}
+
+	for _, pocket := range pockets {
+		if pocket.Goal == nil {
+			continue
+		}
+
+		start := from
+		if pocket.CreatedAt.After(start) {
+			start = pocket.CreatedAt
+		}
+		if monthAgo := now.AddDate(0, -1, 0); start.After(monthAgo) {
+			start = monthAgo
+		}
+		days := decimal.NewFromFloat(now.Sub(start).Hours() / 24)
+		monthlySaving := inflows[pocket.ID].Mul(decimal.NewFromInt(365)).Div(decimal.NewFromInt(12)).Div(days).Round(2)
+
+		progress := pocket.Goal.Progress(pocket.Balance, monthlySaving, now)
+		pocket.Progress = &progress
 	}
 
 	return nil
 }
 
-func (u *Usecase) Transfer(ctx context.Context, userID, fromPocketID, toPocketID uuid.UUID, amount decimal.Decimal) error {
+// DeletePocket deletes a pocket of the user after sweeping its balance into
+// toPocketID, or the cashbox of its account when nil, with a recorded transfer.
+// The cashbox itself cannot be deleted.
+func (u *Usecase) DeletePocket(ctx context.Context, userID, pocketID uuid.UUID, toPocketID *uuid.UUID) error {
 	// Check ownership
-	fromPocket, err := u.pocketRepo.GetPocketByID(ctx, userID, fromPocketID)
+	pocket, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get pocket")
 	}
 
-	toPocket, err := u.pocketRepo.GetPocketByID(ctx, userID, toPocketID)
+	if pocket.Type == entity.PocketTypeCashBox {
+		return errors.WithStack(ErrDeleteCashbox)
+	}
+
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Accounts are locked before pockets
+		if _, err := u.accountRepo.LockAccount(ctx, pocket.AccountID); err != nil {
+			return errors.Wrap(err, "failed to lock account")
+		}
+
+		pockets, err := u.pocketRepo.LockPocketsByAccountID(ctx, pocket.AccountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to lock pockets")
+		}
+
+		var from, to *entity.Pocket
+		for i := range pockets {
+			switch {
+			case pockets[i].ID == pocketID:
+				from = &pockets[i]
+			case toPocketID != nil && pockets[i].ID == *toPocketID:
+				to = &pockets[i]
+			case toPocketID == nil && pockets[i].Type == entity.PocketTypeCashBox:
+				to = &pockets[i]
+			}
+		}
+
+		// The balance only moves within the account
+		if from == nil || to == nil {
+			return errors.WithStack(ErrInvalidSweep)
+		}
+
+		if !from.Balance.IsZero() {
+			transaction := entity.TransactionInput{
+				AccountID:    from.AccountID,
+				FromPocketID: &from.ID,
+				ToPocketID:   &to.ID,
+				Type:         entity.TxTypeTransfer,
+				Amount:       from.Balance,
+				Note:         "Pocket deleted",
+			}
+
+			// Overdrawn pockets of liability accounts are settled the other way
+			if from.Balance.IsNegative() {
+				transaction.FromPocketID, transaction.ToPocketID = &to.ID, &from.ID
+				transaction.Amount = from.Balance.Neg()
+			}
+
+			if _, err := u.transactionRepo.CreateTransaction(ctx, transaction); err != nil {
+				return errors.Wrap(err, "failed to create transaction")
+			}
+
+			if err := u.pocketRepo.Transfer(ctx, *transaction.FromPocketID, *transaction.ToPocketID, transaction.Amount); err != nil {
+				return errors.Wrap(err, "failed to sweep pocket")
+			}
+		}
+
+		if err := u.pocketRepo.DeletePocket(ctx, pocketID); err != nil {
+			return errors.Wrap(err, "failed to delete pocket")
+		}
+
+		return nil
+	})
+}
+
+// Transfer moves money between two pockets of the user. When the pockets belong
+// to different accounts, both account balances move too and the configured
+// cross-account fee is charged to the fee pocket.
+func (u *Usecase) Transfer(ctx context.Context, input entity.TransferInput) error {
+	// Check ownership
+	fromPocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, input.FromPocketID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get pocket")
 	}
 
-	// Create transaction
-	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
-		AccountID:    fromPocket.AccountID,
-		FromPocketID: &fromPocket.ID,
-		ToPocketID:   &toPocket.ID,
-		Type:         entity.TxTypeDeposit,
-		Amount:       amount,
-	}); err != nil {
-		return errors.Wrap(err, "failed to create transaction")
+	toPocket, err := u.pocketRepo.GetPocketByID(ctx, input.UserID, input.ToPocketID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pocket")
 	}
 
-	if err := u.pocketRepo.Transfer(ctx, fromPocketID, toPocketID, amount); err != nil {
-		return errors.Wrap(err, "failed to transfer")
+	crossAccount := fromPocket.AccountID != toPocket.AccountID
+
+	// Converting between currencies is not supported
+	if fromPocket.Currency != toPocket.Currency {
+		return errors.Wrap(entity.ErrCurrencyMismatch, "failed to transfer")
 	}
+	fee := decimal.NewFromFloat(u.config.CrossAccountFee)
+	chargeFee := crossAccount && fee.IsPositive()
 
-	return nil
+	feePocket := fromPocket
+	if chargeFee && input.FeePocketID != nil {
+		feePocket, err = u.pocketRepo.GetPocketByID(ctx, input.UserID, *input.FeePocketID)
+		if err != nil {
+			return errors.Wrap(err, "failed to get fee pocket")
+		}
+
+		// The bank charges the fee to the sending account
+		if feePocket.AccountID != fromPocket.AccountID {
+			return errors.Wrap(ErrInvalidFeePocket, "failed to transfer")
+		}
+	}
+
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		transaction := entity.TransactionInput{
+			AccountID:    fromPocket.AccountID,
+			FromPocketID: &fromPocket.ID,
+			ToPocketID:   &toPocket.ID,
+			Type:         entity.TxTypeTransfer,
+			Amount:       input.Amount,
+			Note:         input.Note,
+		}
+
+		// Accounts are locked before pockets
+		if crossAccount {
+			transaction.ToAccountID = &toPocket.AccountID
+
+			if err := u.accountRepo.Transfer(ctx, fromPocket.AccountID, toPocket.AccountID, input.Amount); err != nil {
+				return errors.Wrap(err, "failed to transfer between accounts")
+			}
+		}
+
+		if chargeFee {
+			if err := u.accountRepo.Withdraw(ctx, feePocket.AccountID, fee); err != nil {
+				return errors.Wrap(err, "failed to charge fee to account")
+			}
+
+			// The fee pocket may be neither side of the transfer, so lock all the
+			// sending account's pockets in id order up front
+			if _, err := u.pocketRepo.LockPocketsByAccountID(ctx, fromPocket.AccountID); err != nil {
+				return errors.Wrap(err, "failed to lock pockets")
+			}
+		}
+
+		// Create transaction
+		transfer, err := u.transactionRepo.CreateTransaction(ctx, transaction)
+		if err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+
+		if err := u.pocketRepo.Transfer(ctx, fromPocket.ID, toPocket.ID, input.Amount); err != nil {
+			return errors.Wrap(err, "failed to transfer")
+		}
+
+		if !chargeFee {
+			return nil
+		}
+
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    feePocket.AccountID,
+			FromPocketID: &feePocket.ID,
+			Type:         entity.TxTypeFee,
+			Amount:       fee,
+			Note:         input.Note,
+			FeeOfID:      &transfer.ID,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create fee transaction")
+		}
+
+		if err := u.pocketRepo.Withdraw(ctx, feePocket.ID, fee); err != nil {
+			return errors.Wrap(err, "failed to charge fee to pocket")
+		}
+
+		return nil
+	})
 }
 
-func (u *Usecase) Withdraw(ctx context.Context, userID, pocketID uuid.UUID, amount decimal.Decimal) error {
+// Withdraw moves money out of the pocket, to payee when given, and lowers the
+// owning account's balance by the same amount.
+func (u *Usecase) Withdraw(ctx context.Context, userID, pocketID uuid.UUID, amount decimal.Decimal, note, payee string) error {
 	// Check ownership
 	fromPocket, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID)
 	if err != nil {
 		return errors.Wrap(err, "failed to get pocket")
 	}
 
-	// Create transaction
-	if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
-		AccountID:    fromPocket.AccountID,
-		FromPocketID: &fromPocket.ID,
-		ToPocketID:   nil,
-		Type:         entity.TxTypeDeposit,
-		Amount:       amount,
-	}); err != nil {
-		return errors.Wrap(err, "failed to create transaction")
-	}
+	return u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Create transaction
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    fromPocket.AccountID,
+			FromPocketID: &fromPocket.ID,
+			ToPocketID:   nil,
+			Type:         entity.TxTypeWithdraw,
+			Amount:       amount,
+			Note:         note,
+			Payee:        payee,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
 
-	if err := u.pocketRepo.Withdraw(ctx, pocketID, amount); err != nil {
-		return errors.Wrap(err, "failed to withdraw")
-	}
+		// Update account balance
+		if err := u.accountRepo.Withdraw(ctx, fromPocket.AccountID, amount); err != nil {
+			return errors.Wrap(err, "failed to withdraw from account")
+		}
 
-	return nil
+		if err := u.pocketRepo.Withdraw(ctx, pocketID, amount); err != nil {
+			return errors.Wrap(err, "failed to withdraw")
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,174 @@
+package pocket_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/account"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/pocket"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/transaction"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the database in TEST_POSTGRES_DSN and skips the test
+// when it is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+
+	return db
+}
+
+func TestTransferConservesMoney(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	const (
+		workers   = 16
+		transfers = 25
+	)
+	var (
+		initial = decimal.NewFromInt(500)
+		amount  = decimal.NewFromInt(40)
+		fee     = decimal.NewFromInt(1)
+	)
+
+	accountRepo := account.NewRepository(db)
+	pocketRepo := pocket.NewRepository(db)
+	transactionRepo := transaction.NewRepository(db)
+	usecase := pocket.NewUsecase(txmanager.NewTxManager(db), pocketRepo, accountRepo, transactionRepo, &pocket.Config{
+		CrossAccountFee: fee.InexactFloat64(),
+	})
+
+	// Two accounts with a cashbox and a normal pocket each
+	userID := uuid.New()
+	var accountIDs, pocketIDs []uuid.UUID
+	for range 2 {
+		a := model.Account{
+			ID:       uuid.New(),
+			UserID:   userID,
+			Type:     entity.AccountTypeSaving,
+			Name:     "Concurrency test",
+			Currency: "THB",
+			Balance:  initial.Mul(decimal.NewFromInt(2)),
+		}
+		if err := db.Create(&a).Error; err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+		accountIDs = append(accountIDs, a.ID)
+
+		for _, pocketType := range []entity.PocketType{entity.PocketTypeCashBox, entity.PocketTypeNormal} {
+			p := model.Pocket{
+				ID:        uuid.New(),
+				AccountID: a.ID,
+				Name:      string(pocketType),
+				Type:      pocketType,
+				Currency:  "THB",
+				Balance:   initial,
+			}
+			if err := db.Create(&p).Error; err != nil {
+				t.Fatalf("failed to create pocket: %v", err)
+			}
+			pocketIDs = append(pocketIDs, p.ID)
+		}
+	}
+
+	t.Cleanup(func() {
+		transactionIDs := db.Model(&model.Transaction{}).Select("id").Where("account_id IN ?", accountIDs)
+		db.Where("transaction_id IN (?)", transactionIDs).Delete(&model.Posting{})
+		db.Where("account_id IN ?", accountIDs).Delete(&model.Transaction{})
+		db.Unscoped().Where("account_id IN ?", accountIDs).Delete(&model.Pocket{})
+		db.Where("id IN ?", accountIDs).Delete(&model.Account{})
+	})
+
+	var (
+		wg       sync.WaitGroup
+		charged  atomic.Int64
+		rejected atomic.Int64
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range transfers {
+				from, to := rand.IntN(len(pocketIDs)), rand.IntN(len(pocketIDs)-1)
+				if to >= from {
+					to++
+				}
+
+				err := usecase.Transfer(ctx, entity.TransferInput{
+					UserID:       userID,
+					FromPocketID: pocketIDs[from],
+					ToPocketID:   pocketIDs[to],
+					Amount:       amount,
+				})
+				switch {
+				case errors.Is(err, entity.ErrInsufficientBalance):
+					rejected.Add(1)
+				case err != nil:
+					t.Errorf("failed to transfer: %v", err)
+				case from/2 != to/2:
+					charged.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every unit of money is in a pocket or was charged as a fee
+	total := fee.Mul(decimal.NewFromInt(charged.Load()))
+	for i, accountID := range accountIDs {
+		a, err := accountRepo.LockAccount(ctx, accountID)
+		if err != nil {
+			t.Fatalf("failed to get account: %v", err)
+		}
+
+		pocketsBalance := decimal.Zero
+		for _, pocketID := range pocketIDs[i*2 : i*2+2] {
+			p, err := pocketRepo.GetPocketByID(ctx, userID, pocketID)
+			if err != nil {
+				t.Fatalf("failed to get pocket: %v", err)
+			}
+
+			if p.Balance.IsNegative() {
+				t.Errorf("pocket %s is overdrawn: %s", p.ID, p.Balance)
+			}
+			pocketsBalance = pocketsBalance.Add(p.Balance)
+		}
+
+		if !a.Balance.Equal(pocketsBalance) {
+			t.Errorf("account %s balance = %s, pockets hold %s", a.ID, a.Balance, pocketsBalance)
+		}
+		total = total.Add(pocketsBalance)
+	}
+
+	if want := initial.Mul(decimal.NewFromInt(4)); !total.Equal(want) {
+		t.Errorf("total money = %s, want %s", total, want)
+	}
+
+	t.Logf("%d transfers, %d rejected for insufficient balance", workers*transfers, rejected.Load())
+}
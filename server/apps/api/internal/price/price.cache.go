@@ -0,0 +1,62 @@
+package price
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+)
+
+// cachedProvider serves prices from the price table and asks source only when
+// the table has no price for the requested day. Fetched prices are stored, so
+// the source is hit at most once per symbol and day.
+type cachedProvider struct {
+	priceRepo interfaces.PriceRepository
+	source    interfaces.PriceProvider // nil when only uploaded prices are used
+}
+
+func NewCachedProvider(priceRepo interfaces.PriceRepository, source interfaces.PriceProvider) interfaces.PriceProvider {
+	return &cachedProvider{
+		priceRepo: priceRepo,
+		source:    source,
+	}
+}
+
+func (p *cachedProvider) GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error) {
+	symbol = NormalizeSymbol(symbol)
+
+	cached, err := p.priceRepo.GetPrice(ctx, symbol, date)
+	if err != nil && !errors.Is(err, entity.ErrPriceNotFound) {
+		return nil, errors.Wrap(err, "failed to get cached price")
+	}
+
+	if p.source == nil || (cached != nil && cached.Date.Format(dateLayout) == date.Format(dateLayout)) {
+		if cached == nil {
+			return nil, errors.WithStack(err)
+		}
+		return cached, nil
+	}
+
+	fetched, err := p.source.GetPrice(ctx, symbol, date)
+	if err != nil {
+		if cached != nil {
+			logger.WarnContext(ctx, "failed to fetch price, using cached price", slog.String("symbol", symbol), slog.Any("error", err))
+			return cached, nil
+		}
+		return nil, errors.Wrap(err, "failed to fetch price")
+	}
+
+	if cached != nil && !fetched.Date.After(cached.Date) {
+		return cached, nil
+	}
+
+	if err := p.priceRepo.SavePrices(ctx, []entity.Price{*fetched}); err != nil {
+		logger.WarnContext(ctx, "failed to cache price", slog.String("symbol", symbol), slog.Any("error", err))
+	}
+
+	return fetched, nil
+}
@@ -0,0 +1,122 @@
+package price
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase *usecase
+}
+
+func NewController(priceUsecase *usecase) *controller {
+	return &controller{
+		usecase: priceUsecase,
+	}
+}
+
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:symbol", h.GetPrice)
+}
+
+// MountAdmin registers the routes on the admin group.
+func (h *controller) MountAdmin(r fiber.Router) {
+	r.Post("/prices", h.UploadPrices)
+	r.Post("/prices/refresh", h.Refresh)
+}
+
+type priceResponse struct {
+	Symbol string             `json:"symbol"`
+	Date   string             `json:"date"`
+	Price  decimal.Decimal    `json:"price"`
+	Source entity.PriceSource `json:"source"`
+}
+
+func (h *controller) GetPrice(ctx *fiber.Ctx) error {
+	date := time.Now()
+	if q := ctx.Query("date"); q != "" {
+		d, err := time.Parse(dateLayout, q)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+				Error: "Invalid date",
+			})
+		}
+		date = d
+	}
+
+	price, err := h.usecase.GetPrice(ctx.UserContext(), ctx.Params("symbol"), date)
+	if errors.Is(err, entity.ErrPriceNotFound) {
+		return ctx.Status(fiber.StatusNotFound).JSON(&dto.HttpResponse{
+			Error: "Price not found",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to get price")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: priceResponse{
+			Symbol: price.Symbol,
+			Date:   price.Date.Format(dateLayout),
+			Price:  price.Value,
+			Source: price.Source,
+		},
+	})
+}
+
+type uploadPricesResponse struct {
+	Saved int `json:"saved"`
+}
+
+// UploadPrices accepts the same CSV or JSON formats as the price file,
+// chosen by the Content-Type header.
+func (h *controller) UploadPrices(ctx *fiber.Ctx) error {
+	var (
+		prices []entity.Price
+		err    error
+	)
+	if strings.HasPrefix(ctx.Get(fiber.HeaderContentType), "text/csv") {
+		prices, err = ParseCSV(bytes.NewReader(ctx.Body()))
+	} else {
+		prices, err = ParseJSON(bytes.NewReader(ctx.Body()))
+	}
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	if err := h.usecase.UploadPrices(ctx.UserContext(), prices); err != nil {
+		return errors.Wrap(err, "failed to upload prices")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: uploadPricesResponse{
+			Saved: len(prices),
+		},
+	})
+}
+
+type refreshPricesResponse struct {
+	Refreshed int `json:"refreshed"`
+}
+
+func (h *controller) Refresh(ctx *fiber.Ctx) error {
+	refreshed, err := h.usecase.Refresh(ctx.UserContext())
+	if err != nil {
+		return errors.Wrap(err, "failed to refresh prices")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: refreshPricesResponse{
+			Refreshed: refreshed,
+		},
+	})
+}
@@ -0,0 +1,188 @@
+package price
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/shopspring/decimal"
+)
+
+const dateLayout = "2006-01-02"
+
+// fileProvider reads prices from a file and reloads it when it changes.
+//
+// CSV rows are "symbol,date,price" with an optional header row. JSON is an
+// array of {"symbol": "...", "date": "2006-01-02", "price": 10.1234}.
+type fileProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	prices  map[string][]entity.Price // Sorted by date ascending
+}
+
+func NewFileProvider(path string) interfaces.PriceProvider {
+	return &fileProvider{
+		path: path,
+	}
+}
+
+func (p *fileProvider) GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.reload(); err != nil {
+		return nil, errors.Wrap(err, "failed to load prices")
+	}
+
+	prices := p.prices[symbol]
+
+	// First price published after date
+	i := sort.Search(len(prices), func(i int) bool {
+		return prices[i].Date.After(date)
+	})
+	if i == 0 {
+		return nil, errors.Wrapf(entity.ErrPriceNotFound, "no price for %s on %s", symbol, date.Format(dateLayout))
+	}
+
+	price := prices[i-1]
+	return &price, nil
+}
+
+func (p *fileProvider) reload() error {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat price file")
+	}
+
+	if p.prices != nil && info.ModTime().Equal(p.modTime) {
+		return nil
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open price file")
+	}
+	defer f.Close()
+
+	var prices []entity.Price
+	switch strings.ToLower(filepath.Ext(p.path)) {
+	case ".csv":
+		prices, err = ParseCSV(f)
+	case ".json":
+		prices, err = ParseJSON(f)
+	default:
+		err = errors.Newf("unsupported price file type %q", filepath.Ext(p.path))
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bySymbol := make(map[string][]entity.Price)
+	for _, price := range prices {
+		price.Source = entity.PriceSourceFile
+		bySymbol[price.Symbol] = append(bySymbol[price.Symbol], price)
+	}
+	for _, prices := range bySymbol {
+		sort.Slice(prices, func(i, j int) bool {
+			return prices[i].Date.Before(prices[j].Date)
+		})
+	}
+
+	p.prices = bySymbol
+	p.modTime = info.ModTime()
+
+	return nil
+}
+
+// NormalizeSymbol is the form symbols are stored and looked up in.
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+func parsePrice(symbol, date, value string) (entity.Price, error) {
+	d, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return entity.Price{}, errors.Wrapf(err, "invalid date %q", date)
+	}
+
+	v, err := decimal.NewFromString(strings.TrimSpace(value))
+	if err != nil {
+		return entity.Price{}, errors.Wrapf(err, "invalid price %q", value)
+	}
+
+	if !v.IsPositive() {
+		return entity.Price{}, errors.Newf("price %q must be positive", value)
+	}
+
+	return entity.Price{
+		Symbol: NormalizeSymbol(symbol),
+		Date:   d,
+		Value:  v,
+	}, nil
+}
+
+// ParseCSV reads "symbol,date,price" rows, skipping a header row.
+func ParseCSV(r io.Reader) ([]entity.Price, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read csv")
+	}
+
+	var prices []entity.Price
+	for i, record := range records {
+		if len(record) < 3 {
+			return nil, errors.Newf("line %d: expected symbol,date,price", i+1)
+		}
+
+		price, err := parsePrice(record[0], record[1], record[2])
+		if err != nil {
+			// Skip the header row
+			if i == 0 {
+				continue
+			}
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
+
+type priceRecord struct {
+	Symbol string          `json:"symbol"`
+	Date   string          `json:"date"`
+	Price  decimal.Decimal `json:"price"`
+}
+
+// ParseJSON reads an array of {"symbol", "date", "price"} records.
+func ParseJSON(r io.Reader) ([]entity.Price, error) {
+	var records []priceRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, errors.Wrap(err, "failed to decode json")
+	}
+
+	prices := make([]entity.Price, 0, len(records))
+	for i, record := range records {
+		price, err := parsePrice(record.Symbol, record.Date, record.Price.String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "record %d", i)
+		}
+
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,37 @@
+package price
+
+import (
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "positive", value: " 12.3456 "},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-1.5", wantErr: true},
+		{name: "not a number", value: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := parsePrice(" kfsdiv ", "2024-05-31", tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePrice(%q) = %s, want error", tt.value, price.Value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if price.Symbol != "KFSDIV" || price.Date.Format(dateLayout) != "2024-05-31" || price.Value.String() != "12.3456" {
+				t.Errorf("parsePrice = %+v", price)
+			}
+		})
+	}
+}
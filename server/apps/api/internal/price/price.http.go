@@ -0,0 +1,75 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+)
+
+// httpProvider asks a JSON API for prices. The URL template gets {symbol} and
+// {date} substituted, and the response is a single
+// {"symbol": "...", "date": "2006-01-02", "price": 10.1234} record. A 404
+// means the API has no price on or before the date.
+type httpProvider struct {
+	urlTemplate string
+	client      *http.Client
+}
+
+func NewHTTPProvider(urlTemplate string, timeout time.Duration) interfaces.PriceProvider {
+	return &httpProvider{
+		urlTemplate: urlTemplate,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+func (p *httpProvider) GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error) {
+	u := strings.NewReplacer(
+		"{symbol}", url.PathEscape(symbol),
+		"{date}", date.Format(dateLayout),
+	).Replace(p.urlTemplate)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request price")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.Wrapf(entity.ErrPriceNotFound, "no price for %s on %s", symbol, date.Format(dateLayout))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Newf("price api responded with status %d", resp.StatusCode)
+	}
+
+	var record priceRecord
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return nil, errors.Wrap(err, "failed to decode price")
+	}
+
+	if record.Symbol == "" {
+		record.Symbol = symbol
+	}
+
+	price, err := parsePrice(record.Symbol, record.Date, record.Price.String())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	price.Source = entity.PriceSourceHTTP
+
+	return &price, nil
+}
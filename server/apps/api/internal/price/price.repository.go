@@ -0,0 +1,104 @@
+package price
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.PriceRepository {
+	db.AutoMigrate(&model.Price{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error) {
+	var p model.Price
+	err := r.getDB(ctx).Where("symbol = ? AND date <= ?", symbol, date.Format(dateLayout)).Order("date desc").First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrapf(entity.ErrPriceNotFound, "no price for %s on %s", symbol, date.Format(dateLayout))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get price")
+	}
+
+	return &entity.Price{
+		Symbol: p.Symbol,
+		Date:   p.Date,
+		Value:  p.Value,
+		Source: p.Source,
+	}, nil
+}
+
+func (r *repository) SavePrices(ctx context.Context, prices []entity.Price) error {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	// A single upsert must not touch the same row twice, so the last price
+	// for a symbol and date wins
+	latest := make(map[string]int, len(prices))
+	for i, p := range prices {
+		latest[p.Symbol+"|"+p.Date.Format(dateLayout)] = i
+	}
+
+	now := time.Now()
+	rows := make([]model.Price, 0, len(latest))
+	for i, p := range prices {
+		if latest[p.Symbol+"|"+p.Date.Format(dateLayout)] != i {
+			continue
+		}
+
+		rows = append(rows, model.Price{
+			ID:        uuid.New(),
+			Symbol:    p.Symbol,
+			Date:      p.Date,
+			Value:     p.Value,
+			Source:    p.Source,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	if err := r.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "source", "updated_at"}),
+	}).Create(&rows).Error; err != nil {
+		return errors.Wrap(err, "failed to save prices")
+	}
+
+	return nil
+}
+
+func (r *repository) GetTrackedSymbols(ctx context.Context) ([]string, error) {
+	db := r.getDB(ctx)
+
+	var symbols []string
+	if err := db.Raw("? UNION ? UNION ?",
+		db.Model(&model.Trade{}).Distinct("symbol"),
+		db.Model(&model.FundOrder{}).Distinct("fund_code"),
+		db.Model(&model.Price{}).Distinct("symbol"),
+	).Scan(&symbols).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get tracked symbols")
+	}
+
+	return symbols, nil
+}
@@ -0,0 +1,105 @@
+package price
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+)
+
+var (
+	ErrUnknownPriceSource = errors.New("UNKNOWN_PRICE_SOURCE")
+)
+
+type Config struct {
+	Source          string `mapstructure:"source"`           // "file", "http", or empty to use uploaded prices only
+	File            string `mapstructure:"file"`             // CSV or JSON file, chosen by extension
+	URL             string `mapstructure:"url"`              // JSON API, {symbol} and {date} are substituted
+	Timeout         int64  `mapstructure:"timeout"`          // Seconds before an API request is abandoned
+	RefreshInterval int64  `mapstructure:"refresh_interval"` // Seconds between background refreshes, 0 disables the refresher
+}
+
+// NewSource returns the backend selected by config, or nil when none is.
+func NewSource(config *Config) (interfaces.PriceProvider, error) {
+	switch config.Source {
+	case "":
+		return nil, nil
+	case "file":
+		return NewFileProvider(config.File), nil
+	case "http":
+		return NewHTTPProvider(config.URL, time.Duration(config.Timeout)*time.Second), nil
+	}
+
+	return nil, errors.Wrapf(ErrUnknownPriceSource, "price source %q", config.Source)
+}
+
+type usecase struct {
+	priceRepo     interfaces.PriceRepository
+	source        interfaces.PriceProvider
+	priceProvider interfaces.PriceProvider
+}
+
+func NewUsecase(priceRepo interfaces.PriceRepository, source interfaces.PriceProvider, priceProvider interfaces.PriceProvider) *usecase {
+	return &usecase{
+		priceRepo:     priceRepo,
+		source:        source,
+		priceProvider: priceProvider,
+	}
+}
+
+func (u *usecase) GetPrice(ctx context.Context, symbol string, date time.Time) (*entity.Price, error) {
+	price, err := u.priceProvider.GetPrice(ctx, symbol, date)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get price")
+	}
+
+	return price, nil
+}
+
+func (u *usecase) UploadPrices(ctx context.Context, prices []entity.Price) error {
+	for i := range prices {
+		prices[i].Symbol = NormalizeSymbol(prices[i].Symbol)
+		prices[i].Source = entity.PriceSourceUpload
+	}
+
+	if err := u.priceRepo.SavePrices(ctx, prices); err != nil {
+		return errors.Wrap(err, "failed to save prices")
+	}
+
+	return nil
+}
+
+// Refresh fetches today's price of every tracked symbol from the source. A
+// symbol that fails is logged and skipped so one bad symbol does not stall the rest.
+func (u *usecase) Refresh(ctx context.Context) (int, error) {
+	if u.source == nil {
+		return 0, nil
+	}
+
+	symbols, err := u.priceRepo.GetTrackedSymbols(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get tracked symbols")
+	}
+
+	now := time.Now()
+	prices := make([]entity.Price, 0, len(symbols))
+	for _, symbol := range symbols {
+		price, err := u.source.GetPrice(ctx, symbol, now)
+		if err != nil {
+			logger.WarnContext(ctx, "failed to refresh price", slog.String("symbol", symbol), slog.Any("error", err))
+			continue
+		}
+
+		prices = append(prices, *price)
+	}
+
+	if err := u.priceRepo.SavePrices(ctx, prices); err != nil {
+		return 0, errors.Wrap(err, "failed to save prices")
+	}
+
+	return len(prices), nil
+}
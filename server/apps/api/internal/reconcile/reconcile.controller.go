@@ -0,0 +1,113 @@
+package reconcile
+
+import (
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(reconcileUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        reconcileUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// Mount registers the routes on the account group.
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/:id/reconcile", h.GetReconciliation)
+	r.Post("/:id/reconcile", h.Repair)
+}
+
+type pocketReconciliationResponse struct {
+	PocketID      uuid.UUID       `json:"pocketId"`
+	Name          string          `json:"name"`
+	Balance       decimal.Decimal `json:"balance"`
+	LedgerBalance decimal.Decimal `json:"ledgerBalance"`
+	Difference    decimal.Decimal `json:"difference"`
+	Balanced      bool            `json:"balanced"`
+}
+
+type reconciliationResponse struct {
+	AccountID      uuid.UUID                      `json:"accountId"`
+	Balance        decimal.Decimal                `json:"balance"`
+	PocketsBalance decimal.Decimal                `json:"pocketsBalance"`
+	LedgerBalance  decimal.Decimal                `json:"ledgerBalance"`
+	Difference     decimal.Decimal                `json:"difference"`
+	Balanced       bool                           `json:"balanced"`
+	Repaired       bool                           `json:"repaired"`
+	Pockets        []pocketReconciliationResponse `json:"pockets"`
+
+	OrphanedPostings int64           `json:"orphanedPostings"`
+	OrphanedBalance  decimal.Decimal `json:"orphanedBalance"`
+}
+
+func newReconciliationResponse(r *entity.AccountReconciliation) reconciliationResponse {
+	pockets := make([]pocketReconciliationResponse, 0, len(r.Pockets))
+	for _, p := range r.Pockets {
+		pockets = append(pockets, pocketReconciliationResponse{
+			PocketID:      p.PocketID,
+			Name:          p.Name,
+			Balance:       p.Balance,
+			LedgerBalance: p.LedgerBalance,
+			Difference:    p.Difference,
+			Balanced:      p.IsBalanced(),
+		})
+	}
+
+	return reconciliationResponse{
+		AccountID:      r.AccountID,
+		Balance:        r.Balance,
+		PocketsBalance: r.PocketsBalance,
+		LedgerBalance:  r.LedgerBalance,
+		Difference:     r.Difference,
+		Balanced:       r.IsBalanced(),
+		Repaired:       r.Repaired,
+		Pockets:        pockets,
+
+		OrphanedPostings: r.OrphanedPostings,
+		OrphanedBalance:  r.OrphanedBalance,
+	}
+}
+
+func (h *controller) GetReconciliation(ctx *fiber.Ctx) error {
+	return h.reconcile(ctx, false)
+}
+
+func (h *controller) Repair(ctx *fiber.Ctx) error {
+	return h.reconcile(ctx, true)
+}
+
+func (h *controller) reconcile(ctx *fiber.Ctx, repair bool) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	accountID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid account ID",
+		})
+	}
+
+	result, err := h.usecase.ReconcileAccount(ctx.UserContext(), userID, accountID, repair)
+	if err != nil {
+		return errors.Wrap(err, "failed to reconcile account")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: newReconciliationResponse(result),
+	})
+}
@@ -0,0 +1,191 @@
+package reconcile
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Config struct {
+	Interval int64 `mapstructure:"interval"` // Seconds between background runs, 0 disables the runner
+	Repair   bool  `mapstructure:"repair"`   // Whether the background runner repairs drift
+}
+
+type usecase struct {
+	txManager       interfaces.TxManager
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	transactionRepo interfaces.TransactionRepository
+}
+
+func NewUsecase(txManager interfaces.TxManager, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, transactionRepo interfaces.TransactionRepository) *usecase {
+	return &usecase{
+		txManager:       txManager,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+func (u *usecase) ReconcileAccount(ctx context.Context, userID, accountID uuid.UUID, repair bool) (*entity.AccountReconciliation, error) {
+	// Check ownership
+	if _, err := u.accountRepo.GetUserAccount(ctx, userID, accountID); err != nil {
+		return nil, errors.Wrap(err, "failed to get account")
+	}
+
+	result, err := u.reconcile(ctx, accountID, repair)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reconcile account")
+	}
+
+	return result, nil
+}
+
+// ReconcileAll checks every account and logs the ones that drifted. A failing
+// account is logged and checked again on the next run.
+func (u *usecase) ReconcileAll(ctx context.Context, repair bool) error {
+	accounts, err := u.accountRepo.GetAccounts(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get accounts")
+	}
+
+	for _, account := range accounts {
+		result, err := u.reconcile(ctx, account.ID, repair)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to reconcile account",
+				slog.String("account_id", account.ID.String()),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		if !result.IsBalanced() {
+			logger.WarnContext(ctx, "account balance drift detected",
+				slog.String("account_id", account.ID.String()),
+				slog.String("balance", result.Balance.String()),
+				slog.String("pockets_balance", result.PocketsBalance.String()),
+				slog.String("ledger_balance", result.LedgerBalance.String()),
+				slog.Int64("orphaned_postings", result.OrphanedPostings),
+				slog.Bool("repaired", result.Repaired),
+			)
+		}
+	}
+
+	return nil
+}
+
+// reconcile compares the cached account and pocket balances with the ledger. When
+// repair is set, pocket drift is booked as an adjustment transaction so the ledger
+// explains the cached balance, and the account balance is reset to its pockets' sum.
+// Repaired is set only when the account checks out afterwards, which orphaned
+// postings prevent.
+func (u *usecase) reconcile(ctx context.Context, accountID uuid.UUID, repair bool) (*entity.AccountReconciliation, error) {
+	var result *entity.AccountReconciliation
+	err := u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = u.check(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check account")
+		}
+
+		if !repair || result.IsBalanced() {
+			return nil
+		}
+
+		for _, p := range result.Pockets {
+			if p.IsBalanced() {
+				continue
+			}
+
+			input := entity.TransactionInput{
+				AccountID: accountID,
+				Type:      entity.TxTypeAdjustment,
+				Amount:    p.Difference.Abs(),
+			}
+			if p.Difference.IsPositive() {
+				input.ToPocketID = &p.PocketID
+			} else {
+				input.FromPocketID = &p.PocketID
+			}
+
+			if _, err := u.transactionRepo.CreateTransaction(ctx, input); err != nil {
+				return errors.Wrap(err, "failed to create adjustment transaction")
+			}
+		}
+
+		if !result.Difference.IsZero() {
+			if err := u.accountRepo.SetBalance(ctx, accountID, result.PocketsBalance); err != nil {
+				return errors.Wrap(err, "failed to set account balance")
+			}
+		}
+
+		repaired, err := u.check(ctx, accountID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check repaired account")
+		}
+		result.Repaired = repaired.IsBalanced()
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return result, nil
+}
+
+// check locks the account and its pockets, so money movements can't interleave,
+// and compares their cached balances with the ledger.
+func (u *usecase) check(ctx context.Context, accountID uuid.UUID) (*entity.AccountReconciliation, error) {
+	account, err := u.accountRepo.LockAccount(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock account")
+	}
+
+	pockets, err := u.pocketRepo.LockPocketsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock pockets")
+	}
+
+	result := &entity.AccountReconciliation{
+		AccountID:      account.ID,
+		Balance:        account.Balance,
+		PocketsBalance: decimal.Zero,
+		Pockets:        make([]entity.PocketReconciliation, 0, len(pockets)),
+	}
+
+	for _, pocket := range pockets {
+		ledgerBalance, err := u.transactionRepo.GetPocketLedgerBalance(ctx, pocket.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pocket ledger balance")
+		}
+
+		result.PocketsBalance = result.PocketsBalance.Add(pocket.Balance)
+		result.Pockets = append(result.Pockets, entity.PocketReconciliation{
+			PocketID:      pocket.ID,
+			Name:          pocket.Name,
+			Balance:       pocket.Balance,
+			LedgerBalance: ledgerBalance,
+			Difference:    pocket.Balance.Sub(ledgerBalance),
+		})
+	}
+
+	result.LedgerBalance, err = u.transactionRepo.GetAccountLedgerBalance(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get account ledger balance")
+	}
+	result.Difference = result.Balance.Sub(result.PocketsBalance)
+
+	result.OrphanedPostings, result.OrphanedBalance, err = u.transactionRepo.GetOrphanedPostings(ctx, accountID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get orphaned postings")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,220 @@
+package report
+
+import (
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
+	"github.com/cockroachdb/errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/moonrhythm/validator"
+	"github.com/shopspring/decimal"
+)
+
+type controller struct {
+	usecase        *usecase
+	authMiddleware authentication.AuthMiddleware
+}
+
+func NewController(reportUsecase *usecase, authMiddleware authentication.AuthMiddleware) *controller {
+	return &controller{
+		usecase:        reportUsecase,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (h *controller) Mount(r fiber.Router) {
+	r.Get("/allocation", h.GetAllocation)
+	r.Get("/allocation/targets", h.GetAllocationTargets)
+	r.Put("/allocation/targets", h.SetAllocationTargets)
+}
+
+// mapError translates the usecase errors that are caused by the request.
+func mapError(ctx *fiber.Ctx, err error) (bool, error) {
+	switch {
+	case errors.Is(err, entity.ErrInvalidAllocationDimension):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Dimension must be TYPE, BANK, CURRENCY or POCKET",
+		})
+	case errors.Is(err, entity.ErrInvalidAllocationTargets):
+		return true, ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Targets must have unique valid keys and non-negative weights that sum to 100",
+		})
+	}
+
+	return false, nil
+}
+
+type allocationGroupResponse struct {
+	Key          string           `json:"key"`
+	Label        string           `json:"label"`
+	Amount       decimal.Decimal  `json:"amount"`
+	Percent      decimal.Decimal  `json:"percent"`
+	TargetWeight *decimal.Decimal `json:"targetWeight"`
+	TargetAmount *decimal.Decimal `json:"targetAmount"`
+	Delta        *decimal.Decimal `json:"delta"`
+}
+
+type allocationResponse struct {
+	Dimension entity.AllocationDimension `json:"dimension"`
+	Groups    []allocationGroupResponse  `json:"groups"`
+}
+
+type allocationReportResponse struct {
+	BaseCurrency string               `json:"baseCurrency"`
+	Total        decimal.Decimal      `json:"total"`
+	Allocations  []allocationResponse `json:"allocations"`
+	Unconverted  []string             `json:"unconverted"`
+}
+
+// GetAllocation returns the asset allocation of the user with the
+// rebalancing deltas against their targets.
+func (h *controller) GetAllocation(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	report, err := h.usecase.GetAllocation(ctx.UserContext(), userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get allocation")
+	}
+
+	response := allocationReportResponse{
+		BaseCurrency: report.BaseCurrency,
+		Total:        report.Total,
+		Allocations:  make([]allocationResponse, 0, len(report.Allocations)),
+		Unconverted:  make([]string, 0, len(report.Unconverted)),
+	}
+	response.Unconverted = append(response.Unconverted, report.Unconverted...)
+	for _, a := range report.Allocations {
+		allocation := allocationResponse{
+			Dimension: a.Dimension,
+			Groups:    make([]allocationGroupResponse, 0, len(a.Groups)),
+		}
+		for _, g := range a.Groups {
+			allocation.Groups = append(allocation.Groups, allocationGroupResponse{
+				Key:          g.Key,
+				Label:        g.Label,
+				Amount:       g.Amount,
+				Percent:      g.Percent,
+				TargetWeight: g.TargetWeight,
+				TargetAmount: g.TargetAmount,
+				Delta:        g.Delta,
+			})
+		}
+		response.Allocations = append(response.Allocations, allocation)
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type allocationTargetResponse struct {
+	Dimension entity.AllocationDimension `json:"dimension"`
+	Key       string                     `json:"key"`
+	Weight    decimal.Decimal            `json:"weight"`
+}
+
+func (h *controller) GetAllocationTargets(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	targets, err := h.usecase.GetAllocationTargets(ctx.UserContext(), userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get allocation targets")
+	}
+
+	response := make([]allocationTargetResponse, 0, len(targets))
+	for _, t := range targets {
+		response = append(response, allocationTargetResponse{
+			Dimension: t.Dimension,
+			Key:       t.Key,
+			Weight:    t.Weight,
+		})
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: response,
+	})
+}
+
+type allocationTargetRequest struct {
+	Key    string          `json:"key"`
+	Weight decimal.Decimal `json:"weight"`
+}
+
+type setAllocationTargetsRequest struct {
+	Dimension string                    `json:"dimension"`
+	Targets   []allocationTargetRequest `json:"targets"` // Empty to clear the targets of the dimension
+}
+
+func (a *setAllocationTargetsRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.BodyParser(a); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := a.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (a *setAllocationTargetsRequest) Validate() error {
+	v := validator.New()
+	v.Must(a.Dimension != "", "dimension is required")
+	for _, t := range a.Targets {
+		v.Must(t.Key != "", "key is required")
+		v.Must(!t.Weight.IsNegative(), "weight must not be negative")
+	}
+
+	return errors.WithStack(v.Error())
+}
+
+// SetAllocationTargets replaces the targets of one dimension.
+func (h *controller) SetAllocationTargets(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req setAllocationTargetsRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	input := entity.AllocationTargetsInput{
+		UserID:    userID,
+		Dimension: entity.AllocationDimension(req.Dimension),
+		Targets:   make([]entity.AllocationTarget, 0, len(req.Targets)),
+	}
+	for _, t := range req.Targets {
+		input.Targets = append(input.Targets, entity.AllocationTarget{
+			Key:    t.Key,
+			Weight: t.Weight,
+		})
+	}
+
+	err = h.usecase.SetAllocationTargets(ctx.UserContext(), input)
+	if handled, err := mapError(ctx, err); handled {
+		return err
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to set allocation targets")
+	}
+
+	return ctx.JSON(dto.HttpResponse{
+		Result: "Allocation targets updated",
+	})
+}
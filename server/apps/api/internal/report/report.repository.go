@@ -0,0 +1,81 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) interfaces.ReportRepository {
+	db.AutoMigrate(&model.AllocationTarget{})
+
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func (r *repository) GetAllocationTargets(ctx context.Context, userID uuid.UUID) ([]entity.AllocationTarget, error) {
+	var targets []*model.AllocationTarget
+	if err := r.getDB(ctx).Where("user_id = ?", userID).Order("dimension asc, weight desc, key asc").Find(&targets).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation targets")
+	}
+
+	var result []entity.AllocationTarget
+	for _, t := range targets {
+		result = append(result, entity.AllocationTarget{
+			Dimension: t.Dimension,
+			Key:       t.Key,
+			Weight:    t.Weight,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) SetAllocationTargets(ctx context.Context, userID uuid.UUID, dimension entity.AllocationDimension, targets []entity.AllocationTarget) error {
+	now := time.Now()
+
+	rows := make([]model.AllocationTarget, 0, len(targets))
+	for _, t := range targets {
+		rows = append(rows, model.AllocationTarget{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Dimension: dimension,
+			Key:       t.Key,
+			Weight:    t.Weight,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND dimension = ?", userID, dimension).Delete(&model.AllocationTarget{}).Error; err != nil {
+			return errors.Wrap(err, "failed to delete allocation targets")
+		}
+
+		if len(rows) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&rows).Error; err != nil {
+			return errors.Wrap(err, "failed to create allocation targets")
+		}
+
+		return nil
+	})
+}
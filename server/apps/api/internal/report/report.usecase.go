@@ -0,0 +1,262 @@
+package report
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/currency"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+type usecase struct {
+	reportRepo      interfaces.ReportRepository
+	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
+	currencyUsecase *currency.Usecase
+}
+
+func NewUsecase(reportRepo interfaces.ReportRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository, currencyUsecase *currency.Usecase) *usecase {
+	return &usecase{
+		reportRepo:      reportRepo,
+		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
+		currencyUsecase: currencyUsecase,
+	}
+}
+
+func (u *usecase) GetAllocationTargets(ctx context.Context, userID uuid.UUID) ([]entity.AllocationTarget, error) {
+	targets, err := u.reportRepo.GetAllocationTargets(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation targets")
+	}
+
+	return targets, nil
+}
+
+// SetAllocationTargets replaces the targets of one dimension. Keys are
+// normalized, must be unique and pockets must belong to the user.
+func (u *usecase) SetAllocationTargets(ctx context.Context, input entity.AllocationTargetsInput) error {
+	if !input.Dimension.IsValid() {
+		return errors.WithStack(entity.ErrInvalidAllocationDimension)
+	}
+
+	sum := decimal.Zero
+	seen := make(map[string]bool, len(input.Targets))
+	targets := make([]entity.AllocationTarget, 0, len(input.Targets))
+	for _, t := range input.Targets {
+		key, err := u.normalizeKey(ctx, input.UserID, input.Dimension, t.Key)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if seen[key] || t.Weight.IsNegative() {
+			return errors.WithStack(entity.ErrInvalidAllocationTargets)
+		}
+		seen[key] = true
+		sum = sum.Add(t.Weight)
+
+		targets = append(targets, entity.AllocationTarget{
+			Dimension: input.Dimension,
+			Key:       key,
+			Weight:    t.Weight,
+		})
+	}
+
+	if len(targets) > 0 && !sum.Equal(hundred) {
+		return errors.WithStack(entity.ErrInvalidAllocationTargets)
+	}
+
+	if err := u.reportRepo.SetAllocationTargets(ctx, input.UserID, input.Dimension, targets); err != nil {
+		return errors.Wrap(err, "failed to set allocation targets")
+	}
+
+	return nil
+}
+
+func (u *usecase) normalizeKey(ctx context.Context, userID uuid.UUID, dimension entity.AllocationDimension, key string) (string, error) {
+	key = strings.TrimSpace(key)
+
+	switch dimension {
+	case entity.AllocationDimensionType:
+		accountType := entity.AccountType(strings.ToUpper(key))
+		if !accountType.IsValid() || accountType.IsLiability() {
+			return "", errors.WithStack(entity.ErrInvalidAllocationTargets)
+		}
+		return accountType.String(), nil
+	case entity.AllocationDimensionCurrency:
+		currency, err := entity.NormalizeCurrency(key)
+		if err != nil {
+			return "", errors.WithStack(entity.ErrInvalidAllocationTargets)
+		}
+		return currency, nil
+	case entity.AllocationDimensionPocket:
+		pocketID, err := uuid.Parse(key)
+		if err != nil {
+			return "", errors.WithStack(entity.ErrInvalidAllocationTargets)
+		}
+
+		// Check ownership
+		if _, err := u.pocketRepo.GetPocketByID(ctx, userID, pocketID); err != nil {
+			return "", errors.Wrap(err, "failed to get pocket")
+		}
+		return pocketID.String(), nil
+	}
+
+	if key == "" {
+		return "", errors.WithStack(entity.ErrInvalidAllocationTargets)
+	}
+
+	return key, nil
+}
+
+// allocationBuilder sums amounts per key of one dimension.
+type allocationBuilder struct {
+	amounts map[string]decimal.Decimal
+	labels  map[string]string
+}
+
+func (b *allocationBuilder) add(key, label string, amount decimal.Decimal) {
+	b.amounts[key] = b.amounts[key].Add(amount)
+	b.labels[key] = label
+}
+
+// GetAllocation splits the user's assets, valued in their base currency, by
+// account type, bank, currency and pocket, and compares each split with the
+// stored targets.
+func (u *usecase) GetAllocation(ctx context.Context, userID uuid.UUID) (*entity.AllocationReport, error) {
+	baseCurrency, err := u.currencyUsecase.GetBaseCurrency(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base currency")
+	}
+
+	accounts, err := u.accountRepo.GetUserAccounts(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get accounts")
+	}
+
+	targets, err := u.reportRepo.GetAllocationTargets(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get allocation targets")
+	}
+
+	builders := make(map[entity.AllocationDimension]*allocationBuilder, len(entity.AllocationDimensions))
+	for _, dimension := range entity.AllocationDimensions {
+		builders[dimension] = &allocationBuilder{
+			amounts: make(map[string]decimal.Decimal),
+			labels:  make(map[string]string),
+		}
+	}
+
+	report := &entity.AllocationReport{
+		BaseCurrency: baseCurrency,
+	}
+
+	now := time.Now()
+	unconverted := make(map[string]bool)
+	for _, account := range accounts {
+		if account.Type.IsLiability() {
+			continue
+		}
+
+		rate, err := u.currencyUsecase.GetRate(ctx, account.Currency, baseCurrency, now)
+		if errors.Is(err, entity.ErrExchangeRateNotFound) {
+			unconverted[account.Currency] = true
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get exchange rate")
+		}
+
+		pockets, err := u.pocketRepo.GetPocketsByAccountID(ctx, account.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pockets")
+		}
+
+		for _, pocket := range pockets {
+			amount := *currency.Convert(pocket.Balance, &rate.Rate)
+
+			builders[entity.AllocationDimensionType].add(account.Type.String(), account.Type.String(), amount)
+			builders[entity.AllocationDimensionBank].add(account.Bank, account.Bank, amount)
+			builders[entity.AllocationDimensionCurrency].add(account.Currency, account.Currency, amount)
+			builders[entity.AllocationDimensionPocket].add(pocket.ID.String(), account.Name+" / "+pocket.Name, amount)
+			report.Total = report.Total.Add(amount)
+		}
+	}
+
+	for c := range unconverted {
+		report.Unconverted = append(report.Unconverted, c)
+	}
+	sort.Strings(report.Unconverted)
+
+	targetsByDimension := make(map[entity.AllocationDimension][]entity.AllocationTarget)
+	for _, t := range targets {
+		targetsByDimension[t.Dimension] = append(targetsByDimension[t.Dimension], t)
+	}
+
+	for _, dimension := range entity.AllocationDimensions {
+		report.Allocations = append(report.Allocations, buildAllocation(dimension, builders[dimension], targetsByDimension[dimension], report.Total))
+	}
+
+	return report, nil
+}
+
+// buildAllocation turns the sums into groups, largest first. Targets of keys
+// the user holds nothing in become groups with a zero amount.
+func buildAllocation(dimension entity.AllocationDimension, b *allocationBuilder, targets []entity.AllocationTarget, total decimal.Decimal) entity.Allocation {
+	for _, t := range targets {
+		if _, ok := b.amounts[t.Key]; !ok {
+			b.amounts[t.Key] = decimal.Zero
+			b.labels[t.Key] = t.Key
+		}
+	}
+
+	weights := make(map[string]decimal.Decimal, len(targets))
+	for _, t := range targets {
+		weights[t.Key] = t.Weight
+	}
+
+	allocation := entity.Allocation{
+		Dimension: dimension,
+		Groups:    make([]entity.AllocationGroup, 0, len(b.amounts)),
+	}
+	for key, amount := range b.amounts {
+		group := entity.AllocationGroup{
+			Key:    key,
+			Label:  b.labels[key],
+			Amount: amount,
+		}
+		if total.IsPositive() {
+			group.Percent = amount.Mul(hundred).Div(total).Round(2)
+		}
+
+		if len(targets) > 0 {
+			weight := weights[key]
+			targetAmount := total.Mul(weight).Div(hundred).Round(2)
+			delta := targetAmount.Sub(amount)
+
+			group.TargetWeight = &weight
+			group.TargetAmount = &targetAmount
+			group.Delta = &delta
+		}
+
+		allocation.Groups = append(allocation.Groups, group)
+	}
+
+	sort.Slice(allocation.Groups, func(i, j int) bool {
+		gi, gj := allocation.Groups[i], allocation.Groups[j]
+		if !gi.Amount.Equal(gj.Amount) {
+			return gi.Amount.GreaterThan(gj.Amount)
+		}
+		return gi.Key < gj.Key
+	})
+
+	return allocation
+}
@@ -1,11 +1,18 @@
 package transaction
 
 import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/dto"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/middlewares/authentication"
 	"github.com/cockroachdb/errors"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/moonrhythm/validator"
 	"github.com/shopspring/decimal"
 )
 
@@ -22,7 +29,9 @@ func NewController(usecase *usecase, authMiddleware authentication.AuthMiddlewar
 }
 
 func (h *controller) Mount(r fiber.Router) {
+	r.Get("/", h.GetTransactions)
 	r.Get("/:id", h.GetTransactionByAccountID)
+	r.Post("/:id/reverse", h.Reverse)
 }
 
 type transactionResponse struct {
@@ -30,11 +39,203 @@ type transactionResponse struct {
 	AccountID    uuid.UUID       `json:"accountId"`
 	FromPocketID *uuid.UUID      `json:"fromPocketId"`
 	ToPocketID   *uuid.UUID      `json:"toPocketId"`
+	Type         entity.TxType   `json:"type"`
+	Currency     string          `json:"currency"`
 	Amount       decimal.Decimal `json:"amount"`
+	Note         string          `json:"note"`
+	Payee        string          `json:"payee"`
+	ReversalOfID *uuid.UUID      `json:"reversalOfId"`
 	CreatedAt    int64           `json:"createdAt"`
 	UpdatedAt    int64           `json:"updatedAt"`
 }
 
+const (
+	defaultTransactionLimit = 20
+	maxTransactionLimit     = 100
+)
+
+// encodeCursor turns the position of the last row of a page into an opaque token.
+func encodeCursor(cursor *entity.TransactionCursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + "_" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(token string) (*entity.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cursor")
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "_")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+
+	nanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cursor")
+	}
+
+	cursorID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cursor")
+	}
+
+	return &entity.TransactionCursor{
+		CreatedAt: time.Unix(0, nanos),
+		ID:        cursorID,
+	}, nil
+}
+
+type getTransactionsRequest struct {
+	AccountID string `query:"accountId"`
+	PocketID  string `query:"pocketId"`
+	Type      string `query:"type"`
+	MinAmount string `query:"minAmount"`
+	MaxAmount string `query:"maxAmount"`
+	From      int64  `query:"from"` // Unix seconds, inclusive
+	To        int64  `query:"to"`   // Unix seconds, exclusive
+	Note      string `query:"note"`
+	Cursor    string `query:"cursor"`
+	Limit     int    `query:"limit"`
+	Sort      string `query:"sort"` // asc | desc
+
+	filter entity.TransactionFilter
+}
+
+func (r *getTransactionsRequest) Parse(ctx *fiber.Ctx) error {
+	if err := ctx.QueryParser(r); err != nil {
+		return errors.Wrap(err, "failed to parse request")
+	}
+
+	if err := r.Validate(); err != nil {
+		return errors.Wrap(err, "invalid request")
+	}
+
+	return nil
+}
+
+func (r *getTransactionsRequest) Validate() error {
+	v := validator.New()
+
+	if r.AccountID != "" {
+		accountID, err := uuid.Parse(r.AccountID)
+		v.Must(err == nil, "accountId must be a valid id")
+		r.filter.AccountID = &accountID
+	}
+
+	if r.PocketID != "" {
+		pocketID, err := uuid.Parse(r.PocketID)
+		v.Must(err == nil, "pocketId must be a valid id")
+		r.filter.PocketID = &pocketID
+	}
+
+	if r.Type != "" {
+		txType := entity.TxType(r.Type)
+		r.filter.Type = &txType
+	}
+
+	if r.MinAmount != "" {
+		minAmount, err := decimal.NewFromString(r.MinAmount)
+		v.Must(err == nil, "minAmount must be a number")
+		r.filter.MinAmount = &minAmount
+	}
+
+	if r.MaxAmount != "" {
+		maxAmount, err := decimal.NewFromString(r.MaxAmount)
+		v.Must(err == nil, "maxAmount must be a number")
+		r.filter.MaxAmount = &maxAmount
+	}
+
+	if r.From != 0 {
+		from := time.Unix(r.From, 0)
+		r.filter.From = &from
+	}
+
+	if r.To != 0 {
+		to := time.Unix(r.To, 0)
+		r.filter.To = &to
+	}
+
+	if r.Cursor != "" {
+		cursor, err := decodeCursor(r.Cursor)
+		v.Must(err == nil, "cursor is invalid")
+		r.filter.Cursor = cursor
+	}
+
+	if r.Limit == 0 {
+		r.Limit = defaultTransactionLimit
+	}
+	v.Must(r.Limit > 0 && r.Limit <= maxTransactionLimit, "limit must be between 1 and 100")
+	v.Must(r.Sort == "" || r.Sort == "asc" || r.Sort == "desc", "sort must be asc or desc")
+
+	r.filter.Note = r.Note
+	r.filter.Limit = r.Limit
+	r.filter.Ascending = r.Sort == "asc"
+
+	return errors.WithStack(v.Error())
+}
+
+type transactionPageResponse struct {
+	Items      []transactionResponse `json:"items"`
+	NextCursor string                `json:"nextCursor,omitempty"`
+	Total      int64                 `json:"total"`
+}
+
+func (h *controller) GetTransactions(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	var req getTransactionsRequest
+	if err := req.Parse(ctx); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: err.Error(),
+		})
+	}
+
+	filter := req.filter
+	filter.UserID = userID
+
+	page, err := h.usecase.GetTransactionHistory(ctx.UserContext(), filter)
+	if err != nil {
+		return errors.Wrap(err, "failed to get transactions")
+	}
+
+	items := make([]transactionResponse, 0, len(page.Transactions))
+	for _, transaction := range page.Transactions {
+		items = append(items, transactionResponse{
+			ID:           transaction.ID,
+			AccountID:    transaction.AccountID,
+			FromPocketID: transaction.FromPocketID,
+			ToPocketID:   transaction.ToPocketID,
+			Type:         transaction.Type,
+			Currency:     transaction.Currency,
+			Amount:       transaction.Amount,
+			Note:         transaction.Note,
+			Payee:        transaction.Payee,
+			ReversalOfID: transaction.ReversalOfID,
+			CreatedAt:    transaction.CreatedAt.Unix(),
+			UpdatedAt:    transaction.UpdatedAt.Unix(),
+		})
+	}
+
+	return ctx.JSON(&dto.HttpResponse{
+		Result: transactionPageResponse{
+			Items:      items,
+			NextCursor: encodeCursor(page.NextCursor),
+			Total:      page.Total,
+		},
+	})
+}
+
 func (h *controller) GetTransactionByAccountID(ctx *fiber.Ctx) error {
 	// Get user ID from context
 	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
@@ -66,7 +267,12 @@ func (h *controller) GetTransactionByAccountID(ctx *fiber.Ctx) error {
 			AccountID:    transaction.AccountID,
 			FromPocketID: transaction.FromPocketID,
 			ToPocketID:   transaction.ToPocketID,
+			Type:         transaction.Type,
+			Currency:     transaction.Currency,
 			Amount:       transaction.Amount,
+			Note:         transaction.Note,
+			Payee:        transaction.Payee,
+			ReversalOfID: transaction.ReversalOfID,
 			CreatedAt:    transaction.CreatedAt.Unix(),
 			UpdatedAt:    transaction.UpdatedAt.Unix(),
 		})
@@ -76,3 +282,56 @@ func (h *controller) GetTransactionByAccountID(ctx *fiber.Ctx) error {
 		Result: res,
 	})
 }
+
+func (h *controller) Reverse(ctx *fiber.Ctx) error {
+	userID, err := h.authMiddleware.GetUserIDFromContext(ctx.UserContext())
+	if err != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(&dto.HttpResponse{
+			Error: "Unauthorized",
+		})
+	}
+
+	transactionID, err := uuid.Parse(ctx.Params("id"))
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Invalid transaction ID",
+		})
+	}
+
+	transaction, err := h.usecase.Reverse(ctx.UserContext(), userID, transactionID)
+	if errors.Is(err, entity.ErrAlreadyReversed) {
+		return ctx.Status(fiber.StatusConflict).JSON(&dto.HttpResponse{
+			Error: "Transaction already reversed",
+		})
+	}
+	if errors.Is(err, entity.ErrNotReversible) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Transaction can't be reversed",
+		})
+	}
+	if errors.Is(err, entity.ErrInsufficientBalance) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(&dto.HttpResponse{
+			Error: "Insufficient balance",
+		})
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to reverse transaction")
+	}
+
+	return ctx.JSON(&dto.HttpResponse{
+		Result: transactionResponse{
+			ID:           transaction.ID,
+			AccountID:    transaction.AccountID,
+			FromPocketID: transaction.FromPocketID,
+			ToPocketID:   transaction.ToPocketID,
+			Type:         transaction.Type,
+			Currency:     transaction.Currency,
+			Amount:       transaction.Amount,
+			Note:         transaction.Note,
+			Payee:        transaction.Payee,
+			ReversalOfID: transaction.ReversalOfID,
+			CreatedAt:    transaction.CreatedAt.Unix(),
+			UpdatedAt:    transaction.UpdatedAt.Unix(),
+		},
+	})
+}
@@ -2,13 +2,19 @@ package transaction
 
 import (
 	"context"
+	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
 	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/txmanager"
 	"github.com/cockroachdb/errors"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -16,58 +22,560 @@ type repository struct {
 }
 
 func NewRepository(db *gorm.DB) interfaces.TransactionRepository {
-	db.AutoMigrate(&model.Transaction{})
+	db.AutoMigrate(&model.Transaction{}, &model.Posting{})
 
 	return &repository{
 		db: db,
 	}
 }
 
-func (r *repository) GetTransactionByAccountID(ctx context.Context, userID uuid.UUID, pocketID uuid.UUID) ([]entity.Transaction, error) {
+func (r *repository) getDB(ctx context.Context) *gorm.DB {
+	return txmanager.GetDB(ctx, r.db)
+}
+
+func transactionFromModel(t *model.Transaction) *entity.Transaction {
+	return &entity.Transaction{
+		ID:           t.ID,
+		AccountID:    t.AccountID,
+		ToAccountID:  t.ToAccountID,
+		FromPocketID: t.FromPocketID,
+		ToPocketID:   t.ToPocketID,
+		Type:         entity.TxType(t.Type),
+		Currency:     t.Currency,
+		Amount:       t.Amount,
+		Note:         t.Note,
+		Payee:        t.Payee,
+		ReversalOfID: t.ReversalOfID,
+		FeeOfID:      t.FeeOfID,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func (r *repository) GetTransactionByAccountID(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) ([]entity.Transaction, error) {
 	var transactions []*model.Transaction
-	if err := r.db.Where("account_id IN (?)", r.db.Model(&model.Account{}).Select("id").Where("user_id = ?", userID)).Order("created_at desc").Find(&transactions).Error; err != nil {
+	if err := r.getDB(ctx).Where("account_id IN (?)", r.getDB(ctx).Model(&model.Account{}).Select("id").Where("user_id = ?", userID)).Where("account_id = ? OR to_account_id = ?", accountID, accountID).Order("created_at desc, id desc").Find(&transactions).Error; err != nil {
 		return nil, errors.Wrap(err, "failed to get transactions")
 	}
 
 	var result []entity.Transaction
 	for _, t := range transactions {
-		result = append(result, entity.Transaction{
-			ID:           t.ID,
-			AccountID:    t.AccountID,
-			FromPocketID: t.FromPocketID,
-			ToPocketID:   t.ToPocketID,
-			Type:         entity.TxType(t.Type),
-			Amount:       t.Amount,
-			CreatedAt:    t.CreatedAt,
-			UpdatedAt:    t.UpdatedAt,
+		result = append(result, *transactionFromModel(t))
+	}
+
+	return result, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *repository) applyTransactionFilter(ctx context.Context, db *gorm.DB, filter entity.TransactionFilter) *gorm.DB {
+	db = db.Where("account_id IN (?)", r.getDB(ctx).Model(&model.Account{}).Select("id").Where("user_id = ?", filter.UserID))
+
+	if filter.AccountID != nil {
+		db = db.Where("(account_id = ? OR to_account_id = ?)", *filter.AccountID, *filter.AccountID)
+	}
+
+	if filter.PocketID != nil {
+		db = db.Where("(from_pocket_id = ? OR to_pocket_id = ?)", *filter.PocketID, *filter.PocketID)
+	}
+
+	if filter.Type != nil {
+		db = db.Where("type = ?", *filter.Type)
+	}
+
+	if filter.MinAmount != nil {
+		db = db.Where("amount >= ?", *filter.MinAmount)
+	}
+
+	if filter.MaxAmount != nil {
+		db = db.Where("amount <= ?", *filter.MaxAmount)
+	}
+
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+
+	if filter.To != nil {
+		db = db.Where("created_at < ?", *filter.To)
+	}
+
+	if filter.Note != "" {
+		db = db.Where("note ILIKE ?", "%"+likeEscaper.Replace(filter.Note)+"%")
+	}
+
+	return db
+}
+
+func (r *repository) GetTransactions(ctx context.Context, filter entity.TransactionFilter) (*entity.TransactionPage, error) {
+	var total int64
+	if err := r.applyTransactionFilter(ctx, r.getDB(ctx).Model(&model.Transaction{}), filter).Count(&total).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to count transactions")
+	}
+
+	query := r.applyTransactionFilter(ctx, r.getDB(ctx), filter)
+
+	order, cmp := "created_at desc, id desc", "<"
+	if filter.Ascending {
+		order, cmp = "created_at asc, id asc", ">"
+	}
+
+	if filter.Cursor != nil {
+		query = query.Where("(created_at, id) "+cmp+" (?, ?)", filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	var transactions []*model.Transaction
+	if err := query.Order(order).Limit(filter.Limit + 1).Find(&transactions).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get transactions")
+	}
+
+	page := &entity.TransactionPage{
+		Transactions: make([]entity.Transaction, 0, len(transactions)),
+		Total:        total,
+	}
+
+	if len(transactions) > filter.Limit {
+		transactions = transactions[:filter.Limit]
+		last := transactions[len(transactions)-1]
+		page.NextCursor = &entity.TransactionCursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		}
+	}
+
+	for _, t := range transactions {
+		page.Transactions = append(page.Transactions, *transactionFromModel(t))
+	}
+
+	return page, nil
+}
+
+type pocketTransactionRow struct {
+	model.Transaction
+	SignedAmount           decimal.Decimal
+	CounterpartyPocketID   *uuid.UUID
+	CounterpartyPocketName string
+	RunningBalance         decimal.Decimal
+}
+
+func (r *repository) GetPocketTransactions(ctx context.Context, pocketID uuid.UUID) ([]entity.PocketTransaction, error) {
+	const query = `
+		SELECT *, SUM(signed_amount) OVER (ORDER BY created_at ASC, id ASC) AS running_balance
+		FROM (
+			SELECT t.*,
+				CASE WHEN t.to_pocket_id = @pocket THEN t.amount ELSE -t.amount END AS signed_amount,
+				CASE WHEN t.to_pocket_id = @pocket THEN t.from_pocket_id ELSE t.to_pocket_id END AS counterparty_pocket_id,
+				COALESCE(p.name, '') AS counterparty_pocket_name
+			FROM transactions t
+			LEFT JOIN pockets p ON p.id = CASE WHEN t.to_pocket_id = @pocket THEN t.from_pocket_id ELSE t.to_pocket_id END
+			WHERE t.from_pocket_id = @pocket OR t.to_pocket_id = @pocket
+		) pocket_transactions
+		ORDER BY created_at DESC, id DESC`
+
+	var rows []*pocketTransactionRow
+	if err := r.getDB(ctx).Raw(query, sql.Named("pocket", pocketID)).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket transactions")
+	}
+
+	result := make([]entity.PocketTransaction, 0, len(rows))
+	for _, t := range rows {
+		result = append(result, entity.PocketTransaction{
+			Transaction:            *transactionFromModel(&t.Transaction),
+			SignedAmount:           t.SignedAmount,
+			CounterpartyPocketID:   t.CounterpartyPocketID,
+			CounterpartyPocketName: t.CounterpartyPocketName,
+			RunningBalance:         t.RunningBalance,
 		})
 	}
 
 	return result, nil
 }
 
+func (r *repository) GetTransactionByID(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*entity.Transaction, error) {
+	var t model.Transaction
+	if err := r.getDB(ctx).Where("account_id IN (?)", r.getDB(ctx).Model(&model.Account{}).Select("id").Where("user_id = ?", userID)).First(&t, id).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction")
+	}
+
+	return transactionFromModel(&t), nil
+}
+
+func (r *repository) LockTransaction(ctx context.Context, id uuid.UUID) error {
+	var t model.Transaction
+	if err := r.getDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&t, id).Error; err != nil {
+		return errors.Wrap(err, "failed to lock transaction")
+	}
+
+	return nil
+}
+
+func (r *repository) GetFeeTransaction(ctx context.Context, transactionID uuid.UUID) (*entity.Transaction, error) {
+	var fees []*model.Transaction
+	if err := r.getDB(ctx).Where("fee_of_id = ?", transactionID).Limit(1).Find(&fees).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get fee transaction")
+	}
+
+	if len(fees) == 0 {
+		return nil, nil
+	}
+
+	return transactionFromModel(fees[0]), nil
+}
+
+func (r *repository) GetFirstTransactionAt(ctx context.Context, accountID uuid.UUID) (time.Time, error) {
+	var first sql.NullTime
+	if err := r.getDB(ctx).Model(&model.Transaction{}).Select("MIN(created_at)").Where("account_id = ? OR to_account_id = ?", accountID, accountID).Scan(&first).Error; err != nil {
+		return time.Time{}, errors.Wrap(err, "failed to get first transaction")
+	}
+
+	return first.Time, nil
+}
+
+func (r *repository) IsReversed(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.getDB(ctx).Model(&model.Transaction{}).Where("reversal_of_id = ?", id).Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, "failed to check reversal")
+	}
+
+	return count > 0, nil
+}
+
+// buildPostings derives the balanced journal entry of a transaction from its
+// pockets. The side without a pocket is booked to the account's external ledger.
+// Each leg must land in the account the transaction moves money out of or into,
+// otherwise the per-account sums would not match the account balances.
+func buildPostings(t model.Transaction, pocketAccounts map[uuid.UUID]uuid.UUID) ([]model.Posting, error) {
+	toAccountID := t.AccountID
+	if t.ToAccountID != nil {
+		toAccountID = *t.ToAccountID
+	}
+
+	leg := func(pocketID *uuid.UUID, accountID uuid.UUID, amount decimal.Decimal) (model.Posting, error) {
+		if pocketID != nil {
+			if id, ok := pocketAccounts[*pocketID]; ok {
+				if id != accountID {
+					return model.Posting{}, errors.Wrapf(entity.ErrUnbalancedEntry, "pocket %s is not in account %s", *pocketID, accountID)
+				}
+			}
+		}
+
+		return model.Posting{
+			ID:            uuid.New(),
+			TransactionID: t.ID,
+			AccountID:     accountID,
+			PocketID:      pocketID,
+			Amount:        amount,
+			CreatedAt:     t.CreatedAt,
+		}, nil
+	}
+
+	from, err := leg(t.FromPocketID, t.AccountID, t.Amount.Neg())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build postings")
+	}
+
+	to, err := leg(t.ToPocketID, toAccountID, t.Amount)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build postings")
+	}
+
+	return []model.Posting{from, to}, nil
+}
+
+func (r *repository) getPocketAccounts(ctx context.Context, pocketIDs ...*uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(pocketIDs))
+	for _, id := range pocketIDs {
+		if id != nil {
+			ids = append(ids, *id)
+		}
+	}
+
+	result := make(map[uuid.UUID]uuid.UUID, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	// Deleted pockets still own the postings of their past transactions
+	var pockets []*model.Pocket
+	if err := r.getDB(ctx).Unscoped().Select("id", "account_id").Where("id IN ?", ids).Find(&pockets).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get pockets")
+	}
+
+	for _, p := range pockets {
+		result[p.ID] = p.AccountID
+	}
+
+	return result, nil
+}
+
 func (r *repository) CreateTransaction(ctx context.Context, input entity.TransactionInput) (*entity.Transaction, error) {
+	if !input.Amount.IsPositive() {
+		return nil, errors.Wrap(entity.ErrInvalidAmount, "failed to create transaction")
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		if err := r.getDB(ctx).Model(&model.Account{}).Select("currency").Where("id = ?", input.AccountID).Scan(&currency).Error; err != nil {
+			return nil, errors.Wrap(err, "failed to get account currency")
+		}
+	}
+
 	t := model.Transaction{
 		ID:           uuid.New(),
 		AccountID:    input.AccountID,
+		ToAccountID:  input.ToAccountID,
 		FromPocketID: input.FromPocketID,
 		ToPocketID:   input.ToPocketID,
 		Type:         input.Type,
+		Currency:     currency,
 		Amount:       input.Amount,
+		Note:         input.Note,
+		Payee:        input.Payee,
+		ReversalOfID: input.ReversalOfID,
+		FeeOfID:      input.FeeOfID,
+		CreatedAt:    input.CreatedAt,
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
 	}
 
-	if err := r.db.Create(&t).Error; err != nil {
+	pocketAccounts, err := r.getPocketAccounts(ctx, t.FromPocketID, t.ToPocketID)
+	if err != nil {
 		return nil, errors.Wrap(err, "failed to create transaction")
 	}
 
-	return &entity.Transaction{
-		ID:           t.ID,
-		AccountID:    t.AccountID,
-		FromPocketID: t.FromPocketID,
-		ToPocketID:   t.ToPocketID,
-		Type:         entity.TxType(t.Type),
-		Amount:       t.Amount,
-		CreatedAt:    t.CreatedAt,
-		UpdatedAt:    t.UpdatedAt,
-	}, nil
+	postings, err := buildPostings(t, pocketAccounts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create transaction")
+	}
+
+	err = r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&t).Error; err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+
+		if err := tx.Create(&postings).Error; err != nil {
+			return errors.Wrap(err, "failed to create postings")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return transactionFromModel(&t), nil
+}
+
+func (r *repository) GetPostingsByTransactionID(ctx context.Context, transactionID uuid.UUID) ([]entity.Posting, error) {
+	var postings []*model.Posting
+	if err := r.getDB(ctx).Where("transaction_id = ?", transactionID).Order("amount asc").Find(&postings).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get postings")
+	}
+
+	var result []entity.Posting
+	for _, p := range postings {
+		result = append(result, entity.Posting{
+			ID:            p.ID,
+			TransactionID: p.TransactionID,
+			AccountID:     p.AccountID,
+			PocketID:      p.PocketID,
+			Amount:        p.Amount,
+			CreatedAt:     p.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *repository) GetPocketLedgerBalance(ctx context.Context, pocketID uuid.UUID) (decimal.Decimal, error) {
+	var balance decimal.NullDecimal
+	if err := r.getDB(ctx).Model(&model.Posting{}).Select("SUM(amount)").Where("pocket_id = ?", pocketID).Scan(&balance).Error; err != nil {
+		return decimal.Decimal{}, errors.Wrap(err, "failed to get pocket ledger balance")
+	}
+
+	return balance.Decimal, nil
+}
+
+func (r *repository) GetAccountLedgerBalance(ctx context.Context, accountID uuid.UUID) (decimal.Decimal, error) {
+	var balance decimal.NullDecimal
+	if err := r.getDB(ctx).Model(&model.Posting{}).Select("SUM(amount)").Where("account_id = ? AND pocket_id IS NOT NULL", accountID).Scan(&balance).Error; err != nil {
+		return decimal.Decimal{}, errors.Wrap(err, "failed to get account ledger balance")
+	}
+
+	return balance.Decimal, nil
+}
+
+type orphanedPostingsRow struct {
+	Count  int64
+	Amount decimal.NullDecimal
+}
+
+func (r *repository) GetOrphanedPostings(ctx context.Context, accountID uuid.UUID) (int64, decimal.Decimal, error) {
+	// Soft-deleted pockets still exist, only hard-deleted ones leave orphans
+	var row orphanedPostingsRow
+	if err := r.getDB(ctx).Model(&model.Posting{}).Select("COUNT(*) AS count, SUM(amount) AS amount").
+		Where("account_id = ? AND pocket_id IS NOT NULL", accountID).
+		Where("NOT EXISTS (?)", r.getDB(ctx).Unscoped().Model(&model.Pocket{}).Select("1").Where("pockets.id = postings.pocket_id")).
+		Scan(&row).Error; err != nil {
+		return 0, decimal.Decimal{}, errors.Wrap(err, "failed to get orphaned postings")
+	}
+
+	return row.Count, row.Amount.Decimal, nil
+}
+
+func (r *repository) GetAccountLedgerBalanceAt(ctx context.Context, accountID uuid.UUID, at time.Time) (decimal.Decimal, error) {
+	var balance decimal.NullDecimal
+	if err := r.getDB(ctx).Model(&model.Posting{}).Select("SUM(amount)").Where("account_id = ? AND pocket_id IS NOT NULL AND created_at < ?", accountID, at).Scan(&balance).Error; err != nil {
+		return decimal.Decimal{}, errors.Wrap(err, "failed to get account ledger balance")
+	}
+
+	return balance.Decimal, nil
+}
+
+func (r *repository) GetAccountPostings(ctx context.Context, accountID uuid.UUID, from, to time.Time) ([]entity.Posting, error) {
+	var postings []*model.Posting
+	if err := r.getDB(ctx).Where("account_id = ? AND pocket_id IS NOT NULL AND created_at >= ? AND created_at < ?", accountID, from, to).Order("created_at asc").Find(&postings).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get postings")
+	}
+
+	var result []entity.Posting
+	for _, p := range postings {
+		result = append(result, entity.Posting{
+			ID:            p.ID,
+			TransactionID: p.TransactionID,
+			AccountID:     p.AccountID,
+			PocketID:      p.PocketID,
+			Amount:        p.Amount,
+			CreatedAt:     p.CreatedAt,
+		})
+	}
+
+	return result, nil
+}
+
+type pocketSpendingRow struct {
+	PocketID uuid.UUID
+	Month    time.Time
+	Amount   decimal.Decimal
+}
+
+// GetPocketSpending counts a reversed withdrawal back in the month it was
+// reversed in, so the spending of earlier months never changes.
+func (r *repository) GetPocketSpending(ctx context.Context, pocketIDs []uuid.UUID, from, to time.Time) ([]entity.PocketSpending, error) {
+	if len(pocketIDs) == 0 {
+		return nil, nil
+	}
+
+	const query = `
+		SELECT pocket_id, month, SUM(amount) AS amount
+		FROM (
+			SELECT t.from_pocket_id AS pocket_id, date_trunc('month', t.created_at AT TIME ZONE 'UTC') AS month, t.amount
+			FROM transactions t
+			WHERE t.type = @withdraw AND t.from_pocket_id IN @pockets AND t.created_at >= @from AND t.created_at < @to
+			UNION ALL
+			SELECT r.to_pocket_id, date_trunc('month', r.created_at AT TIME ZONE 'UTC'), -r.amount
+			FROM transactions r
+			JOIN transactions o ON o.id = r.reversal_of_id
+			WHERE r.type = @reversal AND o.type = @withdraw AND r.to_pocket_id IN @pockets AND r.created_at >= @from AND r.created_at < @to
+		) spending
+		GROUP BY pocket_id, month
+		ORDER BY month ASC`
+
+	var rows []*pocketSpendingRow
+	if err := r.getDB(ctx).Raw(query,
+		sql.Named("withdraw", entity.TxTypeWithdraw),
+		sql.Named("reversal", entity.TxTypeReversal),
+		sql.Named("pockets", pocketIDs),
+		sql.Named("from", from),
+		sql.Named("to", to),
+	).Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, "failed to get pocket spending")
+	}
+
+	result := make([]entity.PocketSpending, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, entity.PocketSpending{
+			PocketID: row.PocketID,
+			Month:    entity.MonthStart(row.Month),
+			Amount:   row.Amount,
+		})
+	}
+
+	return result, nil
+}
+
+// BackfillPostings writes the postings of transactions that have none. Run it
+// inside a transaction so a failure leaves nothing half written.
+func (r *repository) BackfillPostings(ctx context.Context) (int, error) {
+	var transactions []model.Transaction
+	if err := r.getDB(ctx).Where("NOT EXISTS (?)", r.getDB(ctx).Model(&model.Posting{}).Select("1").Where("postings.transaction_id = transactions.id")).Order("created_at asc").Find(&transactions).Error; err != nil {
+		return 0, errors.Wrap(err, "failed to get transactions without postings")
+	}
+
+	for _, t := range transactions {
+		pocketAccounts, err := r.getPocketAccounts(ctx, t.FromPocketID, t.ToPocketID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to backfill postings")
+		}
+
+		// Cross-account transfers recorded before ToAccountID existed only know
+		// the receiving account through their pocket
+		if t.ToAccountID == nil && t.ToPocketID != nil {
+			if id, ok := pocketAccounts[*t.ToPocketID]; ok && id != t.AccountID {
+				t.ToAccountID = &id
+				if err := r.getDB(ctx).Model(&t).UpdateColumn("to_account_id", id).Error; err != nil {
+					return 0, errors.Wrap(err, "failed to set receiving account")
+				}
+			}
+		}
+
+		postings, err := buildPostings(t, pocketAccounts)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to build postings for transaction %s", t.ID)
+		}
+
+		if err := r.getDB(ctx).Create(&postings).Error; err != nil {
+			return 0, errors.Wrap(err, "failed to create postings")
+		}
+	}
+
+	return len(transactions), nil
+}
+
+// BackfillTypes infers the type of DEPOSIT, WITHDRAW and TRANSFER rows from which
+// of FromPocketID and ToPocketID is set, fixing rows recorded with the wrong type.
+func (r *repository) BackfillTypes(ctx context.Context) (int64, error) {
+	var updated int64
+	err := r.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		inferred := []struct {
+			txType    entity.TxType
+			condition string
+		}{
+			{entity.TxTypeDeposit, "from_pocket_id IS NULL AND to_pocket_id IS NOT NULL"},
+			{entity.TxTypeWithdraw, "from_pocket_id IS NOT NULL AND to_pocket_id IS NULL"},
+			{entity.TxTypeTransfer, "from_pocket_id IS NOT NULL AND to_pocket_id IS NOT NULL"},
+		}
+
+		legacyTypes := []entity.TxType{entity.TxTypeDeposit, entity.TxTypeWithdraw, entity.TxTypeTransfer}
+		for _, i := range inferred {
+			result := tx.Model(&model.Transaction{}).
+				Where("type IN ?", legacyTypes).
+				Where("type <> ?", i.txType).
+				Where(i.condition).
+				Update("type", i.txType)
+			if result.Error != nil {
+				return errors.Wrap(result.Error, "failed to update transaction type")
+			}
+
+			updated += result.RowsAffected
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return updated, nil
 }
@@ -0,0 +1,96 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/entity"
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/model"
+	"github.com/cockroachdb/errors"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildPostings(t *testing.T) {
+	var (
+		account      = uuid.New()
+		otherAccount = uuid.New()
+		pocket       = uuid.New()
+		otherPocket  = uuid.New()
+		deleted      = uuid.New()
+		amount       = decimal.NewFromInt(100)
+	)
+	pocketAccounts := map[uuid.UUID]uuid.UUID{
+		pocket:      account,
+		otherPocket: otherAccount,
+	}
+
+	tests := []struct {
+		name         string
+		transaction  model.Transaction
+		wantAccounts [2]uuid.UUID
+		wantErr      error
+	}{
+		{
+			name:         "deposit books the external side to the account",
+			transaction:  model.Transaction{AccountID: account, ToPocketID: &pocket},
+			wantAccounts: [2]uuid.UUID{account, account},
+		},
+		{
+			name:         "cross-account transfer",
+			transaction:  model.Transaction{AccountID: account, ToAccountID: &otherAccount, FromPocketID: &pocket, ToPocketID: &otherPocket},
+			wantAccounts: [2]uuid.UUID{account, otherAccount},
+		},
+		{
+			name:         "unknown pocket falls back to the transaction account",
+			transaction:  model.Transaction{AccountID: account, FromPocketID: &deleted},
+			wantAccounts: [2]uuid.UUID{account, account},
+		},
+		{
+			name:        "to pocket outside the receiving account",
+			transaction: model.Transaction{AccountID: account, FromPocketID: &pocket, ToPocketID: &otherPocket},
+			wantErr:     entity.ErrUnbalancedEntry,
+		},
+		{
+			name:        "from pocket outside the sending account",
+			transaction: model.Transaction{AccountID: otherAccount, ToAccountID: &account, FromPocketID: &pocket, ToPocketID: &otherPocket},
+			wantErr:     entity.ErrUnbalancedEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.transaction.ID = uuid.New()
+			tt.transaction.Amount = amount
+
+			postings, err := buildPostings(tt.transaction, pocketAccounts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(postings) != 2 {
+				t.Fatalf("got %d postings, want 2", len(postings))
+			}
+
+			sum := decimal.Zero
+			for i, p := range postings {
+				if p.AccountID != tt.wantAccounts[i] {
+					t.Errorf("posting %d account = %s, want %s", i, p.AccountID, tt.wantAccounts[i])
+				}
+				if p.TransactionID != tt.transaction.ID {
+					t.Errorf("posting %d transaction = %s, want %s", i, p.TransactionID, tt.transaction.ID)
+				}
+				sum = sum.Add(p.Amount)
+			}
+
+			if !postings[0].Amount.Equal(amount.Neg()) || !sum.IsZero() {
+				t.Errorf("postings %s, %s do not balance %s", postings[0].Amount, postings[1].Amount, amount)
+			}
+		})
+	}
+}
@@ -10,14 +10,18 @@ import (
 )
 
 type usecase struct {
+	txManager       interfaces.TxManager
 	transactionRepo interfaces.TransactionRepository
 	accountRepo     interfaces.AccountRepository
+	pocketRepo      interfaces.PocketRepository
 }
 
-func NewUsecase(transactionRepo interfaces.TransactionRepository, accountRepo interfaces.AccountRepository) *usecase {
+func NewUsecase(txManager interfaces.TxManager, transactionRepo interfaces.TransactionRepository, accountRepo interfaces.AccountRepository, pocketRepo interfaces.PocketRepository) *usecase {
 	return &usecase{
+		txManager:       txManager,
 		transactionRepo: transactionRepo,
 		accountRepo:     accountRepo,
+		pocketRepo:      pocketRepo,
 	}
 }
 
@@ -34,3 +38,152 @@ func (u *usecase) GetTransactions(ctx context.Context, userID uuid.UUID, account
 
 	return transactions, nil
 }
+
+func (u *usecase) GetTransactionHistory(ctx context.Context, filter entity.TransactionFilter) (*entity.TransactionPage, error) {
+	// Check account ownership
+	if filter.AccountID != nil {
+		if _, err := u.accountRepo.GetUserAccount(ctx, filter.UserID, *filter.AccountID); err != nil {
+			return nil, errors.Wrap(err, "failed to get account")
+		}
+	}
+
+	// Check pocket ownership
+	if filter.PocketID != nil {
+		if _, err := u.pocketRepo.GetPocketByID(ctx, filter.UserID, *filter.PocketID); err != nil {
+			return nil, errors.Wrap(err, "failed to get pocket")
+		}
+	}
+
+	page, err := u.transactionRepo.GetTransactions(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transactions")
+	}
+
+	return page, nil
+}
+
+// Reverse records a REVERSAL entry for the transaction and moves the money back.
+// Reversing a cross-account transfer refunds its fee too. A transaction can be
+// reversed only once, and never into a negative balance.
+func (u *usecase) Reverse(ctx context.Context, userID uuid.UUID, transactionID uuid.UUID) (*entity.Transaction, error) {
+	// Check ownership
+	original, err := u.transactionRepo.GetTransactionByID(ctx, userID, transactionID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction")
+	}
+
+	if !original.IsReversible() {
+		return nil, errors.Wrap(entity.ErrNotReversible, "failed to reverse transaction")
+	}
+
+	var reversal *entity.Transaction
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// Concurrent reversals of the same transaction wait here, then see the first one
+		if err := u.transactionRepo.LockTransaction(ctx, original.ID); err != nil {
+			return errors.Wrap(err, "failed to lock transaction")
+		}
+
+		reversed, err := u.transactionRepo.IsReversed(ctx, original.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to check reversal")
+		}
+
+		if reversed {
+			return errors.Wrap(entity.ErrAlreadyReversed, "failed to reverse transaction")
+		}
+
+		var fee *entity.Transaction
+		if original.Type == entity.TxTypeTransfer {
+			fee, err = u.transactionRepo.GetFeeTransaction(ctx, original.ID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get fee transaction")
+			}
+		}
+
+		switch original.Type {
+		case entity.TxTypeDeposit:
+			if err := u.accountRepo.Withdraw(ctx, original.AccountID, original.Amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from account")
+			}
+
+			if err := u.pocketRepo.Withdraw(ctx, *original.ToPocketID, original.Amount); err != nil {
+				return errors.Wrap(err, "failed to withdraw from pocket")
+			}
+		case entity.TxTypeWithdraw:
+			if err := u.accountRepo.Deposit(ctx, original.AccountID, original.Amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to account")
+			}
+
+			if err := u.pocketRepo.Deposit(ctx, *original.FromPocketID, original.Amount); err != nil {
+				return errors.Wrap(err, "failed to deposit to pocket")
+			}
+		case entity.TxTypeTransfer:
+			// Accounts are locked before pockets
+			if original.ToAccountID != nil {
+				if err := u.accountRepo.Transfer(ctx, *original.ToAccountID, original.AccountID, original.Amount); err != nil {
+					return errors.Wrap(err, "failed to transfer between accounts")
+				}
+			}
+
+			if fee != nil {
+				if err := u.accountRepo.Deposit(ctx, fee.AccountID, fee.Amount); err != nil {
+					return errors.Wrap(err, "failed to refund fee to account")
+				}
+
+				// The fee pocket may be neither side of the transfer
+				if _, err := u.pocketRepo.LockPocketsByAccountID(ctx, fee.AccountID); err != nil {
+					return errors.Wrap(err, "failed to lock pockets")
+				}
+			}
+
+			if err := u.pocketRepo.Transfer(ctx, *original.ToPocketID, *original.FromPocketID, original.Amount); err != nil {
+				return errors.Wrap(err, "failed to transfer")
+			}
+		}
+
+		input := entity.TransactionInput{
+			AccountID:    original.AccountID,
+			FromPocketID: original.ToPocketID,
+			ToPocketID:   original.FromPocketID,
+			Type:         entity.TxTypeReversal,
+			Amount:       original.Amount,
+			ReversalOfID: &original.ID,
+		}
+
+		// A cross-account reversal flows from the receiving account back to the sender
+		if original.ToAccountID != nil {
+			input.AccountID = *original.ToAccountID
+			input.ToAccountID = &original.AccountID
+		}
+
+		reversal, err = u.transactionRepo.CreateTransaction(ctx, input)
+		if err != nil {
+			return errors.Wrap(err, "failed to create transaction")
+		}
+
+		if fee == nil {
+			return nil
+		}
+
+		if _, err := u.transactionRepo.CreateTransaction(ctx, entity.TransactionInput{
+			AccountID:    fee.AccountID,
+			ToPocketID:   fee.FromPocketID,
+			Type:         entity.TxTypeReversal,
+			Amount:       fee.Amount,
+			ReversalOfID: &fee.ID,
+		}); err != nil {
+			return errors.Wrap(err, "failed to create fee reversal")
+		}
+
+		if err := u.pocketRepo.Deposit(ctx, *fee.FromPocketID, fee.Amount); err != nil {
+			return errors.Wrap(err, "failed to refund fee to pocket")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return reversal, nil
+}
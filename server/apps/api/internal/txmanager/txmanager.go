@@ -0,0 +1,46 @@
+package txmanager
+
+import (
+	"context"
+
+	"github.com/boomchanotai/assets-tracker/server/apps/api/internal/interfaces"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+)
+
+type txContext struct{}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) interfaces.TxManager {
+	return &txManager{
+		db: db,
+	}
+}
+
+func (m *txManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Join the outer transaction
+	if _, ok := ctx.Value(txContext{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContext{}, tx))
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// GetDB returns the transaction bound to ctx by WithTx, or db when there is none.
+func GetDB(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContext{}).(*gorm.DB); ok {
+		return tx
+	}
+
+	return db.WithContext(ctx)
+}
@@ -44,11 +44,12 @@ func (r *repository) GetUsers(ctx context.Context) ([]entity.User, error) {
 	var result []entity.User
 	for _, u := range users {
 		result = append(result, entity.User{
-			ID:        u.ID,
-			Email:     u.Email,
-			Name:      u.Name,
-			CreatedAt: u.CreatedAt,
-			UpdatedAt: u.UpdatedAt,
+			ID:           u.ID,
+			Email:        u.Email,
+			Name:         u.Name,
+			BaseCurrency: u.BaseCurrency,
+			CreatedAt:    u.CreatedAt,
+			UpdatedAt:    u.UpdatedAt,
 		})
 	}
 
@@ -63,12 +64,13 @@ func (r *repository) GetUser(ctx context.Context, id uuid.UUID) (*entity.User, e
 	}
 
 	return &entity.User{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:           u.ID,
+		Email:        u.Email,
+		Name:         u.Name,
+		BaseCurrency: u.BaseCurrency,
+		Password:     u.Password,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
 	}, nil
 }
 
@@ -79,21 +81,23 @@ func (r *repository) GetUserByEmail(ctx context.Context, email string) (*entity.
 	}
 
 	return &entity.User{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		Password:  u.Password,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:           u.ID,
+		Email:        u.Email,
+		Name:         u.Name,
+		BaseCurrency: u.BaseCurrency,
+		Password:     u.Password,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
 	}, nil
 }
 
 func (r *repository) CreateUser(ctx context.Context, input entity.UserInput) (*entity.User, error) {
 	newUser := model.User{
-		ID:       uuid.New(),
-		Email:    input.Email,
-		Name:     input.Name,
-		Password: input.Password,
+		ID:           uuid.New(),
+		Email:        input.Email,
+		Name:         input.Name,
+		Password:     input.Password,
+		BaseCurrency: entity.DefaultCurrency,
 	}
 
 	if err := r.db.Create(&newUser).Error; err != nil {
@@ -101,14 +105,41 @@ func (r *repository) CreateUser(ctx context.Context, input entity.UserInput) (*e
 	}
 
 	return &entity.User{
-		ID:    newUser.ID,
-		Email: newUser.Email,
-		Name:  newUser.Name,
+		ID:           newUser.ID,
+		Email:        newUser.Email,
+		Name:         newUser.Name,
+		BaseCurrency: newUser.BaseCurrency,
 	}, nil
 }
 
 func (r *repository) UpdateUser(ctx context.Context, id uuid.UUID, input entity.UserInput) (*entity.User, error) {
-	panic("not implemented")
+	var u model.User
+	if err := r.db.Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, errors.Wrap(err, "can't get user")
+	}
+
+	if input.Name != "" {
+		u.Name = input.Name
+	}
+
+	if input.BaseCurrency != "" {
+		u.BaseCurrency = input.BaseCurrency
+	}
+
+	u.UpdatedAt = time.Now()
+
+	if err := r.db.Save(&u).Error; err != nil {
+		return nil, errors.Wrap(err, "can't update user")
+	}
+
+	return &entity.User{
+		ID:           u.ID,
+		Email:        u.Email,
+		Name:         u.Name,
+		BaseCurrency: u.BaseCurrency,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
+	}, nil
 }
 
 func getTokenKey(userID uuid.UUID) string {
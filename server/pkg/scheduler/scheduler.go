@@ -0,0 +1,31 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/boomchanotai/assets-tracker/server/pkg/logger"
+)
+
+// Every runs fn once per interval until ctx is done. A non-positive interval disables the job.
+func Every(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		logger.InfoContext(ctx, "scheduled job disabled", slog.String("job", name))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				logger.ErrorContext(ctx, "scheduled job failed", slog.String("job", name), slog.Any("error", err))
+			}
+		}
+	}
+}